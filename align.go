@@ -0,0 +1,32 @@
+package objsearch
+
+import "image"
+
+// The translation best aligning two images, as found by Align
+type Alignment struct {
+	// The pixel of b at p lies over the pixel of a at p.Add(Offset)
+	Offset image.Point
+	// The distance between the aligned images, as the mean absolute
+	// per-pixel difference combined according to Options.CombineMode
+	Score float64
+}
+
+// Finds the translation, at most maxShift pixels either way, best aligning
+// b with a, such as between two frames of a panning camera or two scans of a
+// page. The part of b at least maxShift pixels from its edges is searched for
+// in a, so that it overlaps a at every translation tried. opts.Tolerance,
+// MinDist, ScoreMode and HitMode are ignored.
+//
+// Returns false if b has no pixels at least maxShift from its edges, or no
+// translation overlaps a.
+func Align(a, b image.Image, maxShift int, opts Options) (Alignment, bool) {
+	core := b.Bounds().Inset(maxShift)
+	if core.Empty() {
+		return Alignment{}, false
+	}
+	object := toRGBA(b).SubImage(core)
+	// hits are at the translation of core
+	shifts := image.Rect(-maxShift, -maxShift, maxShift+1, maxShift+1)
+	p, d, ok := bestMatch(a, object, shifts.Intersect(validRect(a.Bounds(), core)), opts)
+	return Alignment{p, d}, ok
+}
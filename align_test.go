@@ -0,0 +1,30 @@
+package objsearch
+
+import (
+	"image"
+	"testing"
+)
+
+func TestAlign(t *testing.T) {
+	a := randomRGBImage(80, 60)
+	b := randomRGBImage(80, 60)
+	shift := image.Point{5, -3}
+	for y := 0; y < 60; y++ {
+		for x := 0; x < 80; x++ {
+			if p := (image.Point{x, y}).Add(shift); p.In(a.Rect) {
+				b.SetRGBA(x, y, a.RGBAAt(p.X, p.Y))
+			}
+		}
+	}
+	al, ok := Align(a, b, 8, Options{ColorMode: COLORMODE_RGB})
+	if !ok || al != (Alignment{shift, 0}) {
+		t.Fatal("alignment error", al, ok)
+	}
+	// and back
+	if al, ok = Align(b, a, 8, Options{}); !ok || al.Offset != shift.Mul(-1) || al.Score != 0 {
+		t.Fatal("reverse alignment error", al, ok)
+	}
+	if _, ok := Align(a, b, 30, Options{}); ok {
+		t.Fatal("aligned with no core")
+	}
+}
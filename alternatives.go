@@ -0,0 +1,50 @@
+package objsearch
+
+import (
+	"image"
+)
+
+// A Hit on one of several alternative objects
+type AlternativeHit struct {
+	Hit
+	// Index of the alternative that matched best at the hit
+	Index int
+}
+
+// Searches field for any of objects, which are alternative appearances of
+// the same object, such as the frames of an animated icon. At each position,
+// the best-matching alternative is used, so that a hit is reported wherever
+// any alternative matches. Hits are found among the best scores as
+// SearchImage finds them. All objects must have the same bounds.
+func SearchAny(field image.Image, objects []image.Image, rect image.Rectangle, opts Options) (hits []AlternativeHit) {
+	if len(objects) == 0 {
+		panic("no objects")
+	}
+	f := NewField(field)
+	var ctx objSearchContext
+	var best []float64
+	var index []int
+	for i, o := range objects {
+		if o.Bounds() != objects[0].Bounds() {
+			panic("objects have different bounds")
+		}
+		c, scores := searchScores(f, o, rect, opts)
+		if i == 0 {
+			ctx, best, index = c, scores, make([]int, len(scores))
+			continue
+		}
+		for j := range scores {
+			if ctx.better(scores[j], best[j]) {
+				best[j], index[j] = scores[j], i
+			}
+		}
+	}
+	if ctx.SearchRect.Empty() || ctx.hitScores(best) == nil {
+		// nothing to search, or the scores cannot be normalized
+		return nil
+	}
+	for _, h := range ctx.hits(best) {
+		hits = append(hits, AlternativeHit{h, index[ctx.offset(h.P.X, h.P.Y)]})
+	}
+	return
+}
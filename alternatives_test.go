@@ -0,0 +1,34 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestSearchAny(t *testing.T) {
+	bg := randomRGBImage(60, 60)
+	frames := []image.Image{randomRGBImage(8, 8), randomRGBImage(8, 8), randomRGBImage(8, 8)}
+	field := frameWithObject(bg, frames[2].(*image.RGBA), image.Point{10, 40})
+	field = frameWithObject(field, frames[0].(*image.RGBA), image.Point{45, 5})
+	for _, opts := range []Options{
+		{Tolerance: 0.1, MinDist: 8},
+		{Tolerance: 0.9, MinDist: 8, ScoreMode: SCOREMODE_CCOEFF_NORMED},
+		{MinDist: 8, HitMode: HITMODE_BESTK, K: 2},
+	} {
+		// the rect is clamped to the positions at which the objects fit
+		hits := SearchAny(field, frames, field.Rect, opts)
+		if len(hits) != 2 {
+			t.Fatal("SearchAny error", opts.ScoreMode, hits)
+		}
+		best := 0.0
+		if opts.ScoreMode == SCOREMODE_CCOEFF_NORMED {
+			best = 1
+		}
+		for _, h := range hits {
+			if math.Abs(h.S-best) > 1e-9 || !(h.Index == 2 && h.P == image.Point{10, 40}) && !(h.Index == 0 && h.P == image.Point{45, 5}) {
+				t.Fatal("SearchAny error", opts.ScoreMode, hits)
+			}
+		}
+	}
+}
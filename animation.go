@@ -0,0 +1,291 @@
+package objsearch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"time"
+)
+
+// The fully composited frames of an animated GIF or PNG
+type Animation struct {
+	Frames []*image.RGBA
+	// Display duration of each frame
+	Delays []time.Duration
+}
+
+// A Hit in one frame of an animation or video
+type FrameHit struct {
+	Hit
+	// Index of the frame, counting from 0
+	Frame int
+	// Time from the start of the animation at which the frame is shown
+	Time time.Duration
+}
+
+// Decodes an animated GIF or PNG (APNG) from r. A non-animated PNG decodes
+// as a single frame.
+func DecodeAnimation(r io.Reader) (*Animation, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(pngSignature))
+	if err != nil {
+		return nil, err
+	}
+	if string(magic) == pngSignature {
+		return decodeAPNG(br)
+	}
+	g, err := gif.DecodeAll(br)
+	if err != nil {
+		return nil, err
+	}
+	return gifAnimation(g), nil
+}
+
+// Returns the time from the start of a at which frame i is shown
+func (a *Animation) FrameTime(i int) (t time.Duration) {
+	for j := 0; j < i; j++ {
+		t += a.Delays[j]
+	}
+	return
+}
+
+// Searches frames first through last, inclusive, of a for object. A
+// negative first searches from the first frame, and a negative last searches
+// through the final frame. Returns nil if first is after last. Each frame is
+// searched as SearchImage does, over every position at which object lies
+// within the frame.
+func (a *Animation) Search(object image.Image, first, last int, opts Options) (hits []FrameHit) {
+	if first < 0 {
+		first = 0
+	}
+	if last < 0 || last >= len(a.Frames) {
+		last = len(a.Frames) - 1
+	}
+	if first > last {
+		return nil
+	}
+	s := NewSearcher(object, opts)
+	t := a.FrameTime(first)
+	for i := first; i <= last; i++ {
+		for _, h := range s.Search(a.Frames[i]) {
+			hits = append(hits, FrameHit{h, i, t})
+		}
+		t += a.Delays[i]
+	}
+	return
+}
+
+// return the composited frames of g
+func gifAnimation(g *gif.GIF) *Animation {
+	a := &Animation{}
+	canvas := image.NewRGBA(image.Rect(0, 0, g.Config.Width, g.Config.Height))
+	for i, frame := range g.Image {
+		var prev *image.RGBA
+		disposal := byte(0)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		if disposal == gif.DisposalPrevious {
+			prev = cloneRGBA(canvas)
+		}
+		draw.Draw(canvas, frame.Rect, frame, frame.Rect.Min, draw.Over)
+		a.Frames = append(a.Frames, cloneRGBA(canvas))
+		a.Delays = append(a.Delays, time.Duration(g.Delay[i])*10*time.Millisecond)
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Rect, image.Transparent, image.ZP, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = prev
+		}
+	}
+	return a
+}
+
+// return a copy of img
+func cloneRGBA(img *image.RGBA) *image.RGBA {
+	r := image.NewRGBA(img.Rect)
+	copy(r.Pix, img.Pix)
+	return r
+}
+
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+// a PNG chunk
+type pngChunk struct {
+	typ  string
+	data []byte
+}
+
+// APNG frame control, from an fcTL chunk
+type apngFrame struct {
+	w, h, x, y         int
+	delayNum, delayDen uint16
+	dispose, blend     byte
+	// compressed image data of the frame
+	data []byte
+}
+
+// decode an animated PNG from r, which must start with the PNG signature
+func decodeAPNG(r io.Reader) (*Animation, error) {
+	if _, err := io.ReadFull(r, make([]byte, len(pngSignature))); err != nil {
+		return nil, err
+	}
+	var ihdr []byte
+	var header []pngChunk // chunks other than IHDR before the first IDAT
+	var frames []*apngFrame
+	var cur *apngFrame
+	animated, seenIDAT, defaultIsFrame := false, false, false
+	var defaultData []byte
+	for {
+		c, err := readPNGChunk(r)
+		if err != nil {
+			return nil, err
+		}
+		if c.typ == "IEND" {
+			break
+		}
+		switch c.typ {
+		case "IHDR":
+			ihdr = c.data
+		case "acTL":
+			animated = true
+		case "fcTL":
+			if len(c.data) != 26 {
+				return nil, errors.New("apng: invalid fcTL chunk")
+			}
+			cur = &apngFrame{
+				w:        int(binary.BigEndian.Uint32(c.data[4:])),
+				h:        int(binary.BigEndian.Uint32(c.data[8:])),
+				x:        int(binary.BigEndian.Uint32(c.data[12:])),
+				y:        int(binary.BigEndian.Uint32(c.data[16:])),
+				delayNum: binary.BigEndian.Uint16(c.data[20:]),
+				delayDen: binary.BigEndian.Uint16(c.data[22:]),
+				dispose:  c.data[24],
+				blend:    c.data[25],
+			}
+			frames = append(frames, cur)
+			if !seenIDAT {
+				defaultIsFrame = true
+			}
+		case "IDAT":
+			seenIDAT = true
+			defaultData = append(defaultData, c.data...)
+			if defaultIsFrame && cur != nil {
+				cur.data = append(cur.data, c.data...)
+			}
+		case "fdAT":
+			if cur == nil || len(c.data) < 4 {
+				return nil, errors.New("apng: invalid fdAT chunk")
+			}
+			cur.data = append(cur.data, c.data[4:]...)
+		default:
+			if !seenIDAT {
+				header = append(header, c)
+			}
+		}
+	}
+	if ihdr == nil || len(ihdr) != 13 {
+		return nil, errors.New("apng: missing IHDR chunk")
+	}
+	width := int(binary.BigEndian.Uint32(ihdr[0:]))
+	height := int(binary.BigEndian.Uint32(ihdr[4:]))
+	if !animated || len(frames) == 0 {
+		img, err := decodePNGFrame(ihdr, header, width, height, defaultData)
+		if err != nil {
+			return nil, err
+		}
+		return &Animation{[]*image.RGBA{toRGBA(img)}, []time.Duration{0}}, nil
+	}
+	a := &Animation{}
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+	for _, f := range frames {
+		img, err := decodePNGFrame(ihdr, header, f.w, f.h, f.data)
+		if err != nil {
+			return nil, err
+		}
+		r := image.Rect(f.x, f.y, f.x+f.w, f.y+f.h)
+		var prev *image.RGBA
+		if f.dispose == 2 {
+			prev = cloneRGBA(canvas)
+		}
+		op := draw.Src
+		if f.blend == 1 {
+			op = draw.Over
+		}
+		draw.Draw(canvas, r, img, image.ZP, op)
+		a.Frames = append(a.Frames, cloneRGBA(canvas))
+		den := time.Duration(f.delayDen)
+		if den == 0 {
+			den = 100
+		}
+		a.Delays = append(a.Delays, time.Duration(f.delayNum)*time.Second/den)
+		switch f.dispose {
+		case 1:
+			draw.Draw(canvas, r, image.Transparent, image.ZP, draw.Src)
+		case 2:
+			canvas = prev
+		}
+	}
+	return a, nil
+}
+
+// read one chunk from a PNG stream
+func readPNGChunk(r io.Reader) (c pngChunk, err error) {
+	var hdr [8]byte
+	if _, err = io.ReadFull(r, hdr[:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return
+	}
+	n := binary.BigEndian.Uint32(hdr[:4])
+	if n > 1<<30 {
+		return c, errors.New("apng: chunk too large")
+	}
+	c.typ = string(hdr[4:])
+	c.data = make([]byte, n)
+	if _, err = io.ReadFull(r, c.data); err != nil {
+		return
+	}
+	// skip CRC; image/png verifies the chunks of each reassembled frame
+	_, err = io.ReadFull(r, hdr[:4])
+	return
+}
+
+// decode the compressed image data of a w by h frame by reassembling it into
+// a standalone PNG
+func decodePNGFrame(ihdr []byte, header []pngChunk, w, h int, data []byte) (image.Image, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteString(pngSignature)
+	frameHdr := append([]byte{}, ihdr...)
+	binary.BigEndian.PutUint32(frameHdr[0:], uint32(w))
+	binary.BigEndian.PutUint32(frameHdr[4:], uint32(h))
+	writePNGChunk(buf, "IHDR", frameHdr)
+	for _, c := range header {
+		writePNGChunk(buf, c.typ, c.data)
+	}
+	writePNGChunk(buf, "IDAT", data)
+	writePNGChunk(buf, "IEND", nil)
+	return png.Decode(buf)
+}
+
+// write a chunk, with its CRC, to a PNG stream
+func writePNGChunk(w io.Writer, typ string, data []byte) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(len(data)))
+	w.Write(b[:])
+	crc := crc32.NewIEEE()
+	io.WriteString(crc, typ)
+	crc.Write(data)
+	io.WriteString(w, typ)
+	w.Write(data)
+	binary.BigEndian.PutUint32(b[:], crc.Sum32())
+	w.Write(b[:])
+}
@@ -0,0 +1,119 @@
+package objsearch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// return the concatenated IDAT data of a PNG encoding of img
+func idatData(t *testing.T, img image.Image) (ihdr, data []byte) {
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatal(err)
+	}
+	buf.Next(len(pngSignature))
+	for {
+		c, err := readPNGChunk(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch c.typ {
+		case "IHDR":
+			ihdr = c.data
+		case "IDAT":
+			data = append(data, c.data...)
+		case "IEND":
+			return
+		}
+	}
+}
+
+// return an fcTL chunk body
+func fcTL(seq, w, h, x, y int, delayMs uint16) []byte {
+	b := make([]byte, 26)
+	binary.BigEndian.PutUint32(b[0:], uint32(seq))
+	binary.BigEndian.PutUint32(b[4:], uint32(w))
+	binary.BigEndian.PutUint32(b[8:], uint32(h))
+	binary.BigEndian.PutUint32(b[12:], uint32(x))
+	binary.BigEndian.PutUint32(b[16:], uint32(y))
+	binary.BigEndian.PutUint16(b[20:], delayMs)
+	binary.BigEndian.PutUint16(b[22:], 1000)
+	return b
+}
+
+func TestAPNG(t *testing.T) {
+	bg := randomRGBImage(40, 40)
+	object := randomRGBImage(8, 8)
+	// frame 1 replaces a region of frame 0 with object
+	ihdr, data0 := idatData(t, bg)
+	_, data1 := idatData(t, object)
+	buf := &bytes.Buffer{}
+	buf.WriteString(pngSignature)
+	writePNGChunk(buf, "IHDR", ihdr)
+	writePNGChunk(buf, "acTL", []byte{0, 0, 0, 2, 0, 0, 0, 0})
+	writePNGChunk(buf, "fcTL", fcTL(0, 40, 40, 0, 0, 100))
+	writePNGChunk(buf, "IDAT", data0)
+	writePNGChunk(buf, "fcTL", fcTL(1, 8, 8, 25, 12, 100))
+	writePNGChunk(buf, "fdAT", append([]byte{0, 0, 0, 2}, data1...))
+	writePNGChunk(buf, "IEND", nil)
+	a, err := DecodeAnimation(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Frames) != 2 || a.Delays[0] != 100*time.Millisecond {
+		t.Fatal("APNG decode error", len(a.Frames), a.Delays)
+	}
+	hits := a.Search(object, 0, -1, Options{Tolerance: 0.01, MinDist: 8})
+	if len(hits) != 1 || hits[0].Frame != 1 || hits[0].P != (image.Point{25, 12}) || hits[0].Time != 100*time.Millisecond {
+		t.Fatal("APNG search error", hits)
+	}
+	if h := a.Search(object, -5, 1, Options{Tolerance: 0.01, MinDist: 8}); len(h) != 1 || h[0] != hits[0] {
+		t.Fatal("APNG search error for a negative first frame", h)
+	}
+	if h := a.Search(object, 5, -1, Options{Tolerance: 0.01, MinDist: 8}); h != nil {
+		t.Fatal("hits after the last frame", h)
+	}
+}
+
+func TestGIFAnimation(t *testing.T) {
+	frame := func(w, h int) *image.Paletted {
+		p := image.NewPaletted(image.Rect(0, 0, w, h), palette.WebSafe)
+		for i := range p.Pix {
+			p.Pix[i] = uint8(rand.Intn(len(palette.WebSafe)))
+		}
+		return p
+	}
+	object := frame(8, 8)
+	moved := image.NewPaletted(object.Rect.Add(image.Point{3, 20}), palette.WebSafe)
+	copy(moved.Pix, object.Pix)
+	g := &gif.GIF{
+		Image: []*image.Paletted{frame(40, 40), frame(40, 40), moved},
+		Delay: []int{5, 5, 5},
+	}
+	buf := &bytes.Buffer{}
+	if err := gif.EncodeAll(buf, g); err != nil {
+		t.Fatal(err)
+	}
+	a, err := DecodeAnimation(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rgba := image.NewRGBA(object.Rect)
+	draw.Draw(rgba, rgba.Rect, object, image.ZP, draw.Src)
+	hits := a.Search(rgba, 1, 2, Options{Tolerance: 0.01, MinDist: 8})
+	if len(hits) != 1 || hits[0].Frame != 2 || hits[0].P != (image.Point{3, 20}) || hits[0].Time != 100*time.Millisecond {
+		t.Fatal("GIF search error", hits)
+	}
+	if a.Frames[2].RGBAAt(0, 0) != color.RGBAModel.Convert(g.Image[1].At(0, 0)) {
+		t.Fatal("GIF compositing error")
+	}
+}
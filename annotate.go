@@ -0,0 +1,247 @@
+package objsearch
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strings"
+)
+
+// How DrawHits draws hits
+type HitStyle struct {
+	// Color of boxes and text. If nil, red is used.
+	Color color.Color
+	// Width of box edges in pixels. Zero is treated as 1.
+	Thickness int
+	// If true, each hit's score is written above its box
+	Score bool
+	// Scale of text, in multiples of its 3x5 pixel font. Zero is treated as
+	// 1.
+	TextScale int
+	// If true, each hit is colored by its score with Colormap instead of
+	// Color, with ScoreMin and ScoreMax mapped to the ends of the colormap.
+	// If ScoreMin and ScoreMax are both zero, [0,1] is used.
+	ColorByScore       bool
+	Colormap           Colormap
+	ScoreMin, ScoreMax float64
+	// If true, hits are numbered from 1, in the order given
+	Number bool
+	// If not zero, boxes are filled with their color at this opacity, in
+	// [0,1], tinting the hits
+	Fill float64
+	// If true, a legend strip is added below the field, showing the range of
+	// scores with ColorByScore, or the box color and number of hits
+	// otherwise
+	Legend bool
+}
+
+// Returns a copy of field with a box drawn around each of hits, styled by
+// style. object is the bounds of the object the hits were found for, so that
+// the boxes cover its windows whatever its origin.
+func DrawHits(field image.Image, hits []Hit, object image.Rectangle, style HitStyle) *image.RGBA {
+	dst := newCanvas(field, style)
+	for i, h := range hits {
+		text := hitNumber(style, i)
+		if style.Score {
+			text = strings.TrimSpace(text + fmt.Sprintf(" %.3f", h.S))
+		}
+		drawBox(dst, object.Add(h.P), text, style, style.hitColor(h.S))
+	}
+	drawLegend(dst, field.Bounds(), len(hits), style)
+	return dst
+}
+
+// Returns a copy of field with a box drawn around each of hits, labeled with
+// its label, and with its score if style.Score is set. Box sizes are those of
+// the objects registered in set.
+func DrawLabeledHits(field image.Image, hits []LabeledHit, set *TemplateSet, style HitStyle) *image.RGBA {
+	dst := newCanvas(field, style)
+	for i, h := range hits {
+		text := strings.TrimSpace(hitNumber(style, i) + " " + h.Label)
+		if style.Score {
+			text += fmt.Sprintf(" %.3f", h.S)
+		}
+		drawBox(dst, set.Searcher(h.Label).Object.Bounds().Add(h.P), text, style, style.hitColor(h.S))
+	}
+	drawLegend(dst, field.Bounds(), len(hits), style)
+	return dst
+}
+
+// return the color of a hit with score s
+func (style HitStyle) hitColor(s float64) color.Color {
+	if style.ColorByScore {
+		min, max := style.scoreRange()
+		return style.Colormap.Color((s - min) / (max - min))
+	}
+	if style.Color == nil {
+		return color.RGBA{255, 0, 0, 255}
+	}
+	return style.Color
+}
+
+// return the scores mapped to the ends of style.Colormap
+func (style HitStyle) scoreRange() (min, max float64) {
+	if style.ScoreMin == 0 && style.ScoreMax == 0 {
+		return 0, 1
+	}
+	return style.ScoreMin, style.ScoreMax
+}
+
+// return the number of the i-th hit, or "" if hits are not numbered
+func hitNumber(style HitStyle, i int) string {
+	if !style.Number {
+		return ""
+	}
+	return fmt.Sprint(i + 1)
+}
+
+// return the text scale of style
+func (style HitStyle) textScale() int {
+	if style.TextScale <= 0 {
+		return 1
+	}
+	return style.TextScale
+}
+
+// return the height of the legend strip of style, or 0 if it has none
+func (style HitStyle) legendHeight() int {
+	if !style.Legend {
+		return 0
+	}
+	return 9 * style.textScale()
+}
+
+// return a copy of field, extended below by the legend strip of style
+func newCanvas(field image.Image, style HitStyle) *image.RGBA {
+	b := field.Bounds()
+	dst := image.NewRGBA(image.Rectangle{b.Min, b.Max.Add(image.Point{0, style.legendHeight()})})
+	draw.Draw(dst, dst.Rect, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, b, field, b.Min, draw.Src)
+	return dst
+}
+
+// draw the legend of n hits in the strip of dst below field, if style has
+// one
+func drawLegend(dst *image.RGBA, field image.Rectangle, n int, style HitStyle) {
+	h := style.legendHeight()
+	if h == 0 {
+		return
+	}
+	s := style.textScale()
+	black := image.NewUniform(color.Black)
+	p := image.Point{field.Min.X + 2*s, field.Max.Y + 2*s}
+	if !style.ColorByScore {
+		draw.Draw(dst, image.Rectangle{p, p.Add(image.Point{5 * s, 5 * s})}, image.NewUniform(style.hitColor(0)), image.Point{}, draw.Src)
+		drawText(dst, p.Add(image.Point{7 * s, 0}), fmt.Sprintf("%d hits", n), s, black)
+		return
+	}
+	// a gradient bar between the ends of the score range
+	min, max := style.scoreRange()
+	lo, hi := fmt.Sprintf("%.3g", min), fmt.Sprintf("%.3g", max)
+	drawText(dst, p, lo, s, black)
+	x0 := p.X + (4*len(lo)+1)*s
+	x1 := field.Max.X - (4*len(hi)+3)*s
+	for x := x0; x < x1; x++ {
+		c := style.Colormap.Color(float64(x-x0) / float64(x1-x0))
+		draw.Draw(dst, image.Rect(x, p.Y, x+1, p.Y+5*s), image.NewUniform(c), image.Point{}, draw.Src)
+	}
+	drawText(dst, image.Point{x1 + 2*s, p.Y}, hi, s, black)
+}
+
+// draw a box at r in color c with text above it, or inside it if there is
+// no room above
+func drawBox(dst *image.RGBA, r image.Rectangle, text string, style HitStyle, c color.Color) {
+	u := image.NewUniform(c)
+	t := style.Thickness
+	if t <= 0 {
+		t = 1
+	}
+	if style.Fill != 0 {
+		alpha := image.NewUniform(color.Alpha{uint8(math.Round(255 * math.Max(0, math.Min(1, style.Fill))))})
+		draw.DrawMask(dst, r, u, image.Point{}, alpha, image.Point{}, draw.Over)
+	}
+	for _, edge := range []image.Rectangle{
+		{r.Min, image.Point{r.Max.X, r.Min.Y + t}},
+		{image.Point{r.Min.X, r.Max.Y - t}, r.Max},
+		{r.Min, image.Point{r.Min.X + t, r.Max.Y}},
+		{image.Point{r.Max.X - t, r.Min.Y}, r.Max},
+	} {
+		draw.Draw(dst, edge.Intersect(r), u, image.Point{}, draw.Over)
+	}
+	if text == "" {
+		return
+	}
+	s := style.textScale()
+	p := image.Point{r.Min.X, r.Min.Y - 6*s}
+	if p.Y < dst.Rect.Min.Y {
+		p = r.Min.Add(image.Point{t + 1, t + 1})
+	}
+	drawText(dst, p, text, s, u)
+}
+
+// draw text with its top-left corner at p, in the 3x5 pixel font scaled by s
+func drawText(dst draw.Image, p image.Point, text string, s int, src image.Image) {
+	for _, ch := range strings.ToUpper(text) {
+		g, ok := glyphs[ch]
+		if !ok {
+			g = glyphs['?']
+		}
+		for i := range g {
+			if g[i] == '#' {
+				px := p.Add(image.Point{i % 3 * s, i / 3 * s})
+				draw.Draw(dst, image.Rectangle{px, px.Add(image.Point{s, s})}, src, image.Point{}, draw.Over)
+			}
+		}
+		p.X += 4 * s
+	}
+}
+
+// a 3x5 pixel font, in row-major order
+var glyphs = map[rune]string{
+	' ': "...............",
+	'?': "###..#.#.....#.",
+	'.': ".............#.",
+	'-': "......###......",
+	'_': "............###",
+	':': "....#.....#....",
+	'#': "#.#####.#####.#",
+	'/': "..#..#.#.#..#..",
+	'0': "####.##.##.####",
+	'1': ".#.##..#..#.###",
+	'2': "###..#####..###",
+	'3': "###..####..####",
+	'4': "#.##.####..#..#",
+	'5': "####..###..####",
+	'6': "####..####.####",
+	'7': "###..#..#..#..#",
+	'8': "####.#####.####",
+	'9': "####.####..####",
+	'A': ".#.#.#####.##.#",
+	'B': "##.#.###.#.###.",
+	'C': ".###..#..#...##",
+	'D': "##.#.##.##.###.",
+	'E': "####..##.#..###",
+	'F': "####..##.#..#..",
+	'G': ".###..#.##.#.##",
+	'H': "#.##.#####.##.#",
+	'I': "###.#..#..#.###",
+	'J': "..#..#..##.#.#.",
+	'K': "#.##.###.#.##.#",
+	'L': "#..#..#..#..###",
+	'M': "#.########.##.#",
+	'N': "##.#.##.##.##.#",
+	'O': ".#.#.##.##.#.#.",
+	'P': "##.#.###.#..#..",
+	'Q': ".#.#.##.###..##",
+	'R': "##.#.###.#.##.#",
+	'S': ".###...#...###.",
+	'T': "###.#..#..#..#.",
+	'U': "#.##.##.##.####",
+	'V': "#.##.##.##.#.#.",
+	'W': "#.##.########.#",
+	'X': "#.##.#.#.#.##.#",
+	'Y': "#.##.#.#..#..#.",
+	'Z': "###..#.#.#..###",
+}
@@ -0,0 +1,111 @@
+package objsearch
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDrawHits(t *testing.T) {
+	field := image.NewRGBA(image.Rect(0, 0, 60, 40))
+	red := color.RGBA{255, 0, 0, 255}
+	img := DrawHits(field, []Hit{{image.Point{10, 20}, 0.25}}, image.Rect(0, 0, 8, 6), HitStyle{Score: true})
+	if img == field || field.RGBAAt(10, 20) == red {
+		t.Fatal("field was modified")
+	}
+	for _, p := range []image.Point{{10, 20}, {17, 20}, {10, 25}, {17, 25}, {13, 20}, {10, 23}} {
+		if img.RGBAAt(p.X, p.Y) != red {
+			t.Fatal("box edge not drawn at", p)
+		}
+	}
+	if img.RGBAAt(13, 23) == red || img.RGBAAt(18, 26) == red {
+		t.Fatal("box drawn outside its edges")
+	}
+	// "0.250" is written in the 5 rows above the box
+	text := 0
+	for y := 14; y < 19; y++ {
+		for x := 10; x < 30; x++ {
+			if img.RGBAAt(x, y) == red {
+				text++
+			}
+		}
+	}
+	if text == 0 {
+		t.Fatal("score not drawn")
+	}
+	// with no room above, text is drawn inside the box
+	img = DrawHits(field, []Hit{{image.Point{0, 0}, 0.5}}, image.Rect(0, 0, 30, 20), HitStyle{Score: true, Thickness: 2, TextScale: 2})
+	if img.RGBAAt(1, 1) != red || img.RGBAAt(2, 2) == red || img.RGBAAt(3, 3) != red {
+		t.Fatal("thick box or scaled text error")
+	}
+}
+
+// test that boxes cover the windows of an object whose bounds do not start
+// at the origin
+func TestDrawHitsObjectOrigin(t *testing.T) {
+	field := image.NewRGBA(image.Rect(0, 0, 60, 40))
+	red := color.RGBA{255, 0, 0, 255}
+	img := DrawHits(field, []Hit{{image.Point{10, 20}, 0}}, image.Rect(5, 3, 13, 9), HitStyle{})
+	for _, p := range []image.Point{{15, 23}, {22, 23}, {15, 28}, {22, 28}} {
+		if img.RGBAAt(p.X, p.Y) != red {
+			t.Fatal("box corner not drawn at", p)
+		}
+	}
+	if img.RGBAAt(10, 20) == red || img.RGBAAt(23, 29) == red {
+		t.Fatal("box drawn at the object's origin")
+	}
+}
+
+func TestDrawLabeledHits(t *testing.T) {
+	field := image.NewRGBA(image.Rect(0, 0, 60, 40))
+	set := NewTemplateSet(Options{})
+	set.Add("a", image.NewRGBA(image.Rect(0, 0, 5, 5)))
+	blue := color.RGBA{0, 0, 255, 255}
+	img := DrawLabeledHits(field, []LabeledHit{{Hit{image.Point{20, 20}, 0}, "a"}}, set, HitStyle{Color: blue})
+	if img.RGBAAt(24, 24) != blue || img.RGBAAt(25, 25) == blue {
+		t.Fatal("labeled box error")
+	}
+	// "A" is drawn above the box
+	if img.RGBAAt(21, 14) != blue {
+		t.Fatal("label not drawn")
+	}
+}
+
+func TestDrawHitsStyles(t *testing.T) {
+	field := image.NewRGBA(image.Rect(0, 0, 60, 40))
+	hits := []Hit{{image.Point{5, 10}, 0}, {image.Point{30, 10}, 1}}
+	style := HitStyle{ColorByScore: true, Colormap: COLORMAP_GRAY, Fill: 0.5, Number: true, Legend: true}
+	img := DrawHits(field, hits, image.Rect(0, 0, 10, 10), style)
+	if img.Rect.Dy() != 40+style.legendHeight() || img.Rect.Dx() != 60 {
+		t.Fatal("legend strip not added", img.Rect)
+	}
+	// boxes are colored by score
+	if c := img.RGBAAt(5, 10); c != (color.RGBA{0, 0, 0, 255}) {
+		t.Fatal("best hit color", c)
+	}
+	if c := img.RGBAAt(30, 10); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Fatal("worst hit color", c)
+	}
+	// and tinted inside
+	if c := img.RGBAAt(35, 15); c.R < 100 || c.R > 150 {
+		t.Fatal("box not tinted", c)
+	}
+	// "1" is drawn above the first box
+	if img.RGBAAt(6, 4) != (color.RGBA{0, 0, 0, 255}) {
+		t.Fatal("hit not numbered")
+	}
+	// the legend's gradient runs from black to white
+	y := 40 + 4
+	if img.RGBAAt(30, y) == (color.RGBA{255, 255, 255, 255}) {
+		t.Fatal("legend not drawn")
+	}
+	lo, hi := img.RGBAAt(12, y), img.RGBAAt(40, y)
+	if lo.R >= hi.R {
+		t.Fatal("legend gradient", lo, hi)
+	}
+	// without ColorByScore, the legend has a swatch of the box color
+	img = DrawHits(field, hits, image.Rect(0, 0, 10, 10), HitStyle{Legend: true})
+	if img.RGBAAt(3, 43) != (color.RGBA{255, 0, 0, 255}) {
+		t.Fatal("legend swatch not drawn")
+	}
+}
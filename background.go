@@ -0,0 +1,124 @@
+package objsearch
+
+import (
+	"image"
+	"time"
+)
+
+// A running-average background model for video. Searching the foreground
+// images it produces makes moving objects stand out against busy but static
+// backgrounds.
+type BackgroundSubtractor struct {
+	// Rate at which the background adapts to each new frame, in (0,1]
+	Rate float64
+	// Pixels with no channel differing from the background by more than
+	// Threshold are background
+	Threshold uint8
+	// If false, foreground images hold the absolute difference between each
+	// frame and the background. If true, they hold the frame's colors at
+	// foreground pixels, and black at background pixels.
+	KeepColor bool
+	// background model, with 3 channels per pixel
+	bg   []float64
+	rect image.Rectangle
+}
+
+// Returns a BackgroundSubtractor adapting at rate
+func NewBackgroundSubtractor(rate float64) *BackgroundSubtractor {
+	return &BackgroundSubtractor{Rate: rate}
+}
+
+// Returns the foreground image of frame, and then updates the background
+// model with frame. The first frame initializes the model, and so has no
+// foreground. If the bounds of the frames change, the model is
+// reinitialized.
+func (b *BackgroundSubtractor) Apply(frame image.Image) *image.RGBA {
+	f := toRGBA(frame)
+	if b.bg == nil || b.rect != f.Rect {
+		b.rect = f.Rect
+		b.bg = make([]float64, 3*f.Rect.Dx()*f.Rect.Dy())
+		b.update(f, 1)
+	}
+	fg := image.NewRGBA(f.Rect)
+	i := 0
+	for y := f.Rect.Min.Y; y < f.Rect.Max.Y; y++ {
+		for x := f.Rect.Min.X; x < f.Rect.Max.X; x++ {
+			o, fo := f.PixOffset(x, y), fg.PixOffset(x, y)
+			var d [3]uint8
+			foreground := false
+			for c := 0; c < 3; c++ {
+				diff := float64(f.Pix[o+c]) - b.bg[i+c]
+				if diff < 0 {
+					diff = -diff
+				}
+				d[c] = uint8(diff + 0.5)
+				foreground = foreground || d[c] > b.Threshold
+			}
+			switch {
+			case b.KeepColor && foreground:
+				copy(fg.Pix[fo:fo+3], f.Pix[o:o+3])
+			case !b.KeepColor:
+				copy(fg.Pix[fo:fo+3], d[:])
+			}
+			fg.Pix[fo+3] = 255
+			i += 3
+		}
+	}
+	b.update(f, b.Rate)
+	return fg
+}
+
+// blend f into the background model at rate
+func (b *BackgroundSubtractor) update(f *image.RGBA, rate float64) {
+	i := 0
+	for y := f.Rect.Min.Y; y < f.Rect.Max.Y; y++ {
+		for x := f.Rect.Min.X; x < f.Rect.Max.X; x++ {
+			o := f.PixOffset(x, y)
+			for c := 0; c < 3; c++ {
+				b.bg[i+c] += rate * (float64(f.Pix[o+c]) - b.bg[i+c])
+			}
+			i += 3
+		}
+	}
+}
+
+// Returns the current background model as an image, or nil if no frames
+// have been applied
+func (b *BackgroundSubtractor) Background() *image.RGBA {
+	if b.bg == nil {
+		return nil
+	}
+	img := image.NewRGBA(b.rect)
+	i := 0
+	for y := b.rect.Min.Y; y < b.rect.Max.Y; y++ {
+		for x := b.rect.Min.X; x < b.rect.Max.X; x++ {
+			o := img.PixOffset(x, y)
+			for c := 0; c < 3; c++ {
+				img.Pix[o+c] = uint8(b.bg[i+c] + 0.5)
+			}
+			img.Pix[o+3] = 255
+			i += 3
+		}
+	}
+	return img
+}
+
+// A FrameSource wrapping another, whose frames are the foreground images of
+// the wrapped source's frames
+type BackgroundSource struct {
+	Src FrameSource
+	*BackgroundSubtractor
+}
+
+// Returns a BackgroundSource over src with a background adapting at rate
+func NewBackgroundSource(src FrameSource, rate float64) *BackgroundSource {
+	return &BackgroundSource{src, NewBackgroundSubtractor(rate)}
+}
+
+func (b *BackgroundSource) Next() (image.Image, time.Time, error) {
+	frame, t, err := b.Src.Next()
+	if err != nil {
+		return nil, t, err
+	}
+	return b.Apply(frame), t, nil
+}
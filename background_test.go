@@ -0,0 +1,29 @@
+package objsearch
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestBackgroundSubtractor(t *testing.T) {
+	bg := randomRGBImage(60, 40)
+	object := randomRGBImage(8, 8)
+	b := NewBackgroundSubtractor(0.1)
+	b.KeepColor = true
+	// the first frame initializes the background
+	if fg := b.Apply(bg); fg.RGBAAt(30, 20) != (color.RGBA{0, 0, 0, 255}) {
+		t.Fatal("first frame has foreground")
+	}
+	for i := 0; i < 4; i++ {
+		p := image.Point{10 + 10*i, 20}
+		fg := b.Apply(frameWithObject(bg, object, p))
+		h := SearchImage(fg, object, image.Rect(0, 0, 52, 32), Options{Tolerance: 0.2, MinDist: 8})
+		if len(h) == 0 || h[0].P != p {
+			t.Fatal("foreground search error", i, h)
+		}
+	}
+	if b.Background().Rect != bg.Rect {
+		t.Fatal("Background error")
+	}
+}
@@ -0,0 +1,126 @@
+package objsearch
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// The hits of a search of one image file, as returned by SearchFiles
+type FileHits struct {
+	Path string
+	// Hits found in the file, best first
+	Hits []Hit
+	// Error reading or decoding the file, if any
+	Err error
+}
+
+// Searches each image file matching pattern for object, loading and
+// searching up to workers files at a time, or runtime.NumCPU() if workers is
+// zero, e.g. to find which of a folder of screenshots show a dialog.
+// pattern is a filepath.Glob pattern, or a directory, in which case every
+// file in it is searched. Every position at which object lies within a
+// field is searched.
+//
+// Returns the files with hits, sorted by their best hit's score, followed
+// by the files without hits and those that could not be read, decoded or
+// searched, each in path order. Returns an error only if pattern is
+// malformed or the directory cannot be read.
+func SearchFiles(pattern string, object image.Image, opts Options, workers int) ([]FileHits, error) {
+	paths, err := batchPaths(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	o := NewObject(object)
+	results := make([]FileHits, len(paths))
+	next := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			for i := range next {
+				results[i].Path = paths[i]
+				results[i].Hits, results[i].Err = searchFile(paths[i], o, opts)
+			}
+			wg.Done()
+		}()
+	}
+	for i := range paths {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	ctx := newContext(image.Rectangle{}, opts)
+	// files with hits first, then without, then failed
+	rank := func(r FileHits) int {
+		switch {
+		case len(r.Hits) > 0:
+			return 0
+		case r.Err == nil:
+			return 1
+		}
+		return 2
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if rank(a) != rank(b) {
+			return rank(a) < rank(b)
+		}
+		if rank(a) == 0 && a.Hits[0].S != b.Hits[0].S {
+			return ctx.better(a.Hits[0].S, b.Hits[0].S)
+		}
+		return a.Path < b.Path
+	})
+	return results, nil
+}
+
+// search the image file at path for o, converting a panicking search, as of
+// a uniform object on a uniform field, to an error
+func searchFile(path string, o *Object, opts Options) (hits []Hit, err error) {
+	f, err := LoadFieldFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			hits, err = nil, fmt.Errorf("objsearch: searching %s: %v", path, r)
+		}
+	}()
+	return SearchImage(f, o, SearchableRect(f.Bounds(), o.Bounds(), opts), opts), nil
+}
+
+// return the paths of the files matching pattern, a glob or a directory, in
+// lexical order
+func batchPaths(pattern string) ([]string, error) {
+	if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+		entries, err := os.ReadDir(pattern)
+		if err != nil {
+			return nil, err
+		}
+		var paths []string
+		for _, e := range entries {
+			if !e.IsDir() {
+				paths = append(paths, filepath.Join(pattern, e.Name()))
+			}
+		}
+		return paths, nil
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, m := range matches {
+		if info, err := os.Stat(m); err == nil && !info.IsDir() {
+			paths = append(paths, m)
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,100 @@
+package objsearch
+
+import (
+	"encoding/json"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSearchFiles(t *testing.T) {
+	object := randomRGBImage(10, 10)
+	noisy := image.NewRGBA(object.Rect)
+	copy(noisy.Pix, object.Pix)
+	for i := 0; i < len(noisy.Pix); i += 8 {
+		noisy.Pix[i] ^= 8
+	}
+	dir := t.TempDir()
+	write := func(name string, img image.Image) {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if err := png.Encode(f, img); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.png", randomRGBImage(50, 40))
+	write("b.png", frameWithObject(randomRGBImage(50, 40), noisy, image.Point{5, 6}))
+	write("c.png", frameWithObject(randomRGBImage(60, 40), object, image.Point{30, 20}))
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not an image"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{Tolerance: 0.05, MinDist: 5, ScoreMode: SCOREMODE_SQDIFF_NORMED}
+	res, err := SearchFiles(dir, object, opts, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := func(r FileHits) string { return filepath.Base(r.Path) }
+	if len(res) != 4 || name(res[0]) != "c.png" || name(res[1]) != "b.png" || name(res[2]) != "a.png" || name(res[3]) != "notes.txt" {
+		t.Fatal("batch order error", res)
+	}
+	if len(res[0].Hits) != 1 || res[0].Hits[0] != (Hit{image.Point{30, 20}, 0}) || len(res[1].Hits) != 1 || res[1].Hits[0].P != (image.Point{5, 6}) {
+		t.Fatal("batch hits error", res)
+	}
+	if res[2].Hits != nil || res[2].Err != nil || res[3].Err == nil {
+		t.Fatal("batch error", res)
+	}
+	// a glob leaves out the text file
+	if res, err := SearchFiles(filepath.Join(dir, "*.png"), object, opts, 0); err != nil || len(res) != 3 {
+		t.Fatal("glob error", res, err)
+	}
+	if _, err := SearchFiles("[", object, opts, 0); err == nil {
+		t.Fatal("expected pattern error")
+	}
+	b, err := json.Marshal(res[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d FileHits
+	if err := json.Unmarshal(b, &d); err != nil || d.Path != res[3].Path || d.Err.Error() != res[3].Err.Error() {
+		t.Fatal("JSON round trip error", string(b), err)
+	}
+}
+
+// test that a file whose search panics is reported with an error
+func TestSearchFilesPanic(t *testing.T) {
+	white := func(w, h int) *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, w, h))
+		for i := range img.Pix {
+			img.Pix[i] = 255
+		}
+		return img
+	}
+	object := white(4, 4)
+	dir := t.TempDir()
+	for name, img := range map[string]image.Image{
+		"uniform.png": white(20, 20),
+		"field.png":   frameWithObject(randomRGBImage(20, 20), object, image.Point{7, 9}),
+	} {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = png.Encode(f, img)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	res, err := SearchFiles(dir, object, Options{Tolerance: 0.05, MinDist: 4}, 2)
+	if err != nil || len(res) != 2 || filepath.Base(res[1].Path) != "uniform.png" || res[1].Err == nil {
+		t.Fatal("expected search error", res, err)
+	}
+	if len(res[0].Hits) == 0 || res[0].Hits[0].P != (image.Point{7, 9}) {
+		t.Fatal("batch hits error", res)
+	}
+}
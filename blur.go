@@ -0,0 +1,81 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+)
+
+// return the Gaussian blurs of planes with standard deviation sigma, over
+// the part of each plane within r. Pixels beyond the edges of a plane are
+// taken from its nearest edge.
+func blurPlanes(planes []*FloatImage, sigma float64, r image.Rectangle) []*FloatImage {
+	k := gaussianKernel(sigma)
+	blurred := make([]*FloatImage, len(planes))
+	for i, p := range planes {
+		blurred[i] = blur(p, k, r.Intersect(p.Rect))
+	}
+	return blurred
+}
+
+// return the normalized Gaussian kernel with standard deviation sigma,
+// truncated at 3 sigma. Its center is at len/2.
+func gaussianKernel(sigma float64) []float64 {
+	n := int(math.Ceil(3 * sigma))
+	k := make([]float64, 2*n+1)
+	sum := 0.0
+	for i := range k {
+		x := float64(i - n)
+		k[i] = math.Exp(-x * x / (2 * sigma * sigma))
+		sum += k[i]
+	}
+	for i := range k {
+		k[i] /= sum
+	}
+	return k
+}
+
+// return p convolved with k horizontally and vertically, over r
+func blur(p *FloatImage, k []float64, r image.Rectangle) *FloatImage {
+	n := len(k) / 2
+	clamp := func(v, min, max int) int {
+		if v < min {
+			return min
+		}
+		if v >= max {
+			return max - 1
+		}
+		return v
+	}
+	// rows of r, extended by n above and below, blurred horizontally
+	ext := image.Rect(r.Min.X, r.Min.Y-n, r.Max.X, r.Max.Y+n).Intersect(p.Rect)
+	h := NewFloatImage(ext)
+	for y := ext.Min.Y; y < ext.Max.Y; y++ {
+		for x := ext.Min.X; x < ext.Max.X; x++ {
+			s := 0.0
+			for i, w := range k {
+				s += w * p.Pix[p.PixOffset(clamp(x+i-n, p.Rect.Min.X, p.Rect.Max.X), y)]
+			}
+			h.Pix[h.PixOffset(x, y)] = s
+		}
+	}
+	dst := NewFloatImage(r)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			s := 0.0
+			for i, w := range k {
+				s += w * h.Pix[h.PixOffset(x, clamp(y+i-n, ext.Min.Y, ext.Max.Y))]
+			}
+			dst.Pix[dst.PixOffset(x, y)] = s
+		}
+	}
+	return dst
+}
+
+// return field and object blurred with standard deviation sigma, over the
+// part of field searched for object at each point of rect
+func blurInputs(field, object []*FloatImage, rect image.Rectangle, sigma float64) ([]*FloatImage, []*FloatImage) {
+	o := object[0].Rect
+	n := len(gaussianKernel(sigma)) / 2
+	searched := image.Rectangle{rect.Min.Add(o.Min), rect.Max.Add(o.Max).Sub(image.Point{1, 1})}
+	return blurPlanes(field, sigma, searched.Inset(-n)), blurPlanes(object, sigma, o)
+}
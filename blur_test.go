@@ -0,0 +1,61 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// test that an object rendered with smoothing is found with Options.Blur,
+// rather than a brighter copy
+func TestBlur(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	object := seededFloatImage(rnd, 12, 12, 0.1, 0.9)
+	field := seededFloatImage(rnd, 60, 60, 0, 1)
+	// the object smoothed horizontally, as font smoothing would render it
+	p := image.Point{20, 20}
+	for y := 0; y < 12; y++ {
+		for x := 0; x < 12; x++ {
+			v := object.FloatAt(x, y) / 2
+			for _, dx := range []int{-1, 1} {
+				if x+dx < 0 || x+dx >= 12 {
+					v += object.FloatAt(x, y) / 4
+				} else {
+					v += object.FloatAt(x+dx, y) / 4
+				}
+			}
+			field.Pix[field.PixOffset(p.X+x, p.Y+y)] = v
+		}
+	}
+	pasteFloat(field, object, image.Point{40, 36}, 0.08)
+	testOptionFinds(t, field, object, p, image.Point{40, 36}, 0.05, func(opts *Options) {
+		opts.Blur = 1
+	})
+}
+
+// test that blurring part of a plane matches blurring all of it
+func TestBlurPlanes(t *testing.T) {
+	p := randomFloatImage(30, 20, 1)
+	r := image.Rect(5, 3, 17, 19)
+	all := blurPlanes([]*FloatImage{p}, 1.5, p.Rect)[0]
+	part := blurPlanes([]*FloatImage{p}, 1.5, r)[0]
+	if part.Rect != r {
+		t.Fatal("wrong bounds", part.Rect)
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if math.Abs(part.FloatAt(x, y)-all.FloatAt(x, y)) > 1e-12 {
+				t.Fatal("partial blur differs at", x, y)
+			}
+		}
+	}
+	// blurring preserves the mean of a constant plane
+	c := NewFloatImage(p.Rect)
+	for i := range c.Pix {
+		c.Pix[i] = 0.25
+	}
+	if v := blurPlanes([]*FloatImage{c}, 2, c.Rect)[0].FloatAt(0, 0); math.Abs(v-0.25) > 1e-12 {
+		t.Fatal("constant plane changed", v)
+	}
+}
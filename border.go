@@ -0,0 +1,143 @@
+package objsearch
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// How positions at which the object extends past the edges of the field are
+// scored, so that objects partly outside the field, such as sprites half
+// off-screen, are still found
+type BorderMode int
+
+const (
+	// The object must lie entirely within the field at every position
+	// searched
+	BORDERMODE_NONE BorderMode = iota
+	// The field is extended past its edges with zeros
+	BORDERMODE_ZERO
+	// The field is extended past its edges by repeating its edge pixels
+	BORDERMODE_CLAMP
+	// The field is extended past its edges by reflecting it about its edge
+	// pixels, which are not repeated
+	BORDERMODE_MIRROR
+	// Only the part of the object overlapping the field is compared, and
+	// distances and scores are normalized by its weight, so that an object
+	// half outside the field scores as well as its visible half matches.
+	// Positions at which no opaque object pixel overlaps the field score
+	// worst. Scores of small overlaps are noisy, so the search rectangle
+	// should hold only positions at which enough of the object is visible.
+	BORDERMODE_OVERLAP
+)
+
+func (m BorderMode) String() string {
+	switch m {
+	case BORDERMODE_NONE:
+		return "none"
+	case BORDERMODE_ZERO:
+		return "zero"
+	case BORDERMODE_CLAMP:
+		return "clamp"
+	case BORDERMODE_MIRROR:
+		return "mirror"
+	case BORDERMODE_OVERLAP:
+		return "overlap"
+	}
+	return fmt.Sprintf("BorderMode(%d)", int(m))
+}
+
+// return the rectangle of top-left corners at which object may be searched
+// for in field according to border: those at which it lies entirely within
+// field for BORDERMODE_NONE, and those at which it overlaps field otherwise
+func searchableRect(field, object image.Rectangle, border BorderMode) image.Rectangle {
+	if border == BORDERMODE_NONE {
+		return validRect(field, object)
+	}
+	if field.Empty() || object.Empty() {
+		return image.Rectangle{}
+	}
+	return image.Rectangle{
+		field.Min.Sub(object.Max).Add(image.Point{1, 1}),
+		field.Max.Sub(object.Min),
+	}
+}
+
+// return the field planes extended past their edges according to border, so
+// that an object with bounds object lies within them at every position at
+// which it overlaps the field
+func padPlanes(field []*FloatImage, object image.Rectangle, border BorderMode) []*FloatImage {
+	if border == BORDERMODE_NONE {
+		return field
+	}
+	r := field[0].Rect
+	ext := image.Point{object.Dx() - 1, object.Dy() - 1}
+	padded := image.Rectangle{r.Min.Sub(ext), r.Max.Add(ext)}
+	// return the field coordinate in [min,max) that i is taken from
+	source := func(i, min, max int) int {
+		switch border {
+		case BORDERMODE_CLAMP:
+			if i < min {
+				return min
+			} else if i >= max {
+				return max - 1
+			}
+		case BORDERMODE_MIRROR:
+			if max-min == 1 {
+				return min
+			}
+			// reflections repeat with this period
+			n := 2 * (max - min - 1)
+			i = ((i-min)%n + n) % n
+			if i >= max-min {
+				i = n - i
+			}
+			return min + i
+		}
+		return i
+	}
+	p := make([]*FloatImage, len(field))
+	for c := range field {
+		p[c] = NewFloatImage(padded)
+		for y := padded.Min.Y; y < padded.Max.Y; y++ {
+			for x := padded.Min.X; x < padded.Max.X; x++ {
+				// out of bounds pixels are zero
+				p[c].Pix[p[c].PixOffset(x, y)] = field[c].FloatAt(source(x, r.Min.X, r.Max.X), source(y, r.Min.Y, r.Max.Y))
+			}
+		}
+	}
+	return p
+}
+
+// return true if the field window w lies within the field, or pixels past
+// its edges are compared according to ctx.Border
+func (ctx objSearchContext) covered(w image.Rectangle) bool {
+	return ctx.Border != BORDERMODE_OVERLAP || w.In(ctx.FieldRect)
+}
+
+// return true if the field pixel at (x,y) is compared according to
+// ctx.Border
+func (ctx objSearchContext) inField(x, y int) bool {
+	return ctx.Border != BORDERMODE_OVERLAP || (image.Point{x, y}).In(ctx.FieldRect)
+}
+
+// replace the NaN distances of res, at positions at which no opaque object
+// pixel overlaps the field, with the largest distance
+func (res *objSearchResult) fillUncovered() {
+	max, nan := 0.0, false
+	for j := 0; j < res.len(); j++ {
+		if v := res.at(j); math.IsNaN(v) {
+			nan = true
+		} else {
+			max = math.Max(max, v)
+		}
+	}
+	if !nan {
+		return
+	}
+	for j := 0; j < res.len(); j++ {
+		if math.IsNaN(res.at(j)) {
+			res.set(j, max)
+		}
+	}
+}
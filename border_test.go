@@ -0,0 +1,82 @@
+package objsearch
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestBorderOverlap(t *testing.T) {
+	object := randomRGBImage(10, 10)
+	field := randomRGBImage(60, 50)
+	for _, p := range []image.Point{{-4, 20}, {30, 15}, {53, -3}} {
+		field = frameWithObject(field, object, p)
+	}
+	for _, opts := range []Options{
+		{Tolerance: 0.05, MinDist: 5},
+		{Tolerance: 0.01, MinDist: 5, ScoreMode: SCOREMODE_SQDIFF_NORMED},
+		{Tolerance: 0.99, MinDist: 5, ScoreMode: SCOREMODE_CCOEFF_NORMED},
+	} {
+		// the partly visible objects are not found
+		if hits := SearchResult(field, object, image.Rectangle{}, opts).Hits; len(hits) != 1 {
+			t.Fatal("hits past the field's edges", opts.ScoreMode, hits)
+		}
+		// positions at which at least half the object is visible
+		opts.Border = BORDERMODE_OVERLAP
+		rect := image.Rect(-5, -5, 56, 46)
+		hits := SearchResult(field, object, rect, opts).Hits
+		SortRaster(hits)
+		if len(hits) != 3 || hits[0].P != (image.Point{53, -3}) || hits[1].P != (image.Point{30, 15}) || hits[2].P != (image.Point{-4, 20}) {
+			t.Fatal("border search error", opts.ScoreMode, hits)
+		}
+	}
+	// no opaque pixel overlaps the field, as only the masked right column
+	// does
+	mask := image.NewAlpha(object.Rect)
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 9; x++ {
+			mask.SetAlpha(x, y, color.Alpha{255})
+		}
+	}
+	d := DistanceMap(field, object, image.Rect(-9, 0, -7, 1), Options{Border: BORDERMODE_OVERLAP, Mask: mask})
+	if d.Pix[0] != d.Pix[1] {
+		t.Fatal("uncovered distance", d.Pix)
+	}
+	if r := searchableRect(field.Rect, object.Rect, BORDERMODE_ZERO); r != image.Rect(-9, -9, 60, 50) {
+		t.Fatal("searchable rect error", r)
+	}
+}
+
+func TestBorderZero(t *testing.T) {
+	// an object whose left half is black, half past the field's left edge
+	object := randomRGBImage(10, 10)
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 5; x++ {
+			object.Set(x, y, color.Black)
+		}
+	}
+	field := frameWithObject(randomRGBImage(60, 50), object, image.Point{-5, 20})
+	hits := SearchResult(field, object, image.Rectangle{}, Options{Tolerance: 0.01, Border: BORDERMODE_ZERO}).Hits
+	if len(hits) != 1 || hits[0] != (Hit{image.Point{-5, 20}, 0}) {
+		t.Fatal("border search error", hits)
+	}
+}
+
+func TestPadPlanes(t *testing.T) {
+	field := []*FloatImage{FloatImageFromRows([][]float64{{1, 2, 3}})}
+	for _, test := range []struct {
+		border BorderMode
+		want   []float64
+	}{
+		{BORDERMODE_NONE, []float64{1, 2, 3}},
+		{BORDERMODE_ZERO, []float64{0, 0, 1, 2, 3, 0, 0}},
+		{BORDERMODE_CLAMP, []float64{1, 1, 1, 2, 3, 3, 3}},
+		{BORDERMODE_MIRROR, []float64{3, 2, 1, 2, 3, 2, 1}},
+	} {
+		p := padPlanes(field, image.Rect(0, 0, 3, 1), test.border)
+		if !reflect.DeepEqual(p[0].Pix, test.want) {
+			t.Fatal("padding error", test.border, p[0].Pix)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package objsearch
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// How BuildTemplate combines example crops
+type BuildMode int
+
+const (
+	// Per-pixel, per-channel mean
+	BUILDMODE_MEAN BuildMode = iota
+	// Per-pixel, per-channel median
+	BUILDMODE_MEDIAN
+)
+
+// Builds a template from several example crops of the same object, which
+// must all have the same size. Returns the template, with bounds at the
+// origin, and a weight map suitable for Options.Mask, in which pixels that
+// vary least between crops have weight 255 and pixels that vary most have
+// weight 0. If every pixel varies equally, every weight is 255.
+func BuildTemplate(crops []image.Image, mode BuildMode) (template *image.RGBA, weights *image.Alpha) {
+	if len(crops) == 0 {
+		panic("no crops")
+	}
+	r := image.Rectangle{Max: crops[0].Bounds().Size()}
+	frames := make([]*image.RGBA, len(crops))
+	for i, c := range crops {
+		if c.Bounds().Size() != r.Max {
+			panic("crops have different sizes")
+		}
+		frames[i] = image.NewRGBA(r)
+		draw.Draw(frames[i], r, c, c.Bounds().Min, draw.Src)
+	}
+	switch mode {
+	case BUILDMODE_MEAN:
+		template = meanRGBA(frames)
+	case BUILDMODE_MEDIAN:
+		template = medianRGBA(frames)
+	default:
+		panic("unknown build mode")
+	}
+	// per-pixel standard deviation, averaged over color channels
+	mean := meanRGBA(frames)
+	sd := make([]float64, r.Dx()*r.Dy())
+	for i := range sd {
+		for c := 0; c < 3; c++ {
+			m := float64(mean.Pix[4*i+c])
+			v := 0.0
+			for _, f := range frames {
+				d := float64(f.Pix[4*i+c]) - m
+				v += d * d
+			}
+			sd[i] += math.Sqrt(v/float64(len(frames))) / 3
+		}
+	}
+	min, max := minMax(sd)
+	weights = image.NewAlpha(r)
+	for i := range sd {
+		weights.Pix[i] = 255
+		if max > min {
+			weights.Pix[i] = uint8(math.Round(255 * (max - sd[i]) / (max - min)))
+		}
+	}
+	return
+}
+
+// return the per-pixel, per-channel mean of frames, which must have equal
+// bounds at the origin
+func meanRGBA(frames []*image.RGBA) *image.RGBA {
+	r := image.NewRGBA(frames[0].Rect)
+	for i := range r.Pix {
+		sum := 0
+		for _, f := range frames {
+			sum += int(f.Pix[i])
+		}
+		r.Pix[i] = uint8((sum + len(frames)/2) / len(frames))
+	}
+	return r
+}
@@ -0,0 +1,35 @@
+package objsearch
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestBuildTemplate(t *testing.T) {
+	object := randomRGBImage(8, 8)
+	object.SetRGBA(5, 5, color.RGBA{100, 100, 100, 255})
+	var crops []image.Image
+	for i := 0; i < 5; i++ {
+		c := crop(object, object.Rect)
+		// pixel (2,3) varies between crops, (5,5) is occasionally corrupted
+		c.SetRGBA(2, 3, color.RGBA{uint8(50 * i), uint8(50 * i), uint8(50 * i), 255})
+		if i == 4 {
+			c.SetRGBA(5, 5, color.RGBA{140, 140, 140, 255})
+		}
+		// crops need not be at the origin
+		field := frameWithObject(randomRGBImage(20, 20), c, image.Point{i, 2 * i})
+		crops = append(crops, field.SubImage(image.Rect(i, 2*i, i+8, 2*i+8)))
+	}
+	median, weights := BuildTemplate(crops, BUILDMODE_MEDIAN)
+	if median.Rect != object.Rect || median.RGBAAt(5, 5) != object.RGBAAt(5, 5) {
+		t.Fatal("median template error")
+	}
+	if weights.AlphaAt(2, 3).A != 0 || weights.AlphaAt(0, 0).A != 255 || weights.AlphaAt(5, 5).A == 255 {
+		t.Fatal("weight map error")
+	}
+	mean, _ := BuildTemplate(crops, BUILDMODE_MEAN)
+	if mean.RGBAAt(2, 3) != (color.RGBA{100, 100, 100, 255}) || mean.RGBAAt(0, 0) != object.RGBAAt(0, 0) {
+		t.Fatal("mean template error")
+	}
+}
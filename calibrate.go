@@ -0,0 +1,102 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+	"sort"
+)
+
+// A field sample for CalibrateTolerance
+type NegativeSample struct {
+	Field image.Image
+	// Regions of Field that contain the object, or should otherwise not be
+	// treated as background. Positions at which the object's window overlaps
+	// any of them are skipped.
+	Exclude []image.Rectangle
+}
+
+// The result of CalibrateTolerance
+type Calibration struct {
+	// Tolerance expected to produce hits at the requested fraction of
+	// background positions
+	Tolerance float64
+	// Number of background positions scored, and the mean and standard
+	// deviation of their scores, as hits are scored according to
+	// Options.ScoreMode
+	N            int
+	Mean, StdDev float64
+}
+
+// Scores object at every background position of samples, as
+// SearchImage scores hits with opts, and returns the tolerance at which the
+// fraction fpr of the background positions would be hits. Scores are
+// normalized per field as SearchImage normalizes them; see
+// Result.HitScores.
+//
+// If fpr is too small to estimate from the N positions scored, that is,
+// fpr*N < 1, the tolerance is extrapolated by modeling the scores as
+// normally distributed.
+func CalibrateTolerance(object image.Image, samples []NegativeSample, fpr float64, opts Options) (c Calibration) {
+	o := NewObject(object)
+	var scores []float64
+	for _, s := range samples {
+		r := SearchResult(s.Field, o, image.Rectangle{}, opts)
+		if r == nil {
+			continue
+		}
+		hs := r.HitScores()
+		if hs == nil {
+			continue
+		}
+	nextPosition:
+		for i, v := range hs.Pix {
+			p := image.Point{hs.Rect.Min.X + i%hs.Stride, hs.Rect.Min.Y + i/hs.Stride}
+			window := o.Bounds().Add(p)
+			for _, e := range s.Exclude {
+				if window.Overlaps(e) {
+					continue nextPosition
+				}
+			}
+			scores = append(scores, v)
+		}
+	}
+	c.N = len(scores)
+	if c.N == 0 {
+		panic("no background positions to calibrate with")
+	}
+	c.Mean, c.StdDev = meanStdDev(scores)
+	// hits score above the tolerance for CCOEFF_NORMED, below it otherwise
+	higher := opts.ScoreMode == SCOREMODE_CCOEFF_NORMED
+	if higher {
+		sort.Sort(sort.Reverse(sort.Float64Slice(scores)))
+	} else {
+		sort.Float64s(scores)
+	}
+	k := int(fpr * float64(c.N))
+	switch {
+	case k >= c.N:
+		// every score is a hit
+		if higher {
+			c.Tolerance = math.Nextafter(scores[c.N-1], math.Inf(-1))
+		} else {
+			c.Tolerance = math.Nextafter(scores[c.N-1], math.Inf(1))
+		}
+	case k >= 1:
+		// the k best scores are hits
+		c.Tolerance = scores[k]
+	default:
+		// the normal quantile at fpr, from the better tail
+		z := math.Sqrt2 * math.Erfinv(2*fpr-1)
+		if higher {
+			z = -z
+		}
+		c.Tolerance = c.Mean + z*c.StdDev
+		// never looser than the best background score
+		if higher {
+			c.Tolerance = math.Max(c.Tolerance, scores[0])
+		} else {
+			c.Tolerance = math.Min(c.Tolerance, scores[0])
+		}
+	}
+	return
+}
@@ -0,0 +1,50 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestCalibrateTolerance(t *testing.T) {
+	object := randomRGBImage(8, 8)
+	p := image.Point{20, 10}
+	field := frameWithObject(randomRGBImage(80, 60), object, p)
+	samples := []NegativeSample{
+		{Field: field, Exclude: []image.Rectangle{object.Rect.Add(p)}},
+		{Field: randomRGBImage(80, 60)},
+	}
+	for _, mode := range []ScoreMode{SCOREMODE_L1, SCOREMODE_CCOEFF_NORMED} {
+		opts := Options{ScoreMode: mode}
+		c := CalibrateTolerance(object, samples, 0.05, opts)
+		if c.N != 2*73*53-15*15 || c.StdDev <= 0 {
+			t.Fatal("calibration error", mode, c)
+		}
+		// the background positions of the samples are hits at the requested
+		// rate
+		fp := 0
+		for i, sample := range samples {
+			for j, v := range SearchResult(sample.Field, object, image.Rectangle{}, opts).HitScores().Pix {
+				q := image.Point{j % 73, j / 73}
+				if i == 0 && object.Rect.Add(q).Overlaps(sample.Exclude[0]) {
+					continue
+				}
+				if mode == SCOREMODE_L1 && v < c.Tolerance || mode == SCOREMODE_CCOEFF_NORMED && v > c.Tolerance {
+					fp++
+				}
+			}
+		}
+		if rate := float64(fp) / float64(c.N); math.Abs(rate-0.05) > 0.001 {
+			t.Fatal("false positive rate error", mode, rate)
+		}
+		// the excluded object is still found
+		opts.Tolerance = c.Tolerance
+		if hits := SearchImage(field, object, validRect(field.Rect, object.Rect), opts); len(hits) == 0 || hits[0].P != p {
+			t.Fatal("object not found at calibrated tolerance", mode)
+		}
+		// a tiny rate is extrapolated, but never looser than the background
+		if e := CalibrateTolerance(object, samples, 1e-9, opts); mode == SCOREMODE_L1 && e.Tolerance >= c.Tolerance || mode == SCOREMODE_CCOEFF_NORMED && e.Tolerance <= c.Tolerance {
+			t.Fatal("extrapolation error", mode, e)
+		}
+	}
+}
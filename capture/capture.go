@@ -0,0 +1,82 @@
+// Package capture captures the screen as objsearch fields, so that images can
+// be found on screen with a few lines of code:
+//
+//	object, _ := objsearch.LoadObjectFile("button.png")
+//	hits, _ := capture.Find(object, objsearch.Options{Tolerance: 0.05})
+//
+// Fields are positioned in screen coordinates, so hits are screen
+// coordinates too. Capture is provided by github.com/kbinani/screenshot, and
+// works on Windows, macOS, and Linux/X11.
+package capture
+
+import (
+	"image"
+	"time"
+
+	"github.com/hypoactiv/objsearch"
+	"github.com/kbinani/screenshot"
+)
+
+// Returns the number of active displays
+func NumDisplays() int {
+	return screenshot.NumActiveDisplays()
+}
+
+// Returns the bounds of display i in screen coordinates
+func DisplayBounds(i int) image.Rectangle {
+	return screenshot.GetDisplayBounds(i)
+}
+
+// Captures r, in screen coordinates, as a Field with bounds r. To capture a
+// window, pass the window's bounds.
+func Rect(r image.Rectangle) (*objsearch.Field, error) {
+	img, err := screenshot.CaptureRect(r)
+	if err != nil {
+		return nil, err
+	}
+	// place the image in screen coordinates
+	img.Rect = img.Rect.Sub(img.Rect.Min).Add(r.Min)
+	return objsearch.NewField(img), nil
+}
+
+// Captures display i as a Field
+func Display(i int) (*objsearch.Field, error) {
+	return Rect(DisplayBounds(i))
+}
+
+// Captures the primary display and searches it for object. Hits are in screen
+// coordinates.
+func Find(object image.Image, opts objsearch.Options) ([]objsearch.Hit, error) {
+	field, err := Display(0)
+	if err != nil {
+		return nil, err
+	}
+	return objsearch.NewSearcher(object, opts).Search(field), nil
+}
+
+// A FrameSource capturing a rectangle of the screen, for use with
+// objsearch.Run and objsearch.WaitFor
+type Source struct {
+	// Rectangle to capture, in screen coordinates
+	Rect image.Rectangle
+	// Minimum time between captures
+	Interval time.Duration
+	last     time.Time
+}
+
+// Returns a Source capturing r at most once per interval
+func NewSource(r image.Rectangle, interval time.Duration) *Source {
+	return &Source{Rect: r, Interval: interval}
+}
+
+func (s *Source) Next() (image.Image, time.Time, error) {
+	if wait := s.Interval - time.Since(s.last); wait > 0 {
+		time.Sleep(wait)
+	}
+	s.last = time.Now()
+	f, err := Rect(s.Rect)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return f, s.last, nil
+}
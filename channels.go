@@ -0,0 +1,155 @@
+package objsearch
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Byte order of the channels of a 4-byte pixel
+type ChannelOrder int
+
+const (
+	CHANNELORDER_RGBA ChannelOrder = iota
+	// as produced by Windows and X11 screen captures
+	CHANNELORDER_BGRA
+	CHANNELORDER_ARGB
+	CHANNELORDER_ABGR
+	// the fourth byte is padding, and all pixels are opaque
+	CHANNELORDER_RGBX
+	CHANNELORDER_BGRX
+)
+
+// returns the byte offsets of the red, green, blue and alpha channels within
+// a pixel. a is negative if the pixel has no alpha channel.
+func (o ChannelOrder) offsets() (r, g, b, a int) {
+	switch o {
+	case CHANNELORDER_RGBA:
+		return 0, 1, 2, 3
+	case CHANNELORDER_BGRA:
+		return 2, 1, 0, 3
+	case CHANNELORDER_ARGB:
+		return 1, 2, 3, 0
+	case CHANNELORDER_ABGR:
+		return 3, 2, 1, 0
+	case CHANNELORDER_RGBX:
+		return 0, 1, 2, -1
+	case CHANNELORDER_BGRX:
+		return 2, 1, 0, -1
+	default:
+		panic("invalid channel order")
+	}
+}
+
+// An in-memory image with 4 bytes per pixel in a given channel order, and
+// non-premultiplied alpha. Use it to wrap pixel buffers, such as BGRA screen
+// captures, without reordering them.
+type OrderedRGBA struct {
+	// Pix holds the image's pixels. The pixel at (x,y) starts at
+	// Pix[(y-Rect.Min.Y)*Stride+(x-Rect.Min.X)*4]
+	Pix []uint8
+	// Stride is the Pix distance in bytes between vertically adjacent pixels
+	Stride int
+	Rect   image.Rectangle
+	Order  ChannelOrder
+}
+
+// Returns the index of the first byte of the pixel at (x,y) in p.Pix
+func (p *OrderedRGBA) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x-p.Rect.Min.X)*4
+}
+
+func (p *OrderedRGBA) NRGBAAt(x, y int) color.NRGBA {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return color.NRGBA{}
+	}
+	i := p.PixOffset(x, y)
+	r, g, b, a := p.Order.offsets()
+	c := color.NRGBA{p.Pix[i+r], p.Pix[i+g], p.Pix[i+b], 255}
+	if a >= 0 {
+		c.A = p.Pix[i+a]
+	}
+	return c
+}
+
+func (p *OrderedRGBA) Bounds() image.Rectangle {
+	return p.Rect
+}
+
+func (p *OrderedRGBA) ColorModel() color.Model {
+	return color.NRGBAModel
+}
+
+func (p *OrderedRGBA) At(x, y int) color.Color {
+	return p.NRGBAAt(x, y)
+}
+
+// return img as an *image.RGBA, converting only if necessary
+func toRGBA(img image.Image) *image.RGBA {
+	var nrgbaAt func(x, y int) color.NRGBA
+	switch img := img.(type) {
+	case *image.RGBA:
+		return img
+	case *OrderedRGBA:
+		nrgbaAt = img.NRGBAAt
+	case *OrderedRGB:
+		nrgbaAt = img.NRGBAAt
+	default:
+		r := image.NewRGBA(img.Bounds())
+		draw.Draw(r, r.Rect, img, r.Rect.Min, draw.Src)
+		return r
+	}
+	// convert without going through image.Image's generic interface
+	r := image.NewRGBA(img.Bounds())
+	for x := r.Rect.Min.X; x < r.Rect.Max.X; x++ {
+		for y := r.Rect.Min.Y; y < r.Rect.Max.Y; y++ {
+			c := nrgbaAt(x, y)
+			r.SetRGBA(x, y, color.RGBA{
+				uint8(uint32(c.R) * uint32(c.A) / 255),
+				uint8(uint32(c.G) * uint32(c.A) / 255),
+				uint8(uint32(c.B) * uint32(c.A) / 255),
+				c.A,
+			})
+		}
+	}
+	return r
+}
+
+// return an opaque copy of the colors of img, and the alpha channel of img as
+// a mask. If img is already opaque, it may be returned as is with a nil mask.
+func opaqueRGBA(img image.Image) (*image.RGBA, *image.Alpha) {
+	var nrgbaAt func(x, y int) color.NRGBA
+	switch img := img.(type) {
+	case *image.RGBA:
+		if img.Opaque() {
+			return img, nil
+		}
+		return unpremultiply(img), alphaMask(img)
+	case *image.NRGBA:
+		nrgbaAt = img.NRGBAAt
+	case *OrderedRGBA:
+		nrgbaAt = img.NRGBAAt
+	case *OrderedRGB:
+		return toRGBA(img), nil
+	default:
+		nrgbaAt = func(x, y int) color.NRGBA {
+			return color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+		}
+	}
+	b := img.Bounds()
+	r := image.NewRGBA(b)
+	m := image.NewAlpha(b)
+	opaque := true
+	for x := b.Min.X; x < b.Max.X; x++ {
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			c := nrgbaAt(x, y)
+			r.SetRGBA(x, y, color.RGBA{c.R, c.G, c.B, 255})
+			m.SetAlpha(x, y, color.Alpha{c.A})
+			opaque = opaque && c.A == 255
+		}
+	}
+	if opaque {
+		return r, nil
+	}
+	return r, m
+}
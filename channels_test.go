@@ -0,0 +1,73 @@
+package objsearch
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+// return img with its pixels reordered to BGRA
+func toBGRA(img *image.RGBA) *OrderedRGBA {
+	p := &OrderedRGBA{
+		Pix:    make([]uint8, len(img.Pix)),
+		Stride: img.Stride,
+		Rect:   img.Rect,
+		Order:  CHANNELORDER_BGRA,
+	}
+	for i := 0; i < len(img.Pix); i += 4 {
+		p.Pix[i+0] = img.Pix[i+2]
+		p.Pix[i+1] = img.Pix[i+1]
+		p.Pix[i+2] = img.Pix[i+0]
+		p.Pix[i+3] = img.Pix[i+3]
+	}
+	return p
+}
+
+// test searching a BGRA field for an NRGBA object
+func TestChannelOrder(t *testing.T) {
+	field := randomRGBImage(50, 50)
+	object := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	draw.Draw(object, object.Rect, field, image.Point{12, 34}, draw.Src)
+	bgra := toBGRA(field)
+	if bgra.NRGBAAt(5, 6) != color.NRGBAModel.Convert(field.At(5, 6)) {
+		t.Fatal("OrderedRGBA color error")
+	}
+	h := SearchImage(bgra, object, image.Rect(0, 0, 40, 40), Options{
+		Tolerance: 0.1,
+		MinDist:   10,
+		ColorMode: COLORMODE_RGB,
+	})
+	if len(h) == 0 || h[0] != (Hit{image.Point{12, 34}, 0}) {
+		t.Error(h)
+		t.Fatal("channel order search error")
+	}
+}
+
+// test searching raw framebuffers with padded rows
+func TestSearchRaw(t *testing.T) {
+	field := randomRGBImage(50, 50)
+	// pack field into a BGR24 buffer with 8 bytes of padding per row
+	stride := 50*3 + 8
+	buf := make([]byte, 50*stride)
+	for y := 0; y < 50; y++ {
+		for x := 0; x < 50; x++ {
+			c := field.RGBAAt(x, y)
+			copy(buf[y*stride+x*3:], []byte{c.B, c.G, c.R})
+		}
+	}
+	object := field.SubImage(image.Rect(20, 5, 30, 15)).(*image.RGBA)
+	h, err := SearchRaw(buf, 50, 50, stride, PIXELFORMAT_BGR24,
+		object.Pix, 10, 10, object.Stride, PIXELFORMAT_RGBA,
+		image.Rect(0, 0, 40, 40), Options{Tolerance: 0.1, MinDist: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h) == 0 || h[0] != (Hit{image.Point{20, 5}, 0}) {
+		t.Error(h)
+		t.Fatal("SearchRaw error")
+	}
+	if _, err := NewRawImage(buf, 50, 50, 100, PIXELFORMAT_BGR24); err == nil {
+		t.Fatal("expected stride error")
+	}
+}
@@ -0,0 +1,170 @@
+// Command objsearch searches a field image for one or more object images and
+// prints the hits found.
+//
+// Usage:
+//
+//	objsearch [flags] field template...
+//
+// Each hit is printed as a line "template x y score", where (x,y) is the
+// top-left corner of the object in the field. With -json, hits are printed as
+// a JSON array of {"label","x","y","score"} objects instead, where the label
+// is the template's path. With -o, an annotated copy of the field, with a
+// labeled box around each hit, is written as a PNG.
+//
+// With -batch, the arguments are instead a template and a directory or glob
+// pattern, and every image file it names is searched for the template:
+//
+//	objsearch -batch [flags] template dir|pattern
+//
+// Each hit is printed as a line "file x y score", files in order of their
+// best hit's score. Files that cannot be decoded are reported to stderr.
+// With -json, the results of every file are printed as a JSON array of
+// {"path","hits","error"} objects.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"os"
+
+	"github.com/hypoactiv/objsearch"
+)
+
+var (
+	tolerance = flag.Float64("tolerance", 0.1, "maximum hit score, in [0,1]")
+	minDist   = flag.Int("mindist", 10, "minimum distance in pixels between hits")
+	colorMode = flag.String("color", "gray", "color mode: gray or rgb")
+	combine   = flag.String("combine", "max", "channel combine mode: max or mean")
+	jsonOut   = flag.Bool("json", false, "print hits as JSON")
+	output    = flag.String("o", "", "write an annotated field image to this PNG file")
+	verbose   = flag.Bool("v", false, "verbose output to stderr")
+	batch     = flag.Bool("batch", false, "search every image file in a directory or glob for one template")
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("objsearch: ")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: objsearch [flags] field template...\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "       objsearch -batch [flags] template dir|pattern\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	opts := objsearch.Options{
+		Tolerance: *tolerance,
+		MinDist:   *minDist,
+	}
+	switch *colorMode {
+	case "gray":
+		opts.ColorMode = objsearch.COLORMODE_GRAY
+	case "rgb":
+		opts.ColorMode = objsearch.COLORMODE_RGB
+	default:
+		log.Fatalf("unknown color mode %q", *colorMode)
+	}
+	switch *combine {
+	case "max":
+		opts.CombineMode = objsearch.COMBINEMODE_MAX
+	case "mean":
+		opts.CombineMode = objsearch.COMBINEMODE_MEAN
+	default:
+		log.Fatalf("unknown combine mode %q", *combine)
+	}
+	if *verbose {
+		opts.VerboseOut = os.Stderr
+	}
+	if *batch {
+		if flag.NArg() != 2 || *output != "" {
+			flag.Usage()
+			os.Exit(2)
+		}
+		searchFiles(opts)
+		return
+	}
+	field, err := objsearch.LoadFieldFile(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	set := objsearch.NewTemplateSet(opts)
+	for _, path := range flag.Args()[1:] {
+		object, err := objsearch.LoadObjectFile(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		set.Add(path, object)
+	}
+	hits := set.SearchAll(field)
+	if *jsonOut {
+		if hits == nil {
+			hits = []objsearch.LabeledHit{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(hits); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		for _, h := range hits {
+			fmt.Printf("%s %d %d %f\n", h.Label, h.P.X, h.P.Y, h.S)
+		}
+	}
+	if *output != "" {
+		if err := writeAnnotated(*output, field, set, hits); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// search the files named by the second argument for the template named by
+// the first, and print the hits
+func searchFiles(opts objsearch.Options) {
+	object, err := objsearch.LoadObjectFile(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	results, err := objsearch.SearchFiles(flag.Arg(1), object, opts, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *jsonOut {
+		if results == nil {
+			results = []objsearch.FileHits{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			log.Printf("%s: %v", r.Path, r.Err)
+		}
+		for _, h := range r.Hits {
+			fmt.Printf("%s %d %d %f\n", r.Path, h.P.X, h.P.Y, h.S)
+		}
+	}
+}
+
+// write a copy of field to path as a PNG, with a labeled box around each hit
+func writeAnnotated(path string, field image.Image, set *objsearch.TemplateSet, hits []objsearch.LabeledHit) error {
+	dst := objsearch.DrawLabeledHits(field, hits, set, objsearch.HitStyle{Score: true})
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, dst); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,66 @@
+package objsearch
+
+// A temporal filter reporting hits only once they have appeared at about the
+// same location in K of the last N frames, suppressing one-frame false
+// positives caused by encoder noise and the like.
+type Consensus struct {
+	K, N int
+	// Hits at most Tol pixels apart, as measured by Hit.Distance, are
+	// considered to be at the same location
+	Tol int
+	// indexes of the hits of the last N frames, oldest first
+	history []*HitIndex
+	// all hits of the previous frame
+	prev *HitIndex
+	// streaks of the hits in prev, by location
+	streaks map[Hit]int
+}
+
+// A hit reported by Consensus
+type ConsensusHit struct {
+	Hit
+	// Number of the last N frames in which the hit appeared
+	Count int
+	// Number of consecutive frames, ending with the current one, in which
+	// the hit appeared
+	Streak int
+}
+
+// Returns a Consensus filter requiring hits to appear within tol pixels in k
+// of the last n frames
+func NewConsensus(k, n, tol int) *Consensus {
+	if k > n || n <= 0 {
+		panic("invalid consensus parameters")
+	}
+	return &Consensus{K: k, N: n, Tol: tol}
+}
+
+// Adds the hits found in the next frame, and returns those that have reached
+// consensus, in the order given
+func (c *Consensus) Update(hits []Hit) (r []ConsensusHit) {
+	cur := NewHitIndex(hits, c.Tol+1)
+	c.history = append(c.history, cur)
+	if len(c.history) > c.N {
+		c.history = c.history[len(c.history)-c.N:]
+	}
+	streaks := make(map[Hit]int, len(hits))
+	for _, h := range hits {
+		ch := ConsensusHit{Hit: h, Streak: 1}
+		for _, idx := range c.history {
+			if len(idx.HitsNear(h.P, c.Tol)) > 0 {
+				ch.Count++
+			}
+		}
+		if c.prev != nil {
+			if p, ok := c.prev.NearestHit(h.P); ok && p.Distance(h) <= c.Tol {
+				ch.Streak = c.streaks[p] + 1
+			}
+		}
+		streaks[h] = ch.Streak
+		if ch.Count >= c.K {
+			r = append(r, ch)
+		}
+	}
+	c.prev, c.streaks = cur, streaks
+	return
+}
@@ -0,0 +1,34 @@
+package objsearch
+
+import (
+	"image"
+	"testing"
+)
+
+func TestConsensus(t *testing.T) {
+	c := NewConsensus(2, 3, 1)
+	stable := Hit{image.Point{10, 10}, 0.1}
+	frames := [][]Hit{
+		{stable},
+		{{image.Point{11, 10}, 0.1}, {image.Point{50, 50}, 0}}, // jitter, flicker
+		{stable},
+		{},
+		{{image.Point{50, 50}, 0}},
+	}
+	want := []struct{ n, count, streak int }{
+		{0, 0, 0},
+		{1, 2, 2},
+		{1, 3, 3},
+		{0, 0, 0},
+		{0, 0, 0},
+	}
+	for i, hits := range frames {
+		r := c.Update(hits)
+		if len(r) != want[i].n {
+			t.Fatal("consensus error", i, r)
+		}
+		if len(r) > 0 && (r[0].Count != want[i].count || r[0].Streak != want[i].streak) {
+			t.Fatal("consensus count error", i, r)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+)
+
+// return the standard deviation of the field window under an object with
+// bounds object at each point of rect, averaged over the field planes
+func localContrast(field []*FloatImage, object, rect image.Rectangle) []float64 {
+	contrast := make([]float64, rect.Dx()*rect.Dy())
+	n := float64(object.Dx() * object.Dy())
+	for _, f := range field {
+		sum, sum2 := integralImages(f)
+		w := f.Rect.Dx() + 1
+		// return the sum of table t over window r, in field coordinates
+		area := func(t []float64, r image.Rectangle) float64 {
+			r = r.Sub(f.Rect.Min)
+			return t[r.Max.Y*w+r.Max.X] - t[r.Min.Y*w+r.Max.X] - t[r.Max.Y*w+r.Min.X] + t[r.Min.Y*w+r.Min.X]
+		}
+		i := 0
+		for v := rect.Min.Y; v < rect.Max.Y; v++ {
+			for u := rect.Min.X; u < rect.Max.X; u++ {
+				win := object.Add(image.Point{u, v})
+				mean := area(sum, win) / n
+				variance := area(sum2, win)/n - mean*mean
+				contrast[i] += math.Sqrt(math.Max(variance, 0)) / float64(len(field))
+				i++
+			}
+		}
+	}
+	return contrast
+}
+
+// return the summed-area tables of the values of f and of their squares.
+// Entry (x,y) of a table, at index y*(width+1)+x, is the sum over the pixels
+// above and left of (x,y), relative to f.Rect.Min.
+func integralImages(f *FloatImage) (sum, sum2 []float64) {
+	w, h := f.Rect.Dx(), f.Rect.Dy()
+	sum = make([]float64, (w+1)*(h+1))
+	sum2 = make([]float64, (w+1)*(h+1))
+	for y := 0; y < h; y++ {
+		row, row2 := 0.0, 0.0
+		for x := 0; x < w; x++ {
+			v := f.Pix[f.PixOffset(f.Rect.Min.X+x, f.Rect.Min.Y+y)]
+			row += v
+			row2 += v * v
+			i := (y+1)*(w+1) + x + 1
+			sum[i] = sum[i-w-1] + row
+			sum2[i] = sum2[i-w-1] + row2
+		}
+	}
+	return
+}
+
+// return a function giving the tolerance at each index of the scores. If
+// ctx.Contrast is set and hits are scored by distance, the tolerance is
+// scaled by the local contrast relative to the mean local contrast.
+func (ctx objSearchContext) tolerance() func(i int) float64 {
+	if ctx.Contrast == nil || !ctx.distanceScores() {
+		return func(int) float64 { return ctx.Tolerance }
+	}
+	mean, _ := meanStdDev(ctx.Contrast)
+	if mean <= 0 {
+		return func(int) float64 { return ctx.Tolerance }
+	}
+	return func(i int) float64 {
+		return ctx.Tolerance * ctx.Contrast[i] / mean
+	}
+}
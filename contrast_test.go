@@ -0,0 +1,70 @@
+package objsearch
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+)
+
+func TestLocalContrast(t *testing.T) {
+	f := randomFloatImage(20, 15, 1)
+	f.Rect = f.Rect.Add(image.Point{3, 4})
+	object := image.Rect(1, 1, 5, 4)
+	rect := validRect(f.Rect, object)
+	c := localContrast([]*FloatImage{f, f}, object, rect)
+	for i, p := range []image.Point{rect.Min, {7, 9}, rect.Max.Sub(image.Point{1, 1})} {
+		var vals []float64
+		win := object.Add(p)
+		for y := win.Min.Y; y < win.Max.Y; y++ {
+			for x := win.Min.X; x < win.Max.X; x++ {
+				vals = append(vals, f.FloatAt(x, y))
+			}
+		}
+		_, sd := meanStdDev(vals)
+		got := c[(p.Y-rect.Min.Y)*rect.Dx()+p.X-rect.Min.X]
+		if math.Abs(got-sd) > 1e-9 {
+			t.Fatal("local contrast error", i, got, sd)
+		}
+	}
+}
+
+func TestAdaptiveTolerance(t *testing.T) {
+	// a low-contrast object, on a field that is half flat and half busy
+	object := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for i := range object.Pix {
+		object.Pix[i] = 120 + uint8(i%7)
+		if i%4 == 3 {
+			object.Pix[i] = 255
+		}
+	}
+	field := randomRGBImage(80, 40)
+	draw.Draw(field, image.Rect(0, 0, 40, 40), image.NewUniform(color.Gray{123}), image.Point{}, draw.Src)
+	rect := validRect(field.Rect, object.Rect)
+	opts := Options{Tolerance: 0.1, MinDist: 8}
+	// the flat half is all nearly as close as a match
+	if hits := SearchImage(field, object, rect, opts); len(hits) < 5 {
+		t.Fatal("expected false positives on the flat half", len(hits))
+	}
+	opts.AdaptiveTolerance = true
+	if hits := SearchImage(field, object, rect, opts); len(hits) != 0 {
+		t.Fatal("adaptive tolerance should reject flat matches", hits)
+	}
+	// but still finds exact matches
+	exact := frameWithObject(field, object, image.Point{55, 20})
+	if hits := SearchImage(exact, object, rect, opts); len(hits) != 1 || hits[0].P != (image.Point{55, 20}) {
+		t.Fatal("adaptive tolerance should accept exact matches", hits)
+	}
+	// the result keeps the contrast, and re-thresholds adaptively
+	r := SearchResult(field, object, rect, opts)
+	if r.Contrast == nil || len(r.Rethreshold(0.1, 8)) != 0 {
+		t.Fatal("result contrast error")
+	}
+	// progress reporting searches in bands with the same result
+	s := NewSearcher(object, opts)
+	s.Rect = rect
+	if hits := s.SearchWithProgress(field, func(float64) {}); len(hits) != 0 {
+		t.Fatal("banded adaptive search error", hits)
+	}
+}
@@ -0,0 +1,124 @@
+// Command cshared builds objsearch as a C shared library, for use from other
+// languages, in particular by the Python bindings in python/objsearch.py.
+// Build it with
+//
+//	go build -buildmode=c-shared -o python/libobjsearch.so ./cshared
+//
+// The library exports:
+//
+//	typedef struct { int32_t x, y; double score; } objsearch_hit;
+//
+//	int objsearch_search(
+//		const uint8_t *field, int field_w, int field_h, int field_stride, int field_format,
+//		const uint8_t *object, int object_w, int object_h, int object_stride, int object_format,
+//		double tolerance, int min_dist, int color_mode, int combine_mode,
+//		objsearch_hit *hits, int max_hits);
+//
+//	const char *objsearch_last_error(void);
+//
+// Formats are objsearch.PixelFormat values, and modes objsearch.ColorMode and
+// objsearch.CombineMode values. objsearch_search writes at most max_hits hits
+// and returns the number found, which may be larger, or -1 on error, in which
+// case objsearch_last_error describes the error.
+package main
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+
+typedef struct { int32_t x, y; double score; } objsearch_hit;
+*/
+import "C"
+
+import (
+	"fmt"
+	"image"
+	"sync"
+	"unsafe"
+
+	"github.com/hypoactiv/objsearch"
+)
+
+var (
+	errMu     sync.Mutex
+	lastError *C.char
+)
+
+// record err for objsearch_last_error
+func setError(err error) {
+	errMu.Lock()
+	defer errMu.Unlock()
+	if lastError != nil {
+		C.free(unsafe.Pointer(lastError))
+	}
+	lastError = C.CString(err.Error())
+}
+
+//export objsearch_last_error
+func objsearch_last_error() *C.char {
+	errMu.Lock()
+	defer errMu.Unlock()
+	return lastError
+}
+
+//export objsearch_search
+func objsearch_search(
+	field *C.uint8_t, fieldW, fieldH, fieldStride, fieldFormat C.int,
+	object *C.uint8_t, objectW, objectH, objectStride, objectFormat C.int,
+	tolerance C.double, minDist, colorMode, combineMode C.int,
+	hits *C.objsearch_hit, maxHits C.int) C.int {
+	found, err := search(
+		buffer(field, fieldH, fieldStride), int(fieldW), int(fieldH), int(fieldStride), objsearch.PixelFormat(fieldFormat),
+		buffer(object, objectH, objectStride), int(objectW), int(objectH), int(objectStride), objsearch.PixelFormat(objectFormat),
+		objsearch.Options{
+			Tolerance:   float64(tolerance),
+			MinDist:     int(minDist),
+			ColorMode:   objsearch.ColorMode(colorMode),
+			CombineMode: objsearch.CombineMode(combineMode),
+		})
+	if err != nil {
+		setError(err)
+		return -1
+	}
+	if maxHits > 0 {
+		out := unsafe.Slice(hits, int(maxHits))
+		for i := 0; i < len(found) && i < len(out); i++ {
+			out[i] = C.objsearch_hit{
+				x:     C.int32_t(found[i].P.X),
+				y:     C.int32_t(found[i].P.Y),
+				score: C.double(found[i].S),
+			}
+		}
+	}
+	return C.int(len(found))
+}
+
+// return the h rows of stride bytes at p as a slice, without copying
+func buffer(p *C.uint8_t, h, stride C.int) []byte {
+	if p == nil || h <= 0 || stride <= 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(p)), int(h)*int(stride))
+}
+
+// search field for object, converting panics from invalid arguments to
+// errors
+func search(field []byte, fw, fh, fstride int, fformat objsearch.PixelFormat, object []byte, ow, oh, ostride int, oformat objsearch.PixelFormat, opts objsearch.Options) (hits []objsearch.Hit, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	for _, f := range []objsearch.PixelFormat{fformat, oformat} {
+		if f < objsearch.PIXELFORMAT_RGBA || f > objsearch.PIXELFORMAT_GRAY8 {
+			return nil, fmt.Errorf("invalid pixel format %d", f)
+		}
+	}
+	rect := image.Rectangle{Max: image.Point{fw - ow + 1, fh - oh + 1}}
+	if ow <= 0 || oh <= 0 || rect.Empty() {
+		return nil, fmt.Errorf("object of size %dx%d does not fit in field of size %dx%d", ow, oh, fw, fh)
+	}
+	return objsearch.SearchRaw(field, fw, fh, fstride, fformat, object, ow, oh, ostride, oformat, rect, opts)
+}
+
+func main() {}
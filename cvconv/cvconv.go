@@ -0,0 +1,107 @@
+//go:build gocv
+
+// Package cvconv converts between gocv.Mat and the images accepted by
+// objsearch, so that pipelines that capture or preprocess frames with OpenCV
+// can search them without encoding round trips.
+//
+// The package depends on gocv.io/x/gocv, and so on OpenCV, and is built only
+// with the gocv build tag.
+package cvconv
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/hypoactiv/objsearch"
+	"gocv.io/x/gocv"
+)
+
+// Returns m, an 8-bit grayscale, BGR or BGRA Mat, as an image. If m is
+// continuous, the image shares m's memory, and m must not be closed or
+// modified while the image is in use. Otherwise m's data is copied.
+//
+// The image may be passed to objsearch.SearchImage as a field or object.
+func MatToImage(m gocv.Mat) (image.Image, error) {
+	var format objsearch.PixelFormat
+	switch m.Type() {
+	case gocv.MatTypeCV8UC1:
+		format = objsearch.PIXELFORMAT_GRAY8
+	case gocv.MatTypeCV8UC3:
+		format = objsearch.PIXELFORMAT_BGR24
+	case gocv.MatTypeCV8UC4:
+		format = objsearch.PIXELFORMAT_BGRA
+	default:
+		return nil, fmt.Errorf("cvconv: unsupported Mat type %v", m.Type())
+	}
+	var pix []byte
+	stride := m.Cols() * format.BytesPerPixel()
+	if m.IsContinuous() {
+		var err error
+		if pix, err = m.DataPtrUint8(); err != nil {
+			return nil, err
+		}
+	} else {
+		pix = m.ToBytes()
+	}
+	return objsearch.NewRawImage(pix, m.Cols(), m.Rows(), stride, format)
+}
+
+// Returns m as an objsearch.Field. The field does not share m's memory.
+func MatToField(m gocv.Mat) (*objsearch.Field, error) {
+	img, err := MatToImage(m)
+	if err != nil {
+		return nil, err
+	}
+	return objsearch.NewField(img), nil
+}
+
+// Returns m as an objsearch.Object. The object does not share m's memory.
+func MatToObject(m gocv.Mat) (*objsearch.Object, error) {
+	img, err := MatToImage(m)
+	if err != nil {
+		return nil, err
+	}
+	// NewObject would keep a reference to img
+	return objsearch.NewObject(copyImage(img)), nil
+}
+
+// Returns img as a Mat, which the caller must close. An *objsearch.OrderedRGB
+// in BGR order or *objsearch.OrderedRGBA in BGRA order, without row padding,
+// shares its memory with the Mat. Other images are copied, as BGR if opaque
+// and BGRA otherwise. *image.Gray images are copied as grayscale.
+func ImageToMat(img image.Image) (gocv.Mat, error) {
+	b := img.Bounds()
+	switch img := img.(type) {
+	case *objsearch.OrderedRGB:
+		if img.Order == objsearch.CHANNELORDER_BGRX && img.Stride == 3*b.Dx() {
+			return gocv.NewMatFromBytes(b.Dy(), b.Dx(), gocv.MatTypeCV8UC3, img.Pix)
+		}
+	case *objsearch.OrderedRGBA:
+		if img.Order == objsearch.CHANNELORDER_BGRA && img.Stride == 4*b.Dx() {
+			return gocv.NewMatFromBytes(b.Dy(), b.Dx(), gocv.MatTypeCV8UC4, img.Pix)
+		}
+	case *image.Gray:
+		pix := make([]byte, 0, b.Dx()*b.Dy())
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			o := img.PixOffset(b.Min.X, y)
+			pix = append(pix, img.Pix[o:o+b.Dx()]...)
+		}
+		return gocv.NewMatFromBytes(b.Dy(), b.Dx(), gocv.MatTypeCV8UC1, pix)
+	}
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && opaque.Opaque() {
+		return gocv.ImageToMatRGB(img)
+	}
+	return gocv.ImageToMatRGBA(img)
+}
+
+// return an *image.NRGBA copy of img
+func copyImage(img image.Image) image.Image {
+	b := img.Bounds()
+	c := image.NewNRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c.Set(x, y, img.At(x, y))
+		}
+	}
+	return c
+}
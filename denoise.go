@@ -0,0 +1,48 @@
+package objsearch
+
+import (
+	"image"
+	"sort"
+)
+
+// return the median filters of planes over (2r+1)x(2r+1) neighbourhoods,
+// over the part of each plane within region. Neighbourhoods are clipped to
+// the plane's bounds.
+func medianPlanes(planes []*FloatImage, r int, region image.Rectangle) []*FloatImage {
+	if r < 1 {
+		panic("denoise radius less than 1")
+	}
+	filtered := make([]*FloatImage, len(planes))
+	window := make([]float64, 0, (2*r+1)*(2*r+1))
+	for i, p := range planes {
+		q := NewFloatImage(region.Intersect(p.Rect))
+		for y := q.Rect.Min.Y; y < q.Rect.Max.Y; y++ {
+			for x := q.Rect.Min.X; x < q.Rect.Max.X; x++ {
+				n := image.Rect(x-r, y-r, x+r+1, y+r+1).Intersect(p.Rect)
+				window = window[:0]
+				for ny := n.Min.Y; ny < n.Max.Y; ny++ {
+					for nx := n.Min.X; nx < n.Max.X; nx++ {
+						window = append(window, p.Pix[p.PixOffset(nx, ny)])
+					}
+				}
+				sort.Float64s(window)
+				q.Pix[q.PixOffset(x, y)] = window[len(window)/2]
+			}
+		}
+		filtered[i] = q
+	}
+	return filtered
+}
+
+// return field median filtered with radius r over the part searched for
+// object at each point of rect, and object filtered too if filterObject is
+// set
+func denoiseInputs(field, object []*FloatImage, rect image.Rectangle, r int, filterObject bool) ([]*FloatImage, []*FloatImage) {
+	o := object[0].Rect
+	searched := image.Rectangle{rect.Min.Add(o.Min), rect.Max.Add(o.Max).Sub(image.Point{1, 1})}
+	field = medianPlanes(field, r, searched)
+	if filterObject {
+		object = medianPlanes(object, r, o)
+	}
+	return field, object
+}
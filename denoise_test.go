@@ -0,0 +1,48 @@
+package objsearch
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+)
+
+// test that an object is found in a field with impulse noise with
+// Options.Denoise, rather than a brighter copy
+func TestDenoise(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	// 4x4 blocks of random values
+	blocks := func(w, h int) *FloatImage {
+		img := NewFloatImage(image.Rect(0, 0, w, h))
+		for y := 0; y < h; y += 4 {
+			for x := 0; x < w; x += 4 {
+				v := rnd.Float64()
+				for i := 0; i < 16; i++ {
+					img.Pix[img.PixOffset(x+i%4, y+i/4)] = v
+				}
+			}
+		}
+		return img
+	}
+	object := blocks(16, 16)
+	field := blocks(64, 64)
+	p, decoy := image.Point{20, 36}, image.Point{40, 8}
+	pasteFloat(field, object, p, 0)
+	pasteFloat(field, object, decoy, 0.04)
+	// impulse noise over the object
+	for y := p.Y; y < p.Y+16; y++ {
+		for x := p.X; x < p.X+16; x++ {
+			if rnd.Float64() < 0.15 {
+				field.Pix[field.PixOffset(x, y)] = float64(rnd.Intn(2))
+			}
+		}
+	}
+	testOptionFinds(t, field, object, p, decoy, 0.03, func(opts *Options) {
+		opts.Denoise, opts.DenoiseObject = 1, true
+	})
+	// the median of a constant plane with one outlier is the constant
+	c := NewFloatImage(image.Rect(0, 0, 5, 5))
+	c.Pix[c.PixOffset(2, 2)] = 1
+	if m := medianPlanes([]*FloatImage{c}, 1, image.Rect(1, 1, 4, 4))[0]; m.Rect != image.Rect(1, 1, 4, 4) || m.FloatAt(2, 2) != 0 {
+		t.Fatal("median error", m.Rect, m.Pix)
+	}
+}
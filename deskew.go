@@ -0,0 +1,116 @@
+package objsearch
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hypoactiv/imutil"
+)
+
+// Estimates the rotation of a scanned document, in degrees counterclockwise
+// and at most maxAngle either way, from the projection profiles of its dark
+// pixels: text lines and rules project to sharp peaks when viewed at the
+// document's rotation. Rotating field by the negated result, as Deskew
+// does, levels it.
+func EstimateSkew(field image.Image, maxAngle float64) float64 {
+	gray := imutil.ToGrayscale(toRGBA(field))
+	b := gray.Rect
+	// dark pixels, relative to the center, and their darkness
+	type ink struct{ x, y, w float64 }
+	var dark []ink
+	cx, cy := float64(b.Min.X+b.Max.X)/2, float64(b.Min.Y+b.Max.Y)/2
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if v := gray.GrayAt(x, y).Y; v < 128 {
+				dark = append(dark, ink{float64(x) + 0.5 - cx, float64(y) + 0.5 - cy, float64(255-v) / 255})
+			}
+		}
+	}
+	if len(dark) == 0 {
+		return 0
+	}
+	offset := math.Hypot(float64(b.Dx()), float64(b.Dy()))
+	profile := make([]float64, int(2*offset)+2)
+	// sharpness of the profile of rows at angle a
+	sharpness := func(a float64) (s float64) {
+		for i := range profile {
+			profile[i] = 0
+		}
+		sin, cos := math.Sincos(a * math.Pi / 180)
+		for _, p := range dark {
+			profile[int(p.x*sin+p.y*cos+offset)] += p.w
+		}
+		for _, v := range profile {
+			s += v * v
+		}
+		return
+	}
+	// coarse to fine. Nearby angles bin pixels alike, so the sharpest angle
+	// is taken as the middle of the run of angles sharpest at each step.
+	best := 0.0
+	for _, step := range []float64{0.5, 0.1, 0.02} {
+		lo, hi := math.Max(-maxAngle, best-5*step), math.Min(maxAngle, best+5*step)
+		if step == 0.5 {
+			lo, hi = -maxAngle, maxAngle
+		}
+		n := int(math.Round((hi - lo) / step))
+		first, last, bestS := 0, 0, math.Inf(-1)
+		for i := 0; i <= n; i++ {
+			switch s := sharpness(lo + float64(i)*step); {
+			case s > bestS:
+				first, last, bestS = i, i, s
+			case s == bestS && last == i-1:
+				last = i
+			}
+		}
+		best = lo + float64(first+last)/2*step
+	}
+	return best
+}
+
+// Returns img rotated counterclockwise by angle degrees about its center,
+// with the same bounds. Pixels are interpolated bilinearly, and those
+// rotated in from outside img are bg.
+func Rotate(img image.Image, angle float64, bg color.Color) *image.RGBA {
+	src := toRGBA(img)
+	b := src.Rect
+	dst := image.NewRGBA(b)
+	fill := color.RGBAModel.Convert(bg).(color.RGBA)
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	cx, cy := float64(b.Min.X+b.Max.X)/2, float64(b.Min.Y+b.Max.Y)/2
+	// the pixel at (x,y), or fill outside src
+	at := func(x, y int) [4]float64 {
+		c := fill
+		if (image.Point{x, y}).In(b) {
+			c = src.RGBAAt(x, y)
+		}
+		return [4]float64{float64(c.R), float64(c.G), float64(c.B), float64(c.A)}
+	}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			dx, dy := float64(x)+0.5-cx, float64(y)+0.5-cy
+			sx := cx + dx*cos - dy*sin - 0.5
+			sy := cy + dx*sin + dy*cos - 0.5
+			x0, y0 := int(math.Floor(sx)), int(math.Floor(sy))
+			fx, fy := sx-float64(x0), sy-float64(y0)
+			p00, p10, p01, p11 := at(x0, y0), at(x0+1, y0), at(x0, y0+1), at(x0+1, y0+1)
+			var v [4]uint8
+			for i := range v {
+				top := p00[i]*(1-fx) + p10[i]*fx
+				bottom := p01[i]*(1-fx) + p11[i]*fx
+				v[i] = uint8(math.Round(top*(1-fy) + bottom*fy))
+			}
+			dst.SetRGBA(x, y, color.RGBA{v[0], v[1], v[2], v[3]})
+		}
+	}
+	return dst
+}
+
+// Returns field rotated to undo its skew as estimated by EstimateSkew, with
+// white filling in at the corners, and the estimated skew. Templates can
+// then be matched against the result without searching over rotations.
+func Deskew(field image.Image, maxAngle float64) (*image.RGBA, float64) {
+	angle := EstimateSkew(field, maxAngle)
+	return Rotate(field, -angle, color.White), angle
+}
@@ -0,0 +1,39 @@
+package objsearch
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// test that the skew of a page of text lines is estimated and undone
+func TestDeskew(t *testing.T) {
+	page := image.NewRGBA(image.Rect(0, 0, 240, 180))
+	draw.Draw(page, page.Rect, image.NewUniform(color.White), image.Point{}, draw.Src)
+	// lines of words
+	for y := 20; y < 160; y += 12 {
+		for x := 20; x < 200; {
+			w := 8 + rand.Intn(20)
+			draw.Draw(page, image.Rect(x, y, x+w, y+5), image.NewUniform(color.Black), image.Point{}, draw.Src)
+			x += w + 6
+		}
+	}
+	for _, angle := range []float64{3, -1.5} {
+		skewed := Rotate(page, angle, color.White)
+		if a := EstimateSkew(skewed, 5); math.Abs(a-angle) > 0.2 {
+			t.Fatal("skew error", angle, a)
+		}
+		level, a := Deskew(skewed, 5)
+		if math.Abs(EstimateSkew(level, 5)) > 0.2 {
+			t.Fatal("deskew error", angle, a)
+		}
+	}
+	blank := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	draw.Draw(blank, blank.Rect, image.NewUniform(color.White), image.Point{}, draw.Src)
+	if EstimateSkew(blank, 5) != 0 {
+		t.Fatal("blank page skewed")
+	}
+}
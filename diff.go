@@ -0,0 +1,132 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+)
+
+// An 8-connected region of changed pixels
+type Region struct {
+	// Bounding rectangle of the region
+	Bounds image.Rectangle
+	// Number of changed pixels in the region
+	Area int
+}
+
+// Returns the regions of pixels at which frames a and b differ by more than
+// threshold, in raster order of their first pixel. a and b must have equal
+// bounds.
+//
+// Pixels are compared as the Search functions compare object and field
+// pixels: the planes selected by opts.ColorMode are compared, and their
+// differences, in [0,1], are weighted by opts.ChannelWeights and combined
+// according to opts.CombineMode. Differences are squared with
+// SCOREMODE_SQDIFF_NORMED, and absolute with the L1 score modes. Diff panics
+// with SCOREMODE_CCOEFF_NORMED, which does not compare single pixels.
+//
+// opts.Mask, if not nil, weights the difference at each pixel, in frame
+// coordinates, and pixels of color opts.ColorKey in either frame are
+// ignored. Other options are ignored.
+func Diff(a, b image.Image, threshold float64, opts Options) []Region {
+	if a.Bounds() != b.Bounds() {
+		panic("frames have different bounds")
+	}
+	if opts.ScoreMode == SCOREMODE_CCOEFF_NORMED {
+		panic("frames cannot be compared pixel by pixel with SCOREMODE_CCOEFF_NORMED")
+	}
+	ra, rb := toRGBA(a), toRGBA(b)
+	r := ra.Rect
+	ctx := newContext(r, opts)
+	mask := combineMasks(toMask(opts.Mask, r), colorKeyMask(ra, opts.ColorKey))
+	mask = combineMasks(mask, colorKeyMask(rb, opts.ColorKey))
+	pa, pb := colorPlanes(ra, opts.ColorMode), colorPlanes(rb, opts.ColorMode)
+	results := make([]objSearchResult, len(pa))
+	for i := range pa {
+		d := make([]float64, r.Dx()*r.Dy())
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				v := pa[i].FloatAt(x, y) - pb[i].FloatAt(x, y)
+				if opts.ScoreMode == SCOREMODE_SQDIFF_NORMED {
+					v *= v
+				} else {
+					v = math.Abs(v)
+				}
+				if mask != nil {
+					v *= float64(mask.AlphaAt(x, y).A) / 255
+				}
+				d[ctx.offset(x, y)] = v
+			}
+		}
+		results[i].distances = d
+	}
+	d := ctx.combine(results)
+	changed := make([]bool, len(d))
+	for i := range d {
+		changed[i] = d[i] > threshold
+	}
+	return changedRegions(changed, r)
+}
+
+// Returns the bounding rectangles of regions
+func RegionBounds(regions []Region) []image.Rectangle {
+	r := make([]image.Rectangle, len(regions))
+	for i := range regions {
+		r[i] = regions[i].Bounds
+	}
+	return r
+}
+
+// return the 8-connected regions of the pixels of r marked in changed, in
+// raster order
+func changedRegions(changed []bool, r image.Rectangle) (regions []Region) {
+	w, h := r.Dx(), r.Dy()
+	// label connected regions by flood fill
+	stack := []int{}
+	for start := range changed {
+		if !changed[start] {
+			continue
+		}
+		changed[start] = false
+		bounds := image.Rect(start%w, start/w, start%w+1, start/w+1)
+		area := 0
+		stack = append(stack[:0], start)
+		for len(stack) > 0 {
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			x, y := i%w, i/w
+			bounds = bounds.Union(image.Rect(x, y, x+1, y+1))
+			area++
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := x+dx, y+dy
+					if nx < 0 || ny < 0 || nx >= w || ny >= h || !changed[ny*w+nx] {
+						continue
+					}
+					changed[ny*w+nx] = false
+					stack = append(stack, ny*w+nx)
+				}
+			}
+		}
+		regions = append(regions, Region{bounds.Add(r.Min), area})
+	}
+	return
+}
+
+// return rects with overlapping rectangles replaced by their union
+func mergeRects(rects []image.Rectangle) []image.Rectangle {
+	merged := append([]image.Rectangle{}, rects...)
+	for changed := true; changed; {
+		changed = false
+		for i := 0; i < len(merged); i++ {
+			for j := i + 1; j < len(merged); j++ {
+				if merged[i].Overlaps(merged[j]) {
+					merged[i] = merged[i].Union(merged[j])
+					merged = append(merged[:j], merged[j+1:]...)
+					changed = true
+					j--
+				}
+			}
+		}
+	}
+	return merged
+}
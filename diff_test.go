@@ -0,0 +1,60 @@
+package objsearch
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a := randomRGBImage(50, 50)
+	b := frameWithObject(a, randomRGBImage(5, 4), image.Point{10, 20})
+	// a red diagonal line, connected only at corners
+	for i := 0; i < 6; i++ {
+		c := a.RGBAAt(30+i, 5+i)
+		c.R ^= 0x80
+		b.SetRGBA(30+i, 5+i, c)
+	}
+	opts := Options{ColorMode: COLORMODE_RGB}
+	regions := Diff(a, b, 8.5/255, opts)
+	if len(regions) != 2 {
+		t.Fatal("Diff region count error", regions)
+	}
+	if regions[0] != (Region{image.Rect(30, 5, 36, 11), 6}) {
+		t.Fatal("Diff line region error", regions[0])
+	}
+	// random object pixels may coincide with the background
+	if !regions[1].Bounds.In(image.Rect(10, 20, 15, 24)) || regions[1].Area > 20 {
+		t.Fatal("Diff object region error", regions[1])
+	}
+	if len(Diff(a, a, 0, opts)) != 0 {
+		t.Fatal("Diff of identical frames error")
+	}
+	// squared differences
+	sq := opts
+	sq.ScoreMode = SCOREMODE_SQDIFF_NORMED
+	if r := Diff(a, b, (8.5/255)*(8.5/255), sq); len(r) != 2 || r[0] != regions[0] || r[1] != regions[1] {
+		t.Fatal("Diff squared difference error", r)
+	}
+	// the line changes only the red channel, which is given no weight
+	weighted := opts
+	weighted.ChannelWeights = []float64{0, 1, 1}
+	if r := Diff(a, b, 8.5/255, weighted); len(r) != 1 || !r[0].Bounds.In(image.Rect(10, 20, 15, 24)) {
+		t.Fatal("Diff channel weights error", r)
+	}
+	// the mask excludes the object
+	masked := opts
+	mask := image.NewAlpha(a.Rect)
+	for i := range mask.Pix {
+		mask.Pix[i] = 255
+	}
+	for y := 20; y < 24; y++ {
+		for x := 10; x < 15; x++ {
+			mask.SetAlpha(x, y, color.Alpha{0})
+		}
+	}
+	masked.Mask = mask
+	if r := Diff(a, b, 8.5/255, masked); len(r) != 1 || r[0] != regions[0] {
+		t.Fatal("Diff mask error", r)
+	}
+}
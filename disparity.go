@@ -0,0 +1,71 @@
+package objsearch
+
+import "image"
+
+// Parameters of FindDisparities
+type DisparityOptions struct {
+	// Options of the match of each patch. Tolerance, MinDist, ScoreMode and
+	// HitMode are ignored.
+	Options
+	// Size of the patches of the left image matched
+	Patch image.Point
+	// Distance between the top-left corners of adjacent patches. Zero is
+	// treated as the patch size, so that patches tile the left image.
+	Step int
+	// Range of disparities searched, inclusive. The patch at x in the left
+	// image is matched at x-d in the right image for each disparity d.
+	MinDisparity, MaxDisparity int
+}
+
+// The disparity of a patch of the left image of a stereo pair, as found by
+// FindDisparities
+type PatchDisparity struct {
+	// Top-left corner of the patch in the left image
+	P image.Point
+	// Disparity of the patch: it matches best at P.X-D in the right image
+	D int
+	// Distance of the best match, as the mean absolute per-pixel difference
+	// combined according to Options.CombineMode
+	S float64
+}
+
+// Returns the disparity of each patch of left in right, for a rectified
+// stereo pair, by block matching: each patch is searched for only along the
+// same rows of right. Patches that lie outside right at every disparity are
+// skipped. Disparities are in raster order of the patches.
+func FindDisparities(left, right image.Image, opts DisparityOptions) []PatchDisparity {
+	if opts.Patch.X <= 0 || opts.Patch.Y <= 0 {
+		panic("invalid patch size")
+	}
+	if opts.MinDisparity > opts.MaxDisparity {
+		panic("MinDisparity > MaxDisparity")
+	}
+	stepX, stepY := opts.Step, opts.Step
+	if opts.Step <= 0 {
+		stepX, stepY = opts.Patch.X, opts.Patch.Y
+	}
+	l, r := toRGBA(left), NewField(right)
+	var ds []PatchDisparity
+	for y := l.Rect.Min.Y; y+opts.Patch.Y <= l.Rect.Max.Y; y += stepY {
+		for x := l.Rect.Min.X; x+opts.Patch.X <= l.Rect.Max.X; x += stepX {
+			patch := image.Rectangle{image.Point{x, y}, image.Point{x, y}.Add(opts.Patch)}
+			// hits are at the negated disparity
+			shifts := image.Rect(-opts.MaxDisparity, 0, -opts.MinDisparity+1, 1)
+			p, d, ok := bestMatch(r, l.SubImage(patch), shifts.Intersect(validRect(r.Bounds(), patch)), opts.Options)
+			if ok {
+				ds = append(ds, PatchDisparity{patch.Min, -p.X, d})
+			}
+		}
+	}
+	return ds
+}
+
+// Returns the best match of object in field along the rows of field with
+// top-left corners at row y, from column minX to maxX inclusive, as a Hit
+// scored by the mean absolute per-pixel difference. Returns false if object
+// does not fit in field there.
+func SearchRow(field, object image.Image, y, minX, maxX int, opts Options) (Hit, bool) {
+	rect := image.Rect(minX, y, maxX+1, y+1).Intersect(validRect(field.Bounds(), object.Bounds()))
+	p, d, ok := bestMatch(field, object, rect, opts)
+	return Hit{p, d}, ok
+}
@@ -0,0 +1,47 @@
+package objsearch
+
+import (
+	"image"
+	"testing"
+)
+
+func TestFindDisparities(t *testing.T) {
+	right := randomRGBImage(80, 32)
+	// the left view is shifted right by 6 pixels, and the lower half by 9
+	left := randomRGBImage(80, 32)
+	for y := 0; y < 32; y++ {
+		d := 6
+		if y >= 16 {
+			d = 9
+		}
+		for x := d; x < 80; x++ {
+			left.SetRGBA(x, y, right.RGBAAt(x-d, y))
+		}
+	}
+	ds := FindDisparities(left, right, DisparityOptions{
+		Patch:        image.Point{8, 8},
+		MinDisparity: 0,
+		MaxDisparity: 12,
+	})
+	// 10x4 patches. Those near the left edge cannot be matched at their true
+	// disparity, which would place them outside right.
+	if len(ds) != 40 {
+		t.Fatal("wrong number of patches", len(ds))
+	}
+	for _, d := range ds {
+		want := 6
+		if d.P.Y >= 16 {
+			want = 9
+		}
+		if d.P.X >= 16 && (d.D != want || d.S != 0) {
+			t.Fatal("disparity error", d)
+		}
+	}
+	h, ok := SearchRow(right, crop(right, image.Rect(30, 20, 40, 28)), 20, 0, 70, Options{})
+	if !ok || h != (Hit{image.Point{30, 20}, 0}) {
+		t.Fatal("row search error", h, ok)
+	}
+	if _, ok := SearchRow(right, crop(right, image.Rect(30, 20, 40, 28)), 30, 0, 70, Options{}); ok {
+		t.Fatal("object does not fit at row 30")
+	}
+}
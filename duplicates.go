@@ -0,0 +1,107 @@
+package objsearch
+
+import (
+	"image"
+	"sort"
+)
+
+// Parameters of FindDuplicates
+type DuplicateOptions struct {
+	// Options of the search for each block. Hits are found according to
+	// Tolerance, MinDist, ScoreMode and HitMode, as for SearchImage.
+	Options
+	// Size of the blocks searched for
+	Size image.Point
+	// Distance between the top-left corners of adjacent blocks. Zero is
+	// treated as the block size, so that blocks tile the field.
+	Step int
+	// Blocks whose planes all have a standard deviation of at most
+	// MinStdDev, in [0,1] pixel units, are not searched for, since plain
+	// areas trivially match one another
+	MinStdDev float64
+}
+
+// A pair of matching regions of a field, as found by FindDuplicates
+type DuplicatePair struct {
+	// Top-left corners of the regions, A first in raster order
+	A, B image.Point
+	// Score of the match, as for a Hit
+	S float64
+}
+
+// Finds regions of field repeated elsewhere in field, such as copy-pasted
+// areas or repeated UI widgets, without a template. Blocks of field are
+// searched for in field itself, ignoring the positions at which a block
+// overlaps itself. Returns the pairs of matching regions, best first. Each
+// pair is returned once, though a repeated region larger than a block is
+// returned as several pairs with the same offset B-A.
+func FindDuplicates(field image.Image, opts DuplicateOptions) []DuplicatePair {
+	if opts.Size.X <= 0 || opts.Size.Y <= 0 {
+		panic("invalid block size")
+	}
+	stepX, stepY := opts.Step, opts.Step
+	if opts.Step <= 0 {
+		stepX, stepY = opts.Size.X, opts.Size.Y
+	}
+	f := NewField(field)
+	b := f.Bounds()
+	seen := make(map[[2]image.Point]bool)
+	var pairs []DuplicatePair
+	for y := b.Min.Y; y+opts.Size.Y <= b.Max.Y; y += stepY {
+		for x := b.Min.X; x+opts.Size.X <= b.Max.X; x += stepX {
+			block := image.Rectangle{image.Point{x, y}, image.Point{x, y}.Add(opts.Size)}
+			object := NewObject(f.SubImage(block))
+			if plain(object.planes(opts.ColorMode), opts.MinStdDev) {
+				continue
+			}
+			// hits are at the offset of the block's repeat
+			rect := validRect(b, block)
+			ctx, scores := searchScores(f, object, rect, opts.Options)
+			ctx.ignore(scores, image.Rectangle{opts.Size.Mul(-1).Add(image.Point{1, 1}), opts.Size}.Intersect(rect))
+			for _, h := range ctx.hits(scores) {
+				p := DuplicatePair{block.Min, block.Min.Add(h.P), h.S}
+				if p.B.Y < p.A.Y || p.B.Y == p.A.Y && p.B.X < p.A.X {
+					p.A, p.B = p.B, p.A
+				}
+				if !seen[[2]image.Point{p.A, p.B}] {
+					seen[[2]image.Point{p.A, p.B}] = true
+					pairs = append(pairs, p)
+				}
+			}
+		}
+	}
+	ctx := newContext(b, opts.Options)
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return ctx.better(pairs[i].S, pairs[j].S)
+	})
+	return pairs
+}
+
+// return true if each of planes has a standard deviation of at most sd
+func plain(planes []*FloatImage, sd float64) bool {
+	for _, p := range planes {
+		if min, max := minMax(p.Pix); min == max {
+			continue
+		}
+		if _, s := meanStdDev(p.Pix); s > sd {
+			return false
+		}
+	}
+	return true
+}
+
+// set the scores at the points of r, within ctx.SearchRect, to the worst of
+// scores, so that no hits are found there
+func (ctx objSearchContext) ignore(scores []float64, r image.Rectangle) {
+	worst := scores[0]
+	for _, s := range scores {
+		if ctx.better(worst, s) {
+			worst = s
+		}
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			scores[ctx.offset(x, y)] = worst
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package objsearch
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	field := randomRGBImage(80, 60)
+	// a copy-pasted region, and a plain area that is repeated trivially
+	draw.Draw(field, image.Rect(50, 30, 66, 46), field, image.Point{8, 4}, draw.Src)
+	draw.Draw(field, image.Rect(0, 48, 80, 60), image.NewUniform(color.Gray{80}), image.Point{}, draw.Src)
+	pairs := FindDuplicates(field, DuplicateOptions{
+		Options: Options{Tolerance: 0.05, MinDist: 4},
+		Size:    image.Point{8, 8},
+		Step:    4,
+	})
+	if len(pairs) == 0 {
+		t.Fatal("no duplicates found")
+	}
+	for _, p := range pairs {
+		if p.B.Sub(p.A) != (image.Point{42, 26}) || p.S != 0 {
+			t.Fatal("unexpected pair", p)
+		}
+		if !(image.Rectangle{p.A, p.A.Add(image.Point{8, 8})}).In(image.Rect(8, 4, 24, 20)) {
+			t.Fatal("pair outside the copied region", p)
+		}
+	}
+	// blocks at step 4 entirely within the source, 3x3 of them, or within
+	// the copy, 2x2 of them
+	if len(pairs) != 13 {
+		t.Fatal("wrong number of pairs", len(pairs), pairs)
+	}
+}
@@ -0,0 +1,53 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+)
+
+// return the gradient magnitude of object, the largest over its planes, as a
+// mask scaled so that the strongest edge has weight 255, or nil if object is
+// flat. Gradients are central differences, clamped at the object's edges.
+func edgeMask(object []*FloatImage) *image.Alpha {
+	r := object[0].Rect
+	g := make([]float64, r.Dx()*r.Dy())
+	clampX := func(x int) int {
+		if x < r.Min.X {
+			return r.Min.X
+		}
+		if x >= r.Max.X {
+			return r.Max.X - 1
+		}
+		return x
+	}
+	clampY := func(y int) int {
+		if y < r.Min.Y {
+			return r.Min.Y
+		}
+		if y >= r.Max.Y {
+			return r.Max.Y - 1
+		}
+		return y
+	}
+	max := 0.0
+	for _, p := range object {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				dx := p.FloatAt(clampX(x+1), y) - p.FloatAt(clampX(x-1), y)
+				dy := p.FloatAt(x, clampY(y+1)) - p.FloatAt(x, clampY(y-1))
+				i := (y-r.Min.Y)*r.Dx() + (x - r.Min.X)
+				g[i] = math.Max(g[i], math.Hypot(dx, dy))
+				max = math.Max(max, g[i])
+			}
+		}
+	}
+	if max == 0 {
+		return nil
+	}
+	m := image.NewAlpha(r)
+	for i := range g {
+		y, x := i/r.Dx(), i%r.Dx()
+		m.Pix[m.PixOffset(r.Min.X+x, r.Min.Y+y)] = uint8(math.Round(255 * g[i] / max))
+	}
+	return m
+}
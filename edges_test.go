@@ -0,0 +1,41 @@
+package objsearch
+
+import (
+	"image"
+	"testing"
+)
+
+// test that an object with a fine pattern on a plain background is found on
+// a different background, rather than on a plain patch, when edge-weighted
+func TestEdgeWeighted(t *testing.T) {
+	checker := func(img *FloatImage, p image.Point) {
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				img.Pix[img.PixOffset(p.X+x, p.Y+y)] = float64((x + y) % 2 * 200)
+			}
+		}
+	}
+	object := NewFloatImage(image.Rect(0, 0, 20, 20))
+	fillFloat(object, object.Rect, 100)
+	checker(object, image.Point{8, 8})
+	field := NewFloatImage(image.Rect(0, 0, 80, 40))
+	fillFloat(field, field.Rect, 50)
+	// the object, on a brighter background
+	fillFloat(field, image.Rect(5, 10, 25, 30), 130)
+	checker(field, image.Point{13, 18})
+	// a plain patch
+	fillFloat(field, image.Rect(45, 10, 65, 30), 100)
+	rect := validRect(field.Rect, object.Rect)
+	opts := Options{Tolerance: 1, HitMode: HITMODE_BESTK, K: 1}
+	if h := SearchFloat(field, object, rect, opts); len(h) != 1 || h[0].P != (image.Point{45, 10}) {
+		t.Fatal("expected the plain patch without edge weights", h)
+	}
+	opts.EdgeWeighted = true
+	if h := SearchFloat(field, object, rect, opts); len(h) != 1 || h[0].P != (image.Point{5, 10}) {
+		t.Fatal("edge-weighted search error", h)
+	}
+	// flat objects are weighted equally
+	if edgeMask([]*FloatImage{NewFloatImage(object.Rect)}) != nil {
+		t.Fatal("flat object has an edge mask")
+	}
+}
@@ -0,0 +1,202 @@
+package eval
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/hypoactiv/objsearch"
+)
+
+// How well one configuration separates the expected objects of a case from
+// the rest of the field
+type Separation struct {
+	// Accuracy of the hits found with the configuration's tolerance
+	Metrics Metrics
+	// Rank, from 1, of the worst-ranked expected object among all candidate
+	// hits at least MinDist apart, best first. If an expected object is not
+	// among the candidates, Rank is one more than the number of candidates.
+	Rank int
+	// Score difference between the worst-ranked expected object and the best
+	// candidate not near any expected object, in the configuration's score
+	// units, positive if every expected object scores better. +Inf if there
+	// are no other candidates, and -Inf if an expected object is not among
+	// the candidates.
+	Gap float64
+}
+
+// The outcome of one case of an A/B comparison
+type CaseComparison struct {
+	Name string
+	A, B Separation
+	// 1 if A did better, 2 if B did, or 0 if neither did. Configurations are
+	// compared by F1 score, then by Rank, then by Gap.
+	Winner int
+	// If not nil, the case could not be run
+	Err error
+}
+
+// The result of CompareCorpus
+type Comparison struct {
+	Cases []CaseComparison
+	// Accuracy of each configuration over all cases
+	A, B Metrics
+	// Number of cases won by each configuration, and tied
+	WinsA, WinsB, Ties int
+	// 1 if A did better overall, 2 if B did, or 0 if neither did.
+	// Configurations are compared by F1 score over all cases, then by
+	// cases won.
+	Winner int
+}
+
+// Searches each case of the golden corpus in dir, as described by RunCorpus,
+// with both a and b, and reports which configuration better separates the
+// expected hits from the background, case by case and overall. Hits are
+// matched to expected hits within tol pixels.
+func CompareCorpus(dir string, a, b objsearch.Options, tol int) (*Comparison, error) {
+	names, err := corpusCases(dir)
+	if err != nil {
+		return nil, err
+	}
+	c := &Comparison{}
+	var msA, msB []Metrics
+	for _, name := range names {
+		cc := CaseComparison{Name: name}
+		caseDir := filepath.Join(dir, name)
+		field, template, err := loadCase(caseDir)
+		var expected []objsearch.Hit
+		if err == nil {
+			expected, err = loadExpected(caseDir)
+		}
+		if err != nil {
+			cc.Err = err
+			c.Cases = append(c.Cases, cc)
+			continue
+		}
+		truth := make([]image.Point, len(expected))
+		for i, h := range expected {
+			truth[i] = h.P
+		}
+		cc.A = separation(field, template, truth, a, tol)
+		cc.B = separation(field, template, truth, b, tol)
+		cc.Winner = compareSeparations(cc.A, cc.B)
+		switch cc.Winner {
+		case 1:
+			c.WinsA++
+		case 2:
+			c.WinsB++
+		default:
+			c.Ties++
+		}
+		msA, msB = append(msA, cc.A.Metrics), append(msB, cc.B.Metrics)
+		c.Cases = append(c.Cases, cc)
+	}
+	c.A, c.B = Combine(msA...), Combine(msB...)
+	switch {
+	case c.A.F1 > c.B.F1 || (c.A.F1 == c.B.F1 && c.WinsA > c.WinsB):
+		c.Winner = 1
+	case c.B.F1 > c.A.F1 || (c.A.F1 == c.B.F1 && c.WinsB > c.WinsA):
+		c.Winner = 2
+	}
+	return c, nil
+}
+
+// Summarizes the comparison, one line per case and a final line overall
+func (c *Comparison) String() string {
+	var b strings.Builder
+	for _, cc := range c.Cases {
+		if cc.Err != nil {
+			fmt.Fprintf(&b, "%s: %v\n", cc.Name, cc.Err)
+			continue
+		}
+		fmt.Fprintf(&b, "%s: A f1 %.3f rank %d gap %.4g, B f1 %.3f rank %d gap %.4g, winner %s\n",
+			cc.Name, cc.A.Metrics.F1, cc.A.Rank, cc.A.Gap, cc.B.Metrics.F1, cc.B.Rank, cc.B.Gap, winnerName(cc.Winner))
+	}
+	fmt.Fprintf(&b, "overall: A f1 %.3f wins %d, B f1 %.3f wins %d, ties %d, winner %s\n",
+		c.A.F1, c.WinsA, c.B.F1, c.WinsB, c.Ties, winnerName(c.Winner))
+	return b.String()
+}
+
+// return the separation of truth in field with opts
+func separation(field, template image.Image, truth []image.Point, opts objsearch.Options, tol int) Separation {
+	r := objsearch.SearchResult(field, template, image.Rectangle{}, opts)
+	if r == nil {
+		return Separation{Metrics: Evaluate(truth, nil, tol), Rank: 1, Gap: math.Inf(-1)}
+	}
+	s := Separation{Metrics: Evaluate(truth, r.Rethreshold(opts.Tolerance, opts.MinDist), tol)}
+	// every position is a candidate at the loosest tolerance
+	loosest, sign := math.Inf(1), 1.0
+	if opts.ScoreMode == objsearch.SCOREMODE_CCOEFF_NORMED {
+		loosest, sign = math.Inf(-1), -1
+	}
+	candidates := r.Rethreshold(loosest, opts.MinDist)
+	near := make([]bool, len(candidates))
+	worst := math.Inf(-1)
+	s.Rank = 0
+	for _, p := range truth {
+		rank := len(candidates) + 1
+		for i, h := range candidates {
+			if (objsearch.Hit{P: p}).Distance(h) <= tol {
+				near[i] = true
+				if i+1 < rank {
+					rank = i + 1
+				}
+			}
+		}
+		if rank > s.Rank {
+			s.Rank = rank
+		}
+		if rank > len(candidates) {
+			worst = math.Inf(1)
+		} else {
+			worst = math.Max(worst, sign*candidates[rank-1].S)
+		}
+	}
+	impostor := math.Inf(1)
+	for i, h := range candidates {
+		if !near[i] {
+			impostor = sign * h.S
+			break
+		}
+	}
+	// lower signed scores are better
+	s.Gap = impostor - worst
+	if math.IsNaN(s.Gap) {
+		// neither an impostor nor a found expected object
+		s.Gap = math.Inf(-1)
+	}
+	return s
+}
+
+// return 1 if a is better than b, 2 if b is better than a, or 0
+func compareSeparations(a, b Separation) int {
+	switch {
+	case a.Metrics.F1 != b.Metrics.F1:
+		return better(a.Metrics.F1 > b.Metrics.F1)
+	case a.Rank != b.Rank:
+		return better(a.Rank < b.Rank)
+	case a.Gap != b.Gap:
+		return better(a.Gap > b.Gap)
+	}
+	return 0
+}
+
+// return 1 if aBetter, otherwise 2
+func better(aBetter bool) int {
+	if aBetter {
+		return 1
+	}
+	return 2
+}
+
+func winnerName(w int) string {
+	switch w {
+	case 1:
+		return "A"
+	case 2:
+		return "B"
+	}
+	return "tie"
+}
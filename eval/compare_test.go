@@ -0,0 +1,42 @@
+package eval
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/hypoactiv/objsearch"
+)
+
+func TestCompareCorpus(t *testing.T) {
+	dir := writeCorpus(t)
+	a := objsearch.Options{Tolerance: 0.1, MinDist: 8}
+	if err := UpdateCorpus(dir, a); err != nil {
+		t.Fatal(err)
+	}
+	// B finds the same objects, with false positives
+	b := objsearch.Options{Tolerance: 0.9, MinDist: 8}
+	c, err := CompareCorpus(dir, a, b, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Winner != 1 || c.WinsA != 2 || c.WinsB != 0 || c.A.F1 != 1 || c.B.F1 >= 1 {
+		t.Fatal("comparison error", c)
+	}
+	for _, cc := range c.Cases {
+		if cc.A.Rank != 1 || cc.A.Gap <= 0 || cc.A.Gap != cc.B.Gap {
+			t.Fatal("separation error", cc)
+		}
+	}
+	if !strings.Contains(c.String(), "overall: A f1 1.000 wins 2") {
+		t.Fatal("summary error", c)
+	}
+	// correlation separates the same objects, in different units
+	b = objsearch.Options{Tolerance: 0.9, MinDist: 8, ScoreMode: objsearch.SCOREMODE_CCOEFF_NORMED}
+	c, _ = CompareCorpus(dir, a, b, 1)
+	for _, cc := range c.Cases {
+		if cc.B.Metrics.F1 != 1 || cc.B.Rank != 1 || cc.B.Gap <= 0 || math.IsInf(cc.B.Gap, 0) {
+			t.Fatal("correlation separation error", cc)
+		}
+	}
+}
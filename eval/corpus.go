@@ -0,0 +1,187 @@
+package eval
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hypoactiv/objsearch"
+)
+
+// Names of the files making up a case of a golden corpus. The field and
+// template may be PNG, JPEG or GIF images with any extension.
+const (
+	CorpusField    = "field"
+	CorpusTemplate = "template"
+	CorpusExpected = "expected.csv"
+)
+
+// The outcome of one case of a golden corpus
+type CaseReport struct {
+	// Name of the case's directory
+	Name string
+	// Hits expected, as read from the case's expected.csv, and found
+	Expected, Hits []objsearch.Hit
+	// Accuracy of Hits against Expected
+	Metrics Metrics
+	// If not nil, the case could not be run
+	Err error
+}
+
+// Returns true if the case ran, every expected hit was found and no other
+// hits were
+func (r CaseReport) Passed() bool {
+	return r.Err == nil && r.Metrics.FalsePositives == 0 && r.Metrics.FalseNegatives == 0
+}
+
+// Describes the case's outcome, listing any missing and unexpected hits
+func (r CaseReport) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("%s: %v", r.Name, r.Err)
+	}
+	if r.Passed() {
+		return fmt.Sprintf("%s: ok", r.Name)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d missing, %d unexpected", r.Name, r.Metrics.FalseNegatives, r.Metrics.FalsePositives)
+	for _, p := range r.Metrics.Missed {
+		fmt.Fprintf(&b, "\n\t- %d,%d", p.X, p.Y)
+	}
+	for _, h := range r.Metrics.Unmatched {
+		fmt.Fprintf(&b, "\n\t+ %d,%d score %g", h.P.X, h.P.Y, h.S)
+	}
+	return b.String()
+}
+
+// Runs the golden corpus in dir, returning a report for each case in order
+// of name. Each subdirectory of dir is a case, holding a field image, a
+// template image and the hits expected when searching the field for the
+// template with opts, as written by objsearch.WriteHitsCSV. Hits are matched
+// to expected hits as Evaluate does, within tol pixels.
+//
+// A test suite can fail on each report that has not Passed, printing the
+// report to show what changed.
+func RunCorpus(dir string, opts objsearch.Options, tol int) ([]CaseReport, error) {
+	names, err := corpusCases(dir)
+	if err != nil {
+		return nil, err
+	}
+	reports := make([]CaseReport, len(names))
+	for i, name := range names {
+		r := CaseReport{Name: name}
+		r.Expected, r.Hits, r.Err = runCase(filepath.Join(dir, name), opts)
+		if r.Err == nil {
+			truth := make([]image.Point, len(r.Expected))
+			for j, h := range r.Expected {
+				truth[j] = h.P
+			}
+			r.Metrics = Evaluate(truth, r.Hits, tol)
+		}
+		reports[i] = r
+	}
+	return reports, nil
+}
+
+// Rewrites the expected hits of every case of the golden corpus in dir with
+// the hits found with opts, e.g. after an intended change in behavior
+func UpdateCorpus(dir string, opts objsearch.Options) error {
+	names, err := corpusCases(dir)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		caseDir := filepath.Join(dir, name)
+		field, template, err := loadCase(caseDir)
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		hits := objsearch.NewSearcher(template, opts).Search(field)
+		f, err := os.Create(filepath.Join(caseDir, CorpusExpected))
+		if err != nil {
+			return err
+		}
+		if err := objsearch.WriteHitsCSV(f, hits); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// return the names of the case directories in dir, sorted
+func corpusCases(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// return the expected hits of the case in dir, and the hits found
+func runCase(dir string, opts objsearch.Options) (expected, hits []objsearch.Hit, err error) {
+	field, template, err := loadCase(dir)
+	if err != nil {
+		return
+	}
+	if expected, err = loadExpected(dir); err != nil {
+		return
+	}
+	hits = objsearch.NewSearcher(template, opts).Search(field)
+	return
+}
+
+// load the expected hits of the case in dir
+func loadExpected(dir string) ([]objsearch.Hit, error) {
+	f, err := os.Open(filepath.Join(dir, CorpusExpected))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return objsearch.ReadHitsCSV(f)
+}
+
+// load the field and template of the case in dir
+func loadCase(dir string) (*objsearch.Field, *objsearch.Object, error) {
+	fieldPath, err := findImage(dir, CorpusField)
+	if err != nil {
+		return nil, nil, err
+	}
+	templatePath, err := findImage(dir, CorpusTemplate)
+	if err != nil {
+		return nil, nil, err
+	}
+	field, err := objsearch.LoadFieldFile(fieldPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	template, err := objsearch.LoadObjectFile(templatePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return field, template, nil
+}
+
+// return the path of the file in dir named name with any extension
+func findImage(dir, name string) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, name+".*"))
+	if err != nil {
+		return "", err
+	}
+	if len(matches) != 1 {
+		return "", fmt.Errorf("want one %s image, found %d", name, len(matches))
+	}
+	return matches[0], nil
+}
@@ -0,0 +1,69 @@
+package eval
+
+import (
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hypoactiv/objsearch"
+	"github.com/hypoactiv/objsearch/objsearchtest"
+)
+
+func writePNG(t *testing.T, path string, img image.Image) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// write a corpus of two cases, each with the template at (10,12), without
+// expected hits
+func writeCorpus(t *testing.T) string {
+	dir := t.TempDir()
+	g := objsearchtest.NewGenerator(1)
+	template := g.Noise(8, 8)
+	for _, name := range []string{"a", "b"} {
+		os.Mkdir(filepath.Join(dir, name), 0777)
+		field := g.Noise(50, 40)
+		draw.Draw(field, template.Rect.Add(image.Point{10, 12}), template, image.Point{}, draw.Src)
+		writePNG(t, filepath.Join(dir, name, "field.png"), field)
+		writePNG(t, filepath.Join(dir, name, "template.png"), template)
+	}
+	return dir
+}
+
+func TestCorpus(t *testing.T) {
+	dir := writeCorpus(t)
+	os.Mkdir(filepath.Join(dir, "broken"), 0777)
+	opts := objsearch.Options{Tolerance: 0.1, MinDist: 8}
+	if err := UpdateCorpus(dir, opts); err == nil {
+		t.Fatal("expected error for broken case")
+	}
+	os.Remove(filepath.Join(dir, "broken"))
+	if err := UpdateCorpus(dir, opts); err != nil {
+		t.Fatal(err)
+	}
+	reports, err := RunCorpus(dir, opts, 1)
+	if err != nil || len(reports) != 2 {
+		t.Fatal("run error", err)
+	}
+	for _, r := range reports {
+		if !r.Passed() || len(r.Expected) != 1 || r.Expected[0].P != (image.Point{10, 12}) {
+			t.Fatal("case should pass", r)
+		}
+	}
+	// a looser tolerance finds unexpected hits
+	opts.Tolerance = 0.9
+	reports, _ = RunCorpus(dir, opts, 1)
+	if reports[0].Passed() || !strings.Contains(reports[0].String(), "unexpected\n\t+ ") {
+		t.Fatal("case should fail", reports[0])
+	}
+}
@@ -0,0 +1,119 @@
+// Package eval measures the accuracy of objsearch hits against ground truth
+// object locations, so that metrics and parameters can be compared
+// objectively, and runs golden corpora of expected hits to catch
+// regressions.
+package eval
+
+import (
+	"image"
+	"math"
+
+	"github.com/hypoactiv/objsearch"
+)
+
+// A hit matched to a ground truth location
+type Match struct {
+	Truth image.Point
+	Hit   objsearch.Hit
+	// Euclidean distance in pixels between Truth and Hit.P
+	Error float64
+}
+
+// Accuracy of a set of hits
+type Metrics struct {
+	TruePositives, FalsePositives, FalseNegatives int
+	// Precision, recall and F1 score of the hits. Precision is 1 if there are
+	// no hits, and recall is 1 if there are no ground truth locations.
+	Precision, Recall, F1 float64
+	// Mean, root mean square and maximum localization error of the matched
+	// hits, in pixels
+	MeanError, RMSError, MaxError float64
+	// Hits matched to ground truth locations, in the order the hits were
+	// given
+	Matches []Match
+	// Hits not matched to any ground truth location
+	Unmatched []objsearch.Hit
+	// Ground truth locations not matched by any hit
+	Missed []image.Point
+}
+
+// Matches hits to truth, the top-left corners of the objects actually in the
+// field, and returns the accuracy of the hits. Hits are considered in the
+// order given, which should be best first, as objsearch returns them, and
+// each is matched to the nearest unmatched truth location at most tol pixels
+// away, as measured by objsearch.Hit.Distance.
+func Evaluate(truth []image.Point, hits []objsearch.Hit, tol int) Metrics {
+	matched := make([]bool, len(truth))
+	m := Metrics{}
+	for _, h := range hits {
+		best := -1
+		for i, p := range truth {
+			if matched[i] || (objsearch.Hit{P: p}).Distance(h) > tol {
+				continue
+			}
+			if best < 0 || distance(p, h.P) < distance(truth[best], h.P) {
+				best = i
+			}
+		}
+		if best < 0 {
+			m.Unmatched = append(m.Unmatched, h)
+			continue
+		}
+		matched[best] = true
+		m.Matches = append(m.Matches, Match{truth[best], h, distance(truth[best], h.P)})
+	}
+	for i, p := range truth {
+		if !matched[i] {
+			m.Missed = append(m.Missed, p)
+		}
+	}
+	m.finish()
+	return m
+}
+
+// Returns the accuracy of the hits of all of ms together, e.g. to evaluate a
+// set of fields
+func Combine(ms ...Metrics) Metrics {
+	c := Metrics{}
+	for _, m := range ms {
+		c.Matches = append(c.Matches, m.Matches...)
+		c.Unmatched = append(c.Unmatched, m.Unmatched...)
+		c.Missed = append(c.Missed, m.Missed...)
+	}
+	c.finish()
+	return c
+}
+
+// compute m's counts and statistics from its matches and misses
+func (m *Metrics) finish() {
+	m.TruePositives = len(m.Matches)
+	m.FalsePositives = len(m.Unmatched)
+	m.FalseNegatives = len(m.Missed)
+	m.Precision, m.Recall = 1, 1
+	if n := m.TruePositives + m.FalsePositives; n != 0 {
+		m.Precision = float64(m.TruePositives) / float64(n)
+	}
+	if n := m.TruePositives + m.FalseNegatives; n != 0 {
+		m.Recall = float64(m.TruePositives) / float64(n)
+	}
+	m.F1 = 0
+	if m.Precision+m.Recall != 0 {
+		m.F1 = 2 * m.Precision * m.Recall / (m.Precision + m.Recall)
+	}
+	m.MeanError, m.RMSError, m.MaxError = 0, 0, 0
+	if len(m.Matches) == 0 {
+		return
+	}
+	for _, match := range m.Matches {
+		m.MeanError += match.Error
+		m.RMSError += match.Error * match.Error
+		m.MaxError = math.Max(m.MaxError, match.Error)
+	}
+	m.MeanError /= float64(len(m.Matches))
+	m.RMSError = math.Sqrt(m.RMSError / float64(len(m.Matches)))
+}
+
+// return the Euclidean distance between p and q
+func distance(p, q image.Point) float64 {
+	return math.Hypot(float64(p.X-q.X), float64(p.Y-q.Y))
+}
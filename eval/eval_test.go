@@ -0,0 +1,40 @@
+package eval
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/hypoactiv/objsearch"
+)
+
+func TestEvaluate(t *testing.T) {
+	truth := []image.Point{{10, 10}, {50, 10}, {90, 90}}
+	hits := []objsearch.Hit{
+		{P: image.Point{50, 10}, S: 0.01},
+		{P: image.Point{200, 200}, S: 0.02},
+		{P: image.Point{13, 14}, S: 0.05},
+		// a worse hit near the same object is a false positive
+		{P: image.Point{11, 10}, S: 0.08},
+	}
+	m := Evaluate(truth, hits, 5)
+	if m.TruePositives != 2 || m.FalsePositives != 2 || m.FalseNegatives != 1 {
+		t.Fatal("count error", m.TruePositives, m.FalsePositives, m.FalseNegatives)
+	}
+	if m.Precision != 0.5 || math.Abs(m.Recall-2.0/3) > 1e-12 || math.Abs(m.F1-4.0/7) > 1e-12 {
+		t.Fatal("rate error", m.Precision, m.Recall, m.F1)
+	}
+	if m.MaxError != 5 || m.MeanError != 2.5 || m.RMSError != math.Sqrt(12.5) {
+		t.Fatal("localization error", m.MeanError, m.RMSError, m.MaxError)
+	}
+	if m.Matches[0].Truth != (image.Point{50, 10}) || m.Missed[0] != (image.Point{90, 90}) {
+		t.Fatal("match error", m.Matches, m.Missed)
+	}
+	c := Combine(m, Evaluate(truth[2:], []objsearch.Hit{{P: image.Point{90, 90}}}, 5))
+	if c.TruePositives != 3 || c.FalseNegatives != 1 || c.Matches[0].Error != 0 {
+		t.Fatal("combine error", c.TruePositives, c.FalseNegatives)
+	}
+	if e := Evaluate(nil, nil, 5); e.Precision != 1 || e.Recall != 1 || e.F1 != 1 {
+		t.Fatal("empty evaluation error", e)
+	}
+}
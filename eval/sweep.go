@@ -0,0 +1,123 @@
+package eval
+
+import (
+	"image"
+	"math"
+
+	"github.com/hypoactiv/objsearch"
+)
+
+// A search result with the ground truth object locations in its field
+type Case struct {
+	Result *objsearch.Result
+	// Top-left corners of every occurence of the object in the field
+	Truth []image.Point
+}
+
+// A point on a threshold sweep curve
+type CurvePoint struct {
+	Tolerance float64
+	Metrics   Metrics
+	// Fraction of the positions searched without an object at which a hit
+	// was found
+	FalsePositiveRate float64
+}
+
+// A threshold sweep, from the most selective tolerance to the least
+type Curve []CurvePoint
+
+// Evaluates the hits of cases at steps tolerances spread evenly over the score
+// range of their score mode: [0,1] for objsearch.SCOREMODE_L1,
+// objsearch.SCOREMODE_L1_ABSOLUTE and objsearch.SCOREMODE_SQDIFF_NORMED,
+// [-1,1] for objsearch.SCOREMODE_CCOEFF_NORMED, and from the lowest score to 0
+// for objsearch.SCOREMODE_ZSCORE. Hits are found with
+// objsearch.Result.Rethreshold, so the scores are not recomputed, and matched
+// to the ground truth as Evaluate does. All cases must use the same score
+// mode.
+func Sweep(cases []Case, steps, tol int) Curve {
+	if len(cases) == 0 || steps < 2 {
+		return nil
+	}
+	mode := cases[0].Result.Options.ScoreMode
+	lo, hi := 0.0, 1.0
+	switch mode {
+	case objsearch.SCOREMODE_CCOEFF_NORMED:
+		lo = -1
+	case objsearch.SCOREMODE_ZSCORE:
+		hi = 0
+		for _, c := range cases {
+			if s := c.Result.HitScores(); s != nil {
+				lo = math.Min(lo, s.Pix[minIndex(s.Pix)])
+			}
+		}
+	}
+	// count the positions without an object
+	negatives := 0
+	for _, c := range cases {
+		if c.Result.Options.ScoreMode != mode {
+			panic("cases have different score modes")
+		}
+		negatives += len(c.Result.Scores.Pix) - len(c.Truth)
+	}
+	curve := make(Curve, steps)
+	for i := range curve {
+		t := lo + (hi-lo)*float64(i)/float64(steps-1)
+		ms := make([]Metrics, len(cases))
+		for j, c := range cases {
+			ms[j] = Evaluate(c.Truth, c.Result.Rethreshold(t, c.Result.Options.MinDist), tol)
+		}
+		p := CurvePoint{Tolerance: t, Metrics: Combine(ms...)}
+		if negatives > 0 {
+			p.FalsePositiveRate = float64(p.Metrics.FalsePositives) / float64(negatives)
+		}
+		curve[i] = p
+	}
+	if mode == objsearch.SCOREMODE_CCOEFF_NORMED {
+		// hits score above the tolerance, so sensitivity decreases with it
+		for i, j := 0, len(curve)-1; i < j; i, j = i+1, j-1 {
+			curve[i], curve[j] = curve[j], curve[i]
+		}
+	}
+	return curve
+}
+
+// Returns the point of c with the highest F1 score, preferring the most
+// selective tolerance among ties. ok is false if c is empty.
+func (c Curve) Best() (best CurvePoint, ok bool) {
+	for i, p := range c {
+		if i == 0 || p.Metrics.F1 > best.Metrics.F1 {
+			best, ok = p, true
+		}
+	}
+	return
+}
+
+// Returns the points of c as (false positive rate, recall) pairs, the
+// receiver operating characteristic curve
+func (c Curve) ROC() [][2]float64 {
+	r := make([][2]float64, len(c))
+	for i, p := range c {
+		r[i] = [2]float64{p.FalsePositiveRate, p.Metrics.Recall}
+	}
+	return r
+}
+
+// Returns the points of c as (recall, precision) pairs, the precision-recall
+// curve
+func (c Curve) PR() [][2]float64 {
+	r := make([][2]float64, len(c))
+	for i, p := range c {
+		r[i] = [2]float64{p.Metrics.Recall, p.Metrics.Precision}
+	}
+	return r
+}
+
+// return the index of the smallest value in d
+func minIndex(d []float64) (m int) {
+	for i := range d {
+		if d[i] < d[m] {
+			m = i
+		}
+	}
+	return
+}
@@ -0,0 +1,42 @@
+package eval
+
+import (
+	"image"
+	"image/draw"
+	"testing"
+
+	"github.com/hypoactiv/objsearch"
+	"github.com/hypoactiv/objsearch/objsearchtest"
+)
+
+func TestSweep(t *testing.T) {
+	g := objsearchtest.NewGenerator(1)
+	object := g.Noise(8, 8)
+	var cases []Case
+	for _, mode := range []objsearch.ScoreMode{objsearch.SCOREMODE_L1, objsearch.SCOREMODE_CCOEFF_NORMED, objsearch.SCOREMODE_ZSCORE} {
+		field := g.Noise(60, 40)
+		truth := []image.Point{{5, 5}, {40, 20}}
+		for _, p := range truth {
+			draw.Draw(field, object.Bounds().Add(p), object, image.Point{}, draw.Src)
+		}
+		res := objsearch.SearchResult(field, object, image.Rect(0, 0, 53, 33), objsearch.Options{MinDist: 8, ScoreMode: mode})
+		cases = []Case{{res, truth}}
+		c := Sweep(cases, 11, 2)
+		if len(c) != 11 || c[0].Metrics.TruePositives != 0 || c[len(c)-1].Metrics.FalsePositives == 0 {
+			t.Fatal("sweep error", mode, c[0].Metrics.TruePositives)
+		}
+		if c[len(c)-1].FalsePositiveRate <= c[0].FalsePositiveRate {
+			t.Fatal("false positive rate should grow along the curve", mode)
+		}
+		best, ok := c.Best()
+		if !ok || best.Metrics.F1 != 1 || best.Metrics.TruePositives != 2 {
+			t.Fatal("best point error", mode, best)
+		}
+		if roc, pr := c.ROC(), c.PR(); len(roc) != 11 || pr[0] != [2]float64{0, 1} {
+			t.Fatal("curve data error", roc, pr)
+		}
+	}
+	if Sweep(nil, 10, 2) != nil {
+		t.Fatal("expected nil curve")
+	}
+}
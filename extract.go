@@ -0,0 +1,54 @@
+package objsearch
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Crops a template from field around seed, e.g. a point clicked by the user.
+// Starting from a minSize by minSize square centered on seed, the window
+// grows until AnalyzeUniqueness, with minGap and opts, no longer finds it
+// too generic, or until it would exceed maxSize. Windows are clipped to the
+// field.
+//
+// Returns the template, with bounds at the origin, and the window of field
+// it was cropped from. ok is false if no window up to maxSize is unique
+// enough, in which case the largest window tried is returned.
+func ExtractTemplate(field image.Image, seed image.Point, minSize, maxSize int, minGap float64, opts Options) (template *image.RGBA, r image.Rectangle, ok bool) {
+	if minSize <= 0 || maxSize < minSize {
+		panic("invalid template sizes")
+	}
+	f := NewField(field)
+	// AnalyzeUniqueness would warn about every window that is too small
+	opts.VerboseOut = nil
+	for size := minSize; ; {
+		min := seed.Sub(image.Point{size / 2, size / 2})
+		r = image.Rectangle{min, min.Add(image.Point{size, size})}.Intersect(f.Rect)
+		if r.Empty() {
+			panic("seed outside field")
+		}
+		template = image.NewRGBA(image.Rectangle{Max: r.Size()})
+		draw.Draw(template, template.Rect, f, r.Min, draw.Src)
+		if r.Size() == f.Rect.Size() {
+			// nothing else to compare against
+			return template, r, false
+		}
+		if !AnalyzeUniqueness(f, template, minGap, opts).Generic {
+			return template, r, true
+		}
+		if size == maxSize {
+			return template, r, false
+		}
+		// grow by a quarter, and at least a pixel on each side
+		size += size / 4
+		if size%2 == 1 {
+			size++
+		}
+		if size < minSize+2 {
+			size = minSize + 2
+		}
+		if size > maxSize {
+			size = maxSize
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package objsearch
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestExtractTemplate(t *testing.T) {
+	// a flat field with a single textured patch
+	field := image.NewRGBA(image.Rect(0, 0, 80, 80))
+	draw.Draw(field, field.Rect, image.NewUniform(color.RGBA{40, 40, 40, 255}), image.Point{}, draw.Src)
+	field = frameWithObject(field, randomRGBImage(12, 12), image.Point{50, 30})
+	// a small window around a flat point is generic, until it reaches the
+	// patch
+	template, r, ok := ExtractTemplate(field, image.Point{46, 33}, 4, 30, 0.02, Options{})
+	if !ok || !r.Overlaps(image.Rect(50, 30, 62, 42)) || template.Rect.Size() != r.Size() {
+		t.Fatal("template extraction error", r, ok)
+	}
+	if r.Dx() <= 4 {
+		t.Fatal("expected window to grow", r)
+	}
+	hits := SearchImage(field, template, validRect(field.Rect, template.Rect), Options{Tolerance: 0.01})
+	if len(hits) != 1 || hits[0].P != r.Min {
+		t.Fatal("extracted template does not match its window", hits)
+	}
+	// no window around the flat corner is unique
+	if _, _, ok := ExtractTemplate(field, image.Point{5, 5}, 4, 10, 0.02, Options{}); ok {
+		t.Fatal("expected generic windows to fail")
+	}
+}
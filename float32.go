@@ -0,0 +1,74 @@
+package objsearch
+
+// return the number of distances in res
+func (res objSearchResult) len() int {
+	if res.distances32 != nil {
+		return len(res.distances32)
+	}
+	return len(res.distances)
+}
+
+// return the j-th distance of res
+func (res objSearchResult) at(j int) float64 {
+	if res.distances32 != nil {
+		return float64(res.distances32[j])
+	}
+	return res.distances[j]
+}
+
+// set the j-th distance of res to d
+func (res objSearchResult) set(j int, d float64) {
+	if res.distances32 != nil {
+		res.distances32[j] = float32(d)
+	} else {
+		res.distances[j] = d
+	}
+}
+
+// return the distances of res as float64s
+func (res objSearchResult) float64s() []float64 {
+	if res.distances32 == nil {
+		return res.distances
+	}
+	d := make([]float64, len(res.distances32))
+	for j, v := range res.distances32 {
+		d[j] = float64(v)
+	}
+	return d
+}
+
+// as minMax, for float32 distances
+func minMax32(d []float32) (min, max float64) {
+	lo, hi := d[0], d[0]
+	for _, v := range d {
+		if v < lo {
+			lo = v
+		}
+		if v > hi {
+			hi = v
+		}
+	}
+	return float64(lo), float64(hi)
+}
+
+// return the weighted L1 distance between object and the window of field
+// at (u,v), accumulated in float32
+func (ctx objSearchContext) distance32(field, object *FloatImage, u, v int) float32 {
+	var sum float32
+	r := object.Rect
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		f := field.Pix[field.PixOffset(u+r.Min.X, v+y):]
+		o := object.Pix[object.PixOffset(r.Min.X, y):]
+		for x := 0; x < r.Dx(); x++ {
+			d := float32(f[x]) - float32(o[x])
+			if d < 0 {
+				d = -d
+			}
+			if ctx.Mask != nil {
+				d *= float32(ctx.Mask.AlphaAt(r.Min.X+x, y).A) / 255
+			}
+			sum += d
+		}
+	}
+	return sum
+}
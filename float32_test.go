@@ -0,0 +1,32 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestFloat32(t *testing.T) {
+	object := randomRGBImage(10, 10)
+	field := frameWithObject(randomRGBImage(60, 50), object, image.Point{20, 30})
+	rect := validRect(field.Rect, object.Rect)
+	for _, opts := range []Options{
+		{Tolerance: 0.1, MinDist: 5},
+		{Tolerance: 0.1, MinDist: 5, ColorMode: COLORMODE_RGB, CombineMode: COMBINEMODE_MEAN, ChannelWeights: []float64{1, 2, 1}},
+		{Tolerance: 0.1, MinDist: 5, Mask: randomRGBImage(10, 10)},
+		{Tolerance: 0.1, MinDist: 5, Trim: 0.2},
+	} {
+		want := DistanceMap(field, object, rect, opts)
+		opts.Float32 = true
+		d := DistanceMap(field, object, rect, opts)
+		for i := range d.Pix {
+			if math.Abs(d.Pix[i]-want.Pix[i]) > 1e-5 {
+				t.Fatal("float32 distance error", i, d.Pix[i], want.Pix[i])
+			}
+		}
+		hits := SearchImage(field, object, rect, opts)
+		if len(hits) != 1 || hits[0].P != (image.Point{20, 30}) || hits[0].S != 0 {
+			t.Fatal("float32 hits error", hits)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package objsearch
+
+import (
+	"image"
+	"image/color"
+)
+
+// A single-channel image with float64 pixel values, for matching scientific
+// data, depth maps, and other inputs that should not be quantized to 8 bits.
+//
+// Pixel values may have any range. When used as an image.Image, values are
+// clamped to [0,1] and shown as 16-bit grayscale.
+type FloatImage struct {
+	// Pix holds the image's pixels. The pixel at (x,y) is at
+	// Pix[(y-Rect.Min.Y)*Stride+(x-Rect.Min.X)]
+	Pix []float64
+	// Stride is the Pix distance between vertically adjacent pixels
+	Stride int
+	Rect   image.Rectangle
+}
+
+// Returns a new FloatImage with the given bounds
+func NewFloatImage(r image.Rectangle) *FloatImage {
+	return &FloatImage{
+		Pix:    make([]float64, r.Dx()*r.Dy()),
+		Stride: r.Dx(),
+		Rect:   r,
+	}
+}
+
+// Returns a FloatImage with bounds (0,0)-(w,h) from rows, where rows[y][x] is
+// the pixel at (x,y). All rows must have the same length.
+func FloatImageFromRows(rows [][]float64) *FloatImage {
+	h := len(rows)
+	w := 0
+	if h > 0 {
+		w = len(rows[0])
+	}
+	p := NewFloatImage(image.Rect(0, 0, w, h))
+	for y := range rows {
+		if len(rows[y]) != w {
+			panic("rows have unequal lengths")
+		}
+		copy(p.Pix[y*p.Stride:], rows[y])
+	}
+	return p
+}
+
+// Returns a FloatImage with bounds (0,0)-(w,h) from a row-major float32
+// buffer of length w*h
+func FloatImageFromFloat32(pix []float32, w, h int) *FloatImage {
+	if len(pix) != w*h {
+		panic("buffer size does not match dimensions")
+	}
+	p := NewFloatImage(image.Rect(0, 0, w, h))
+	for i := range pix {
+		p.Pix[i] = float64(pix[i])
+	}
+	return p
+}
+
+// Returns img as a FloatImage, with pixel values scaled to [0,1]
+func FloatImageFromGray(img *image.Gray) *FloatImage {
+	p := NewFloatImage(img.Rect)
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			p.Pix[p.PixOffset(x, y)] = float64(img.Pix[img.PixOffset(x, y)]) / 255.0
+		}
+	}
+	return p
+}
+
+// Returns img as a FloatImage, with pixel values scaled to [0,1] without
+// losing precision, as for 16-bit scientific and microscopy images
+func FloatImageFromGray16(img *image.Gray16) *FloatImage {
+	p := NewFloatImage(img.Rect)
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			p.Pix[p.PixOffset(x, y)] = float64(img.Gray16At(x, y).Y) / 0xffff
+		}
+	}
+	return p
+}
+
+// Returns the index of the pixel at (x,y) in p.Pix
+func (p *FloatImage) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x - p.Rect.Min.X)
+}
+
+// Returns the value of the pixel at (x,y), or 0 if (x,y) is out of bounds
+func (p *FloatImage) FloatAt(x, y int) float64 {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return 0
+	}
+	return p.Pix[p.PixOffset(x, y)]
+}
+
+// Sets the value of the pixel at (x,y). Does nothing if (x,y) is out of
+// bounds.
+func (p *FloatImage) SetFloat(x, y int, v float64) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	p.Pix[p.PixOffset(x, y)] = v
+}
+
+func (p *FloatImage) Bounds() image.Rectangle {
+	return p.Rect
+}
+
+func (p *FloatImage) ColorModel() color.Model {
+	return color.Gray16Model
+}
+
+func (p *FloatImage) At(x, y int) color.Color {
+	v := p.FloatAt(x, y)
+	if v < 0 {
+		v = 0
+	} else if v > 1 {
+		v = 1
+	}
+	return color.Gray16{uint16(v*0xffff + 0.5)}
+}
@@ -0,0 +1,168 @@
+package objsearch
+
+import (
+	"image"
+	"image/draw"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func randomFloatImage(w, h int, scale float64) *FloatImage {
+	p := NewFloatImage(image.Rect(0, 0, w, h))
+	for i := range p.Pix {
+		p.Pix[i] = rand.Float64() * scale
+	}
+	return p
+}
+
+// return a w by h image of values drawn from rnd in [min, max), so that
+// scenes are reproducible
+func seededFloatImage(rnd *rand.Rand, w, h int, min, max float64) *FloatImage {
+	p := NewFloatImage(image.Rect(0, 0, w, h))
+	for i := range p.Pix {
+		p.Pix[i] = min + rnd.Float64()*(max-min)
+	}
+	return p
+}
+
+// set the pixels of img in r to v
+func fillFloat(img *FloatImage, r image.Rectangle, v float64) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.Pix[img.PixOffset(x, y)] = v
+		}
+	}
+}
+
+// copy object into img with its top-left corner at p, adding offset to each
+// pixel
+func pasteFloat(img, object *FloatImage, p image.Point, offset float64) {
+	for y := 0; y < object.Rect.Dy(); y++ {
+		for x := 0; x < object.Rect.Dx(); x++ {
+			img.Pix[img.PixOffset(p.X+x, p.Y+y)] = object.FloatAt(x, y) + offset
+		}
+	}
+}
+
+// test that the best match for object in field is decoy, and the object's
+// degraded copy at p scores worse than maxScore, unless enable sets the
+// option under test, when the best match is at p scoring better than
+// maxScore. Scores are SCOREMODE_L1_ABSOLUTE, so that they are comparable
+// with and without the option.
+func testOptionFinds(t *testing.T, field, object *FloatImage, p, decoy image.Point, maxScore float64, enable func(*Options)) {
+	t.Helper()
+	opts := Options{Tolerance: math.Inf(1), ScoreMode: SCOREMODE_L1_ABSOLUTE, HitMode: HITMODE_BESTK, K: 1}
+	rect := validRect(field.Rect, object.Rect)
+	if h := SearchFloat(field, object, rect, opts); len(h) != 1 || h[0].P != decoy {
+		t.Fatal("expected the decoy without the option", h)
+	}
+	if h := SearchFloat(field, object, image.Rectangle{p, p.Add(image.Point{1, 1})}, opts); len(h) != 1 || h[0].S < maxScore {
+		t.Fatal("degraded object matches without the option", h)
+	}
+	enable(&opts)
+	h := SearchFloat(field, object, rect, opts)
+	if len(h) != 1 || h[0].P != p || h[0].S >= maxScore {
+		t.Fatal("option did not find the object", h)
+	}
+}
+
+// test that objects can be found in floating point fields of arbitrary range
+func TestSearchFloat(t *testing.T) {
+	field := randomFloatImage(50, 50, 1000)
+	object := NewFloatImage(image.Rect(0, 0, 8, 8))
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 8; y++ {
+			object.SetFloat(x, y, field.FloatAt(x+17, y+23))
+		}
+	}
+	h := SearchFloat(field, object, image.Rect(0, 0, 42, 42), Options{
+		Tolerance: 0.1,
+		MinDist:   8,
+	})
+	if len(h) != 1 || h[0] != (Hit{image.Point{17, 23}, 0}) {
+		t.Error(h)
+		t.Fatal("SearchFloat error")
+	}
+	rows := [][]float64{{1, 2, 3}, {4, 5, 6}}
+	if p := FloatImageFromRows(rows); p.FloatAt(2, 1) != 6 || p.Bounds() != image.Rect(0, 0, 3, 2) {
+		t.Fatal("FloatImageFromRows error")
+	}
+}
+
+// test searching a 5-channel field
+func TestSearchPlanes(t *testing.T) {
+	var field, object []*FloatImage
+	for i := 0; i < 5; i++ {
+		f := randomFloatImage(40, 40, 1)
+		o := NewFloatImage(image.Rect(0, 0, 6, 6))
+		for x := 0; x < 6; x++ {
+			for y := 0; y < 6; y++ {
+				o.SetFloat(x, y, f.FloatAt(x+3, y+30))
+			}
+		}
+		field, object = append(field, f), append(object, o)
+	}
+	for _, mode := range []CombineMode{COMBINEMODE_MAX, COMBINEMODE_MEAN} {
+		h := SearchPlanes(field, object, image.Rect(0, 0, 34, 34), Options{
+			Tolerance:   0.1,
+			MinDist:     6,
+			CombineMode: mode,
+		})
+		if len(h) != 1 || h[0] != (Hit{image.Point{3, 30}, 0}) {
+			t.Error(mode, h)
+			t.Fatal("SearchPlanes error")
+		}
+	}
+}
+
+// test that depth rejects a lookalike object at the wrong depth
+func TestSearchRGBD(t *testing.T) {
+	field := randomRGBImage(60, 60)
+	depth := NewFloatImage(field.Rect)
+	for i := range depth.Pix {
+		depth.Pix[i] = 2000 // background, in mm
+	}
+	object := randomRGBImage(10, 10)
+	objectDepth := NewFloatImage(object.Rect)
+	for i := range objectDepth.Pix {
+		objectDepth.Pix[i] = 500
+	}
+	// the real object, at the right depth, with a noisy copy of its texture
+	noisy := image.NewRGBA(object.Rect)
+	copy(noisy.Pix, object.Pix)
+	for i := 0; i < len(noisy.Pix); i += 17 {
+		noisy.Pix[i] ^= 0x40
+	}
+	draw.Draw(field, object.Rect.Add(image.Point{5, 5}), noisy, image.ZP, draw.Src)
+	for x := 5; x < 15; x++ {
+		for y := 5; y < 15; y++ {
+			depth.SetFloat(x, y, 500)
+		}
+	}
+	// an exact lookalike printed on the background
+	draw.Draw(field, object.Rect.Add(image.Point{40, 40}), object, image.ZP, draw.Src)
+	opts := Options{
+		Tolerance:   0.5,
+		MinDist:     10,
+		ColorMode:   COLORMODE_RGB,
+		CombineMode: COMBINEMODE_MEAN,
+		DepthScale:  1000,
+		DepthWeight: 3,
+	}
+	h := SearchRGBD(field, depth, object, objectDepth, image.Rect(0, 0, 50, 50), opts)
+	if len(h) == 0 || h[0].P != (image.Point{5, 5}) {
+		t.Error(h)
+		t.Fatal("SearchRGBD error")
+	}
+	// the color channels are weighted as given, here leaving only depth
+	opts.ChannelWeights = []float64{0, 0, 0}
+	if h := SearchRGBD(field, depth, object, objectDepth, image.Rect(0, 0, 50, 50), opts); len(h) != 1 || h[0] != (Hit{image.Point{5, 5}, 0}) {
+		t.Fatal("SearchRGBD channel weights error", h)
+	}
+	// the depth channel is padded with the color channels
+	opts.ChannelWeights, opts.Border = nil, BORDERMODE_CLAMP
+	if h := SearchRGBD(field, depth, object, objectDepth, image.Rect(-9, -9, 60, 60), opts); len(h) == 0 || h[0].P != (image.Point{5, 5}) {
+		t.Fatal("SearchRGBD border error", h)
+	}
+}
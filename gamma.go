@@ -0,0 +1,29 @@
+package objsearch
+
+import "math"
+
+// return planes with gamma-encoded values in [0,1] decoded to linear light,
+// with the sRGB transfer function, or as v^gamma if gamma is not zero
+func linearizePlanes(planes []*FloatImage, gamma float64) []*FloatImage {
+	decode := func(v float64) float64 {
+		v = math.Max(0, math.Min(1, v))
+		if gamma != 0 {
+			return math.Pow(v, gamma)
+		}
+		if v <= 0.04045 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+	linear := make([]*FloatImage, len(planes))
+	for i, p := range planes {
+		q := NewFloatImage(p.Rect)
+		for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+			for x := p.Rect.Min.X; x < p.Rect.Max.X; x++ {
+				q.Pix[q.PixOffset(x, y)] = decode(p.Pix[p.PixOffset(x, y)])
+			}
+		}
+		linear[i] = q
+	}
+	return linear
+}
@@ -0,0 +1,35 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+// test that differences are weighed in linear light with Options.Linearize
+func TestLinearize(t *testing.T) {
+	object := NewFloatImage(image.Rect(0, 0, 10, 10))
+	fillFloat(object, object.Rect, 0.5)
+	field := NewFloatImage(image.Rect(0, 0, 40, 20))
+	fillFloat(field, field.Rect, 1)
+	// darker is further in encoded values, but nearer in linear light
+	fillFloat(field, image.Rect(5, 5, 15, 15), 0.38)
+	fillFloat(field, image.Rect(25, 5, 35, 15), 0.61)
+	rect := validRect(field.Rect, object.Rect)
+	opts := Options{Tolerance: 1, HitMode: HITMODE_BESTK, K: 1}
+	if h := SearchFloat(field, object, rect, opts); len(h) != 1 || h[0].P != (image.Point{25, 5}) {
+		t.Fatal("encoded search error", h)
+	}
+	opts.Linearize = true
+	if h := SearchFloat(field, object, rect, opts); len(h) != 1 || h[0].P != (image.Point{5, 5}) {
+		t.Fatal("linear search error", h)
+	}
+	p := NewFloatImage(image.Rect(0, 0, 1, 1))
+	p.Pix[0] = 0.5
+	if v := linearizePlanes([]*FloatImage{p}, 0)[0].Pix[0]; math.Abs(v-0.214041) > 1e-6 {
+		t.Fatal("sRGB decoding error", v)
+	}
+	if v := linearizePlanes([]*FloatImage{p}, 2)[0].Pix[0]; v != 0.25 {
+		t.Fatal("gamma decoding error", v)
+	}
+}
@@ -0,0 +1,107 @@
+package objsearch
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+)
+
+// Returns the mean absolute per-pixel difference between object and field,
+// combined across channels according to opts, at each top-left corner in
+// rect. This is the distance surface from which Search's hits are taken. If
+// rect is empty, every position at which object lies entirely within field
+// is used. The returned image has bounds rect, clamped as SearchImage clamps
+// it.
+func DistanceMap(field, object image.Image, rect image.Rectangle, opts Options) *FloatImage {
+	if rect.Empty() {
+		rect = searchableRect(field.Bounds(), object.Bounds(), opts.Border)
+	}
+	ctx, dist := searchDistances(field, object, rect, opts)
+	return &FloatImage{Pix: dist, Stride: ctx.SearchRect.Dx(), Rect: ctx.SearchRect}
+}
+
+// Maps values in [0,1] to colors for Heatmap
+type Colormap int
+
+const (
+	COLORMAP_VIRIDIS Colormap = iota
+	COLORMAP_INFERNO
+	COLORMAP_GRAY
+)
+
+// evenly spaced stops of the matplotlib colormaps
+var colormapStops = map[Colormap][]color.RGBA{
+	COLORMAP_VIRIDIS: {
+		{0x44, 0x01, 0x54, 255}, {0x47, 0x2d, 0x7b, 255}, {0x3b, 0x52, 0x8b, 255},
+		{0x2c, 0x72, 0x8e, 255}, {0x21, 0x91, 0x8c, 255}, {0x28, 0xae, 0x80, 255},
+		{0x5e, 0xc9, 0x62, 255}, {0xad, 0xdc, 0x30, 255}, {0xfd, 0xe7, 0x25, 255},
+	},
+	COLORMAP_INFERNO: {
+		{0x00, 0x00, 0x04, 255}, {0x1f, 0x0c, 0x48, 255}, {0x55, 0x0f, 0x6d, 255},
+		{0x88, 0x22, 0x6a, 255}, {0xba, 0x36, 0x55, 255}, {0xe3, 0x59, 0x33, 255},
+		{0xf9, 0x8e, 0x09, 255}, {0xf6, 0xd7, 0x46, 255}, {0xfc, 0xff, 0xa4, 255},
+	},
+	COLORMAP_GRAY: {
+		{0, 0, 0, 255}, {255, 255, 255, 255},
+	},
+}
+
+// Returns the color of v, which is clamped to [0,1]
+func (c Colormap) Color(v float64) color.RGBA {
+	stops, ok := colormapStops[c]
+	if !ok {
+		panic("unknown colormap")
+	}
+	v = math.Max(0, math.Min(1, v)) * float64(len(stops)-1)
+	i := int(v)
+	if i == len(stops)-1 {
+		return stops[i]
+	}
+	f := v - float64(i)
+	lerp := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + f*(float64(b)-float64(a))))
+	}
+	a, b := stops[i], stops[i+1]
+	return color.RGBA{lerp(a.R, b.R), lerp(a.G, b.G), lerp(a.B, b.B), 255}
+}
+
+// Renders d, e.g. as returned by DistanceMap, as an image with cmap. Values
+// are scaled so that the smallest maps to 0 and the largest to 1. Each of
+// hits is marked with a cross, in white for COLORMAP_GRAY and red otherwise.
+func Heatmap(d *FloatImage, cmap Colormap, hits []Hit) *image.RGBA {
+	min, max := math.Inf(1), math.Inf(-1)
+	for y := d.Rect.Min.Y; y < d.Rect.Max.Y; y++ {
+		for x := d.Rect.Min.X; x < d.Rect.Max.X; x++ {
+			v := d.FloatAt(x, y)
+			min, max = math.Min(min, v), math.Max(max, v)
+		}
+	}
+	scale := 0.0
+	if max > min {
+		scale = 1 / (max - min)
+	}
+	img := image.NewRGBA(d.Rect)
+	for y := d.Rect.Min.Y; y < d.Rect.Max.Y; y++ {
+		for x := d.Rect.Min.X; x < d.Rect.Max.X; x++ {
+			img.SetRGBA(x, y, cmap.Color((d.FloatAt(x, y)-min)*scale))
+		}
+	}
+	marker := color.RGBA{255, 0, 0, 255}
+	if cmap == COLORMAP_GRAY {
+		marker = color.RGBA{255, 255, 255, 255}
+	}
+	for _, h := range hits {
+		for i := -2; i <= 2; i++ {
+			img.Set(h.P.X+i, h.P.Y, marker)
+			img.Set(h.P.X, h.P.Y+i, marker)
+		}
+	}
+	return img
+}
+
+// Writes Heatmap(d, cmap, hits) to w as a PNG
+func WriteHeatmapPNG(w io.Writer, d *FloatImage, cmap Colormap, hits []Hit) error {
+	return png.Encode(w, Heatmap(d, cmap, hits))
+}
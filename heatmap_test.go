@@ -0,0 +1,50 @@
+package objsearch
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestDistanceMap(t *testing.T) {
+	object := randomRGBImage(8, 8)
+	field := frameWithObject(randomRGBImage(40, 30), object, image.Point{11, 7})
+	d := DistanceMap(field, object, image.Rectangle{}, Options{})
+	if d.Rect != image.Rect(0, 0, 33, 23) || d.FloatAt(11, 7) != 0 {
+		t.Fatal("distance map error", d.Rect)
+	}
+	r := image.Rect(5, 5, 15, 10)
+	if s := DistanceMap(field, object, r, Options{}); s.Rect != r || s.FloatAt(11, 7) != 0 || s.FloatAt(5, 5) != d.FloatAt(5, 5) {
+		t.Fatal("partial distance map error", s.Rect)
+	}
+}
+
+func TestHeatmap(t *testing.T) {
+	if COLORMAP_GRAY.Color(0.5) != (color.RGBA{128, 128, 128, 255}) || COLORMAP_VIRIDIS.Color(2) != COLORMAP_VIRIDIS.Color(1) {
+		t.Fatal("colormap error")
+	}
+	d := FloatImageFromRows([][]float64{
+		{4, 2, 4, 4, 4},
+		{4, 4, 4, 4, 4},
+		{4, 4, 4, 4, 4},
+		{4, 4, 4, 4, 4},
+		{4, 4, 4, 4, 3},
+	})
+	img := Heatmap(d, COLORMAP_INFERNO, nil)
+	if img.RGBAAt(1, 0) != COLORMAP_INFERNO.Color(0) || img.RGBAAt(0, 0) != COLORMAP_INFERNO.Color(1) || img.RGBAAt(4, 4) != COLORMAP_INFERNO.Color(0.5) {
+		t.Fatal("heatmap error")
+	}
+	img = Heatmap(d, COLORMAP_GRAY, []Hit{{image.Point{1, 0}, 0}})
+	if img.RGBAAt(1, 0) != (color.RGBA{255, 255, 255, 255}) || img.RGBAAt(1, 2) != (color.RGBA{255, 255, 255, 255}) {
+		t.Fatal("hit marker error")
+	}
+	b := &bytes.Buffer{}
+	if err := WriteHeatmapPNG(b, d, COLORMAP_VIRIDIS, nil); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := png.Decode(b); err != nil || p.Bounds() != d.Rect {
+		t.Fatal("heatmap PNG error", err)
+	}
+}
@@ -0,0 +1,40 @@
+package objsearch
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Returns the window of size objectSize at hit h in field as a standalone
+// image, with its top-left corner at the origin, e.g. for logging, manual
+// review or a secondary classifier. Parts of the window outside field are
+// transparent.
+func HitCrop(field image.Image, h Hit, objectSize image.Point) *image.RGBA {
+	return cropWindow(field, image.Rectangle{h.P, h.P.Add(objectSize)})
+}
+
+// Returns the window of each of hits, as HitCrop does
+func HitCrops(field image.Image, hits []Hit, objectSize image.Point) []*image.RGBA {
+	crops := make([]*image.RGBA, len(hits))
+	for i, h := range hits {
+		crops[i] = HitCrop(field, h, objectSize)
+	}
+	return crops
+}
+
+// Returns the window of each of hits, as HitCrop does, with the bounds of
+// the object registered in set under the hit's label
+func LabeledHitCrops(field image.Image, hits []LabeledHit, set *TemplateSet) []*image.RGBA {
+	crops := make([]*image.RGBA, len(hits))
+	for i, h := range hits {
+		crops[i] = cropWindow(field, set.Searcher(h.Label).Object.Bounds().Add(h.P))
+	}
+	return crops
+}
+
+// return the window r of img, moved to the origin
+func cropWindow(img image.Image, r image.Rectangle) *image.RGBA {
+	c := image.NewRGBA(image.Rectangle{Max: r.Size()})
+	draw.Draw(c, c.Rect, img, r.Min, draw.Src)
+	return c
+}
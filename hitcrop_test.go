@@ -0,0 +1,26 @@
+package objsearch
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+func TestHitCrop(t *testing.T) {
+	object := randomRGBImage(6, 4)
+	field := frameWithObject(randomRGBImage(30, 20), object, image.Point{7, 9})
+	hits := []Hit{{P: image.Point{7, 9}}, {P: image.Point{27, 18}}}
+	crops := HitCrops(field, hits, object.Rect.Size())
+	if len(crops) != 2 || !reflect.DeepEqual(crops[0], object) {
+		t.Fatal("crop error")
+	}
+	// the part of the window beyond the field is transparent
+	if c := crops[1]; c.Rect != object.Rect || c.RGBAAt(2, 1).A != 255 || c.RGBAAt(3, 1).A != 0 || c.RGBAAt(2, 2).A != 0 {
+		t.Fatal("edge crop error")
+	}
+	set := NewTemplateSet(Options{})
+	set.Add("a", object)
+	if c := LabeledHitCrops(field, []LabeledHit{{hits[0], "a"}}, set); !reflect.DeepEqual(c[0], object) {
+		t.Fatal("labeled crop error")
+	}
+}
@@ -0,0 +1,97 @@
+package objsearch
+
+import (
+	"image"
+	"sort"
+)
+
+// Returns the hits in hits for which keep returns true. The order of hits is
+// preserved.
+func FilterHits(hits []Hit, keep func(Hit) bool) (r []Hit) {
+	for _, h := range hits {
+		if keep(h) {
+			r = append(r, h)
+		}
+	}
+	return
+}
+
+// Returns a predicate accepting hits with score below s, for use with
+// FilterHits
+func ScoreBelow(s float64) func(Hit) bool {
+	return func(h Hit) bool {
+		return h.S < s
+	}
+}
+
+// Returns the hits in a that are not within tol pixels of any hit in b.
+//
+// Distances are measured with Hit.Distance, so with tol 0 only hits at
+// exactly the same location are considered equal. The order of a is
+// preserved.
+func HitsDifference(a, b []Hit, tol int) []Hit {
+	idx := NewHitIndex(b, tol+1)
+	return FilterHits(a, func(h Hit) bool {
+		return len(idx.HitsNear(h.P, tol)) == 0
+	})
+}
+
+// Returns the hits in a that are within tol pixels of some hit in b. The
+// order of a is preserved, and the hits returned are those from a.
+func HitsIntersection(a, b []Hit, tol int) []Hit {
+	idx := NewHitIndex(b, tol+1)
+	return FilterHits(a, func(h Hit) bool {
+		return len(idx.HitsNear(h.P, tol)) > 0
+	})
+}
+
+// Sorts hits by ascending score, best hits first. This is the order in which
+// Search returns hits.
+func SortByScore(hits []Hit) {
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].S < hits[j].S
+	})
+}
+
+// Sorts hits in raster order: top to bottom, then left to right
+func SortRaster(hits []Hit) {
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].P.Y != hits[j].P.Y {
+			return hits[i].P.Y < hits[j].P.Y
+		}
+		return hits[i].P.X < hits[j].P.X
+	})
+}
+
+// Sorts hits by ascending distance from p, as measured by Hit.Distance. Hits
+// at equal distance are sorted by score.
+func SortByDistanceFrom(hits []Hit, p image.Point) {
+	q := Hit{P: p}
+	sort.SliceStable(hits, func(i, j int) bool {
+		di, dj := hits[i].Distance(q), hits[j].Distance(q)
+		if di != dj {
+			return di < dj
+		}
+		return hits[i].S < hits[j].S
+	})
+}
+
+// return the best-scoring hits such that no two are less than minDist apart,
+// sorted by score
+func suppressHits(hits []Hit, minDist int) (r []Hit) {
+	sorted := append([]Hit{}, hits...)
+	SortByScore(sorted)
+	for _, h := range sorted {
+		keep := true
+		for j := range r {
+			if r[j].Distance(h) < minDist {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			r = append(r, h)
+		}
+	}
+	return
+}
@@ -0,0 +1,85 @@
+package objsearch
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+)
+
+func TestHitSetOperations(t *testing.T) {
+	prev := []Hit{
+		{image.Point{10, 10}, 0.1},
+		{image.Point{50, 50}, 0.2},
+	}
+	cur := []Hit{
+		{image.Point{11, 9}, 0.1},  // moved slightly
+		{image.Point{80, 20}, 0.3}, // new
+	}
+	d := HitsDifference(cur, prev, 2)
+	if len(d) != 1 || d[0] != cur[1] {
+		t.Fatal("HitsDifference error", d)
+	}
+	i := HitsIntersection(cur, prev, 2)
+	if len(i) != 1 || i[0] != cur[0] {
+		t.Fatal("HitsIntersection error", i)
+	}
+	if len(HitsIntersection(cur, prev, 0)) != 0 {
+		t.Fatal("HitsIntersection tolerance error")
+	}
+	f := FilterHits(cur, ScoreBelow(0.2))
+	if len(f) != 1 || f[0] != cur[0] {
+		t.Fatal("FilterHits error", f)
+	}
+}
+
+func TestHitIndex(t *testing.T) {
+	hits := make([]Hit, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		hits = append(hits, Hit{image.Point{rand.Intn(500) - 250, rand.Intn(500) - 250}, rand.Float64()})
+	}
+	idx := NewHitIndex(hits, 16)
+	if idx.Len() != len(hits) {
+		t.Fatal("HitIndex length error")
+	}
+	for i := 0; i < 100; i++ {
+		p := image.Point{rand.Intn(800) - 400, rand.Intn(800) - 400}
+		// compare against brute force
+		n, ok := idx.NearestHit(p)
+		if !ok {
+			t.Fatal("NearestHit error")
+		}
+		q := Hit{P: p}
+		for _, h := range hits {
+			if h.Distance(q) < n.Distance(q) {
+				t.Fatal("NearestHit error", p, n, h)
+			}
+		}
+		r := image.Rect(p.X, p.Y, p.X+40, p.Y+30)
+		if len(idx.HitsInRect(r)) != len(FilterHits(hits, func(h Hit) bool { return h.P.In(r) })) {
+			t.Fatal("HitsInRect error")
+		}
+	}
+	if _, ok := NewHitIndex(nil, 16).NearestHit(image.Point{}); ok {
+		t.Fatal("NearestHit on empty index error")
+	}
+}
+
+func TestSortHits(t *testing.T) {
+	hits := []Hit{
+		{image.Point{30, 10}, 0.3},
+		{image.Point{10, 20}, 0.1},
+		{image.Point{20, 10}, 0.2},
+	}
+	SortRaster(hits)
+	if hits[0].S != 0.2 || hits[1].S != 0.3 || hits[2].S != 0.1 {
+		t.Fatal("SortRaster error", hits)
+	}
+	SortByDistanceFrom(hits, image.Point{28, 12})
+	if hits[0].S != 0.3 || hits[1].S != 0.2 || hits[2].S != 0.1 {
+		t.Fatal("SortByDistanceFrom error", hits)
+	}
+	SortByScore(hits)
+	if hits[0].S != 0.1 || hits[1].S != 0.2 || hits[2].S != 0.3 {
+		t.Fatal("SortByScore error", hits)
+	}
+}
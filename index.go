@@ -0,0 +1,126 @@
+package objsearch
+
+import (
+	"image"
+)
+
+// A spatial index over a slice of Hits, supporting fast neighborhood queries.
+//
+// Hits are bucketed by location into a uniform grid of square cells. A cell
+// size near the typical query radius gives the best performance.
+type HitIndex struct {
+	cellSize int
+	cells    map[image.Point][]Hit
+	// bounds of occupied cells, in cell coordinates
+	bounds image.Rectangle
+	n      int
+}
+
+// Returns a HitIndex over hits with the given grid cell size in pixels.
+func NewHitIndex(hits []Hit, cellSize int) *HitIndex {
+	if cellSize <= 0 {
+		panic("cellSize <= 0")
+	}
+	idx := &HitIndex{
+		cellSize: cellSize,
+		cells:    make(map[image.Point][]Hit),
+	}
+	for _, h := range hits {
+		idx.Add(h)
+	}
+	return idx
+}
+
+// Adds h to the index
+func (idx *HitIndex) Add(h Hit) {
+	c := idx.cell(h.P)
+	cr := image.Rectangle{c, c.Add(image.Point{1, 1})}
+	if idx.n == 0 {
+		idx.bounds = cr
+	} else {
+		idx.bounds = idx.bounds.Union(cr)
+	}
+	idx.cells[c] = append(idx.cells[c], h)
+	idx.n++
+}
+
+// Returns the number of hits in the index
+func (idx *HitIndex) Len() int {
+	return idx.n
+}
+
+// Returns all hits located inside r, in no particular order
+func (idx *HitIndex) HitsInRect(r image.Rectangle) (hits []Hit) {
+	if r.Empty() {
+		return
+	}
+	min := idx.cell(r.Min)
+	max := idx.cell(r.Max.Sub(image.Point{1, 1}))
+	for cx := min.X; cx <= max.X; cx++ {
+		for cy := min.Y; cy <= max.Y; cy++ {
+			for _, h := range idx.cells[image.Point{cx, cy}] {
+				if h.P.In(r) {
+					hits = append(hits, h)
+				}
+			}
+		}
+	}
+	return
+}
+
+// Returns all hits at most d pixels from p, as measured by Hit.Distance
+func (idx *HitIndex) HitsNear(p image.Point, d int) []Hit {
+	return idx.HitsInRect(image.Rect(p.X-d, p.Y-d, p.X+d+1, p.Y+d+1))
+}
+
+// Returns the hit nearest to p, as measured by Hit.Distance. Ties are broken
+// by lower score. ok is false if the index is empty.
+func (idx *HitIndex) NearestHit(p image.Point) (nearest Hit, ok bool) {
+	if idx.n == 0 {
+		return
+	}
+	q := Hit{P: p}
+	best := -1
+	c := idx.cell(p)
+	// search rings of cells of increasing radius k around c
+	for k := 0; ; k++ {
+		if best >= 0 && (k-1)*idx.cellSize >= best {
+			// no hit in this or any larger ring can be nearer
+			break
+		}
+		if k > 0 && idx.bounds.In(image.Rect(c.X-k+1, c.Y-k+1, c.X+k, c.Y+k)) {
+			// previous rings already covered every occupied cell
+			break
+		}
+		ring := image.Rect(c.X-k, c.Y-k, c.X+k+1, c.Y+k+1)
+		for cx := ring.Min.X; cx < ring.Max.X; cx++ {
+			for cy := ring.Min.Y; cy < ring.Max.Y; cy++ {
+				if cx != ring.Min.X && cx != ring.Max.X-1 && cy != ring.Min.Y && cy != ring.Max.Y-1 {
+					// interior cell, visited in a previous ring
+					continue
+				}
+				for _, h := range idx.cells[image.Point{cx, cy}] {
+					d := h.Distance(q)
+					if best < 0 || d < best || (d == best && h.S < nearest.S) {
+						nearest, best = h, d
+					}
+				}
+			}
+		}
+	}
+	return nearest, true
+}
+
+// return the grid cell containing p
+func (idx *HitIndex) cell(p image.Point) image.Point {
+	return image.Point{floorDiv(p.X, idx.cellSize), floorDiv(p.Y, idx.cellSize)}
+}
+
+// integer division rounding towards negative infinity
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
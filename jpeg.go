@@ -0,0 +1,44 @@
+package objsearch
+
+import "image"
+
+// weight of field pixels on either side of a JPEG block boundary, relative
+// to other pixels
+const jpegBoundaryWeight = 0.25
+
+// return the weight of the difference at field pixel (x,y), lowered on
+// either side of the boundaries of the 8x8 blocks starting at
+// ctx.BlockOrigin, where JPEG blocking artifacts concentrate
+func (ctx objSearchContext) jpegWeight(x, y int) float64 {
+	bx, by := (x-ctx.BlockOrigin.X)&7, (y-ctx.BlockOrigin.Y)&7
+	if bx == 0 || bx == 7 || by == 0 || by == 7 {
+		return jpegBoundaryWeight
+	}
+	return 1
+}
+
+// return RGB planes, as returned by colorPlanes, as luma and chroma planes,
+// with the chroma low-passed over the part of field searched for object at
+// each point of rect, so that high-frequency chroma, which JPEG discards,
+// is not compared
+func jpegPlanes(field, object []*FloatImage, rect image.Rectangle) ([]*FloatImage, []*FloatImage) {
+	field, object = ycbcrPlanes(field), ycbcrPlanes(object)
+	fc, oc := blurInputs(field[1:], object[1:], rect, 1)
+	return append(field[:1], fc...), append(object[:1], oc...)
+}
+
+// return the JFIF Y, Cb and Cr planes of R, G and B planes, with chroma
+// centered on 0.5
+func ycbcrPlanes(rgb []*FloatImage) []*FloatImage {
+	if len(rgb) != 3 {
+		panic("internal error")
+	}
+	ycc := []*FloatImage{NewFloatImage(rgb[0].Rect), NewFloatImage(rgb[0].Rect), NewFloatImage(rgb[0].Rect)}
+	for i := range rgb[0].Pix {
+		r, g, b := rgb[0].Pix[i], rgb[1].Pix[i], rgb[2].Pix[i]
+		ycc[0].Pix[i] = 0.299*r + 0.587*g + 0.114*b
+		ycc[1].Pix[i] = -0.168736*r - 0.331264*g + 0.5*b + 0.5
+		ycc[2].Pix[i] = 0.5*r - 0.418688*g - 0.081312*b + 0.5
+	}
+	return ycc
+}
@@ -0,0 +1,58 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/hypoactiv/objsearch/objsearchtest"
+)
+
+// test that a lossless template stands out more in a recompressed field
+// with Options.JPEGTolerant
+func TestJPEGTolerant(t *testing.T) {
+	g := objsearchtest.NewGenerator(1)
+	lossless := g.Clutter(96, 96, 40)
+	g.AddNoise(lossless, 8)
+	p := image.Point{13, 21}
+	object := crop(lossless, image.Rectangle{p, p.Add(image.Point{20, 20})})
+	field, err := objsearchtest.Recompress(lossless, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// distance at p relative to the median distance
+	separation := func(opts Options) float64 {
+		r := SearchResult(field, object, image.Rectangle{}, opts)
+		d := append([]float64(nil), r.Scores.Pix...)
+		sort.Float64s(d)
+		if best := r.Hits; len(best) != 1 || best[0].P != p {
+			t.Fatal("search error", best)
+		}
+		return r.Scores.FloatAt(p.X, p.Y) / d[len(d)/2]
+	}
+	opts := Options{Tolerance: 1, ColorMode: COLORMODE_RGB, CombineMode: COMBINEMODE_MEAN, HitMode: HITMODE_BESTK, K: 1}
+	plain := separation(opts)
+	opts.JPEGTolerant = true
+	tolerant := separation(opts)
+	if tolerant >= plain {
+		t.Fatal("JPEG tolerance did not help", plain, tolerant)
+	}
+}
+
+func TestJPEGWeight(t *testing.T) {
+	ctx := objSearchContext{BlockOrigin: image.Point{3, -2}}
+	if ctx.jpegWeight(3, 3) != jpegBoundaryWeight || ctx.jpegWeight(10, 3) != jpegBoundaryWeight ||
+		ctx.jpegWeight(5, 5) != jpegBoundaryWeight || ctx.jpegWeight(5, 4) != 1 {
+		t.Fatal("block boundary weights error")
+	}
+	// gray pixels have neutral chroma
+	gray := NewFloatImage(image.Rect(0, 0, 1, 1))
+	gray.Pix[0] = 0.4
+	ycc := ycbcrPlanes([]*FloatImage{gray, gray, gray})
+	for i, want := range []float64{0.4, 0.5, 0.5} {
+		if math.Abs(ycc[i].Pix[0]-want) > 1e-9 {
+			t.Fatal("ycbcr error", i, ycc[i].Pix[0])
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package objsearch
+
+// A constant-velocity Kalman filter tracking one coordinate, with state
+// (position, velocity) and a time step of one frame
+type kalman1 struct {
+	x, v float64
+	// state covariance
+	p [2][2]float64
+}
+
+// returns a filter at position x with zero but uncertain velocity, where r
+// is the measurement noise variance and vv the velocity variance
+func newKalman1(x, r, vv float64) kalman1 {
+	k := kalman1{x: x}
+	k.p[0][0] = r
+	k.p[1][1] = vv
+	return k
+}
+
+// advance the state by one frame, with q the variance of the acceleration
+// process noise
+func (k *kalman1) predict(q float64) {
+	k.x += k.v
+	// P = F P F' + Q, with F = [1 1; 0 1] and Q the discrete white noise
+	// acceleration model
+	p := k.p
+	k.p[0][0] = p[0][0] + p[0][1] + p[1][0] + p[1][1] + q/4
+	k.p[0][1] = p[0][1] + p[1][1] + q/2
+	k.p[1][0] = p[1][0] + p[1][1] + q/2
+	k.p[1][1] = p[1][1] + q
+}
+
+// correct the state with a position measurement z, with r the measurement
+// noise variance. If the predicted position and the measurement are both
+// certain, as with zero noise variances, the measurement is taken.
+func (k *kalman1) update(z, r float64) {
+	y := z - k.x
+	s := k.p[0][0] + r
+	if s <= 0 {
+		k.x = z
+		return
+	}
+	k0, k1 := k.p[0][0]/s, k.p[1][0]/s
+	k.x += k0 * y
+	k.v += k1 * y
+	// P = (I - K H) P, with H = [1 0]
+	p := k.p
+	k.p[0][0] = (1 - k0) * p[0][0]
+	k.p[0][1] = (1 - k0) * p[0][1]
+	k.p[1][0] = p[1][0] - k1*p[0][0]
+	k.p[1][1] = p[1][1] - k1*p[0][1]
+}
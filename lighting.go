@@ -0,0 +1,33 @@
+package objsearch
+
+import "image"
+
+// return planes less the mean of the (2r+1)x(2r+1) neighbourhood of each
+// pixel, within the plane's bounds, plus 0.5, removing smooth illumination
+// gradients
+func flattenLighting(planes []*FloatImage, r int) []*FloatImage {
+	if r < 1 {
+		panic("lighting radius less than 1")
+	}
+	flat := make([]*FloatImage, len(planes))
+	for i, p := range planes {
+		sum, _ := integralImages(p)
+		w := p.Rect.Dx() + 1
+		// sum of the pixels in n, relative to p.Rect.Min
+		area := func(n image.Rectangle) float64 {
+			return sum[n.Max.Y*w+n.Max.X] - sum[n.Min.Y*w+n.Max.X] - sum[n.Max.Y*w+n.Min.X] + sum[n.Min.Y*w+n.Min.X]
+		}
+		bounds := image.Rect(0, 0, p.Rect.Dx(), p.Rect.Dy())
+		q := NewFloatImage(p.Rect)
+		for y := 0; y < p.Rect.Dy(); y++ {
+			for x := 0; x < p.Rect.Dx(); x++ {
+				n := image.Rect(x-r, y-r, x+r+1, y+r+1).Intersect(bounds)
+				mean := area(n) / float64(n.Dx()*n.Dy())
+				px, py := p.Rect.Min.X+x, p.Rect.Min.Y+y
+				q.Pix[q.PixOffset(px, py)] = p.Pix[p.PixOffset(px, py)] - mean + 0.5
+			}
+		}
+		flat[i] = q
+	}
+	return flat
+}
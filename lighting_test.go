@@ -0,0 +1,37 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// test that an object is found under an illumination gradient with
+// Options.LightingRadius, rather than a plain patch
+func TestLightingRadius(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	object := seededFloatImage(rnd, 16, 16, 0, 1)
+	field := seededFloatImage(rnd, 80, 80, 0, 1)
+	p, decoy := image.Point{56, 30}, image.Point{10, 50}
+	pasteFloat(field, object, p, 0)
+	// brighter to the right
+	for y := 0; y < 80; y++ {
+		for x := 0; x < 80; x++ {
+			field.Pix[field.PixOffset(x, y)] += 0.8 * float64(x) / 80
+		}
+	}
+	// a plain patch, matching the object's mean
+	fillFloat(field, image.Rectangle{decoy, decoy.Add(image.Point{16, 16})}, 0.5)
+	testOptionFinds(t, field, object, p, decoy, 0.2, func(opts *Options) {
+		opts.LightingRadius = 3
+	})
+	// a linear gradient is removed away from the edges
+	ramp := NewFloatImage(image.Rect(0, 0, 20, 20))
+	for i := range ramp.Pix {
+		ramp.Pix[i] = float64(i%20) / 20
+	}
+	if v := flattenLighting([]*FloatImage{ramp}, 3)[0].FloatAt(10, 10); math.Abs(v-0.5) > 1e-12 {
+		t.Fatal("gradient not removed", v)
+	}
+}
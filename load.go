@@ -0,0 +1,57 @@
+package objsearch
+
+import (
+	"image"
+	"io"
+	"os"
+
+	// register decoders for LoadField and LoadObject
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// Decodes a PNG, JPEG or GIF image from r as a Field, with its grayscale
+// plane precomputed
+func LoadField(r io.Reader) (*Field, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	f := NewField(img)
+	f.planes(COLORMODE_GRAY)
+	return f, nil
+}
+
+// Decodes a PNG, JPEG or GIF image from r as an Object, with its grayscale
+// plane precomputed. Transparent pixels of the image are excluded from
+// matching.
+func LoadObject(r io.Reader) (*Object, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	o := NewObject(img)
+	o.planes(COLORMODE_GRAY)
+	return o, nil
+}
+
+// Like LoadField, reading from the file at path
+func LoadFieldFile(path string) (*Field, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadField(file)
+}
+
+// Like LoadObject, reading from the file at path
+func LoadObjectFile(path string) (*Object, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadObject(file)
+}
@@ -0,0 +1,43 @@
+package objsearch
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+)
+
+// test that loaded fields and objects can be searched repeatedly
+func TestLoad(t *testing.T) {
+	img := randomRGBImage(40, 40)
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatal(err)
+	}
+	field, err := LoadField(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := png.Encode(buf, img.SubImage(image.Rect(7, 9, 15, 17))); err != nil {
+		t.Fatal(err)
+	}
+	object, err := LoadObject(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mode := range []ColorMode{COLORMODE_GRAY, COLORMODE_RGB, COLORMODE_GRAY} {
+		h := SearchImage(field, object, image.Rect(0, 0, 32, 32), Options{
+			Tolerance: 0.1,
+			MinDist:   8,
+			ColorMode: mode,
+		})
+		if len(h) == 0 || h[0] != (Hit{image.Point{7, 9}, 0}) {
+			t.Error(mode, h)
+			t.Fatal("search of loaded images error")
+		}
+	}
+	if _, err := LoadField(bytes.NewReader([]byte("not an image"))); err == nil {
+		t.Fatal("expected decode error")
+	}
+}
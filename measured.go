@@ -0,0 +1,39 @@
+package objsearch
+
+import "image"
+
+// A hit with the mean absolute per-pixel difference between the object and
+// the field there, which unlike the hit's score does not depend on the
+// score mode or on the other positions searched
+type MeasuredHit struct {
+	Hit
+	// Mean absolute difference per object pixel and channel, as a fraction
+	// of the full pixel range, e.g. 3.2/255 for an average difference of 3.2
+	// levels in 8-bit images. Differences are taken between the field and
+	// object as compared, after any preprocessing such as Blur, weighted by
+	// the object's mask and combined across channels as for SCOREMODE_L1.
+	MeanDiff float64
+}
+
+// Like SearchImage, returning each hit with its mean absolute per-pixel
+// difference, so that thresholds can be reasoned about in pixel levels
+func SearchMeasured(field, object image.Image, rect image.Rectangle, opts Options) []MeasuredHit {
+	ctx, fieldPlanes, objectPlanes := newImageContext(field, object, rect, opts)
+	if ctx.SearchRect.Empty() {
+		return nil
+	}
+	hits := ctx.searchPlanes(fieldPlanes, objectPlanes)
+	measured := make([]MeasuredHit, len(hits))
+	for i, h := range hits {
+		c := ctx
+		c.SearchRect = image.Rectangle{h.P, h.P.Add(image.Point{1, 1})}
+		measured[i] = MeasuredHit{h, c.distances(fieldPlanes, objectPlanes)[0]}
+	}
+	return measured
+}
+
+// Returns the mean absolute per-pixel difference between object and field
+// at top-left corner p, as reported by SearchMeasured
+func MeanDiff(field, object image.Image, p image.Point, opts Options) float64 {
+	return distanceAt(field, object, p, opts)
+}
@@ -0,0 +1,46 @@
+package objsearch
+
+import (
+	"encoding/json"
+	"image"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSearchMeasured(t *testing.T) {
+	object := randomRGBImage(10, 10)
+	for i := range object.Pix {
+		if i%4 != 3 {
+			object.Pix[i] = object.Pix[i]/2 + 50
+		}
+	}
+	// a copy 3 levels brighter
+	brighter := image.NewRGBA(object.Rect)
+	for i := range object.Pix {
+		brighter.Pix[i] = object.Pix[i]
+		if i%4 != 3 {
+			brighter.Pix[i] += 3
+		}
+	}
+	field := frameWithObject(frameWithObject(randomRGBImage(60, 50), object, image.Point{10, 5}), brighter, image.Point{40, 30})
+	opts := Options{Tolerance: 0.1, MinDist: 5, ColorMode: COLORMODE_RGB, ScoreMode: SCOREMODE_SQDIFF_NORMED}
+	hits := SearchMeasured(field, object, field.Rect, opts)
+	if len(hits) != 2 || hits[0].P != (image.Point{10, 5}) || hits[0].MeanDiff != 0 || hits[1].P != (image.Point{40, 30}) || math.Abs(hits[1].MeanDiff-3.0/255) > 1e-9 {
+		t.Fatal("measured hits error", hits)
+	}
+	if !reflect.DeepEqual(hits[1].Hit, SearchImage(field, object, field.Rect, opts)[1]) {
+		t.Fatal("measured hits differ from SearchImage")
+	}
+	if d := MeanDiff(field, object, image.Point{40, 30}, opts); d != hits[1].MeanDiff {
+		t.Fatal("mean difference error", d)
+	}
+	b, err := json.Marshal(hits[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d MeasuredHit
+	if err := json.Unmarshal(b, &d); err != nil || d != hits[1] {
+		t.Fatal("JSON round trip error", string(b), err)
+	}
+}
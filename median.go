@@ -0,0 +1,76 @@
+package objsearch
+
+import (
+	"image"
+	"sort"
+	"time"
+)
+
+// A FrameSource wrapping another, whose frames are the per-pixel temporal
+// median of the last N frames of the wrapped source. Matching against the
+// median ignores blinking cursors, spinners, and scanline noise that would
+// otherwise break stable matches.
+//
+// Until N frames have been read, the median is over all frames so far. If the
+// bounds of the wrapped source's frames change, the history is reset.
+type MedianSource struct {
+	Src     FrameSource
+	N       int
+	history []*image.RGBA
+}
+
+// Returns a MedianSource over the last n frames of src
+func NewMedianSource(src FrameSource, n int) *MedianSource {
+	if n <= 0 {
+		panic("n <= 0")
+	}
+	return &MedianSource{Src: src, N: n}
+}
+
+// Returns the median of the last N frames, and the time of the latest frame
+func (m *MedianSource) Next() (image.Image, time.Time, error) {
+	frame, t, err := m.Src.Next()
+	if err != nil {
+		return nil, t, err
+	}
+	f, ok := frame.(*image.RGBA)
+	if ok {
+		// the source may reuse the frame's buffer for its next frame
+		f = cloneRGBA(f)
+	} else {
+		f = toRGBA(frame)
+	}
+	if len(m.history) > 0 && m.history[0].Rect != f.Rect {
+		m.history = nil
+	}
+	m.history = append(m.history, f)
+	if len(m.history) > m.N {
+		m.history = m.history[len(m.history)-m.N:]
+	}
+	return medianRGBA(m.history), t, nil
+}
+
+// return the per-pixel, per-channel median of frames, which must have equal
+// bounds
+func medianRGBA(frames []*image.RGBA) *image.RGBA {
+	if len(frames) == 1 {
+		return frames[0]
+	}
+	r := image.NewRGBA(frames[0].Rect)
+	v := make([]int, len(frames))
+	for y := r.Rect.Min.Y; y < r.Rect.Max.Y; y++ {
+		for x := r.Rect.Min.X; x < r.Rect.Max.X; x++ {
+			o := r.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				for i, f := range frames {
+					v[i] = int(f.Pix[f.PixOffset(x, y)+c])
+				}
+				sort.Ints(v)
+				// for an even number of frames, take the lower median so
+				// that the result is one of the observed values
+				r.Pix[o+c] = uint8(v[(len(v)-1)/2])
+			}
+		}
+	}
+	return r
+}
@@ -0,0 +1,77 @@
+package objsearch
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestMedianSource(t *testing.T) {
+	bg := randomRGBImage(40, 40)
+	object := randomRGBImage(8, 8)
+	src := &SliceSource{}
+	for i := 0; i < 6; i++ {
+		f := frameWithObject(bg, object, image.Point{16, 16})
+		if i%3 == 1 {
+			// a blinking cursor over the object
+			for y := 16; y < 24; y++ {
+				f.SetRGBA(19, y, color.RGBA{255, 255, 255, 255})
+			}
+		}
+		src.Frames = append(src.Frames, f)
+	}
+	m := NewMedianSource(src, 3)
+	for i := 0; i < 6; i++ {
+		frame, _, err := m.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i < 2 {
+			continue
+		}
+		h, _, err := WaitFor(context.Background(), &SliceSource{Frames: []image.Image{frame}}, object, 0, Options{})
+		if err != nil || h.P != (image.Point{16, 16}) {
+			t.Fatal("median frame error", i, h, err)
+		}
+	}
+}
+
+// a FrameSource decoding each of its frames into the same buffer
+type reusedFrameSource struct {
+	frames []*image.RGBA
+	buf    *image.RGBA
+}
+
+func (s *reusedFrameSource) Next() (image.Image, time.Time, error) {
+	if len(s.frames) == 0 {
+		return nil, time.Time{}, io.EOF
+	}
+	if s.buf == nil {
+		s.buf = image.NewRGBA(s.frames[0].Rect)
+	}
+	copy(s.buf.Pix, s.frames[0].Pix)
+	s.frames = s.frames[1:]
+	return s.buf, time.Time{}, nil
+}
+
+// test that the history holds copies of frames whose buffer is reused
+func TestMedianSourceReusedFrame(t *testing.T) {
+	bg := randomRGBImage(40, 40)
+	object := randomRGBImage(8, 8)
+	f := frameWithObject(bg, object, image.Point{16, 16})
+	m := NewMedianSource(&reusedFrameSource{frames: []*image.RGBA{f, f, bg}}, 3)
+	var frame image.Image
+	for i := 0; i < 3; i++ {
+		var err error
+		if frame, _, err = m.Next(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h, _, err := WaitFor(context.Background(), &SliceSource{Frames: []image.Image{frame}}, object, 0, Options{})
+	if err != nil || h.P != (image.Point{16, 16}) {
+		t.Fatal("median frame error", h, err)
+	}
+}
@@ -0,0 +1,91 @@
+package objsearch
+
+import "image"
+
+// approximate stack size of each goroutine scoring a row or column
+const goroutineStack = 8 << 10
+
+// Returns the approximate peak number of bytes allocated by SearchImage
+// for a field and object of the given sizes, searching rect with opts, so
+// that services can reject or queue searches that would exceed their
+// memory budget before starting them. An empty rect is taken as every
+// position at which the object can be scored, as for SearchResult. Returns
+// 0 if there are no positions to search, or if rect is rejected by
+// CheckRect.
+//
+// The estimate assumes field and object are converted from an image type
+// other than *image.RGBA, and counts every preprocessing step of opts as
+// holding its own copy of the planes, so it errs on the high side. It does
+// not count the field and object images themselves.
+func EstimateMemory(fieldSize, objectSize image.Point, rect image.Rectangle, opts Options) int64 {
+	field, object := image.Rectangle{Max: fieldSize}, image.Rectangle{Max: objectSize}
+	if rect.Empty() {
+		rect = searchableRect(field, object, opts.Border)
+	}
+	rect, err := CheckRect(field, object, rect, opts)
+	if err != nil || rect.Empty() {
+		return 0
+	}
+	fieldArea := int64(field.Dx()) * int64(field.Dy())
+	objectArea := int64(object.Dx()) * int64(object.Dy())
+	positions := int64(rect.Dx()) * int64(rect.Dy())
+	channels := int64(1)
+	if opts.ColorMode == COLORMODE_RGB {
+		channels = 3
+	}
+	// RGBA copies, the object's alpha and mask, and the 8-bit planes they
+	// are separated into
+	n := 4*(fieldArea+objectArea) + 2*objectArea + channels*(fieldArea+objectArea)
+	// float64 planes, and each preprocessing step's copy of them
+	planes := 8 * channels * (fieldArea + objectArea)
+	n += planes
+	if opts.Border != BORDERMODE_NONE {
+		padded := int64(field.Dx()+2*object.Dx()) * int64(field.Dy()+2*object.Dy())
+		planes = 8 * channels * (padded + objectArea)
+		n += planes
+	}
+	for _, step := range []bool{
+		opts.Linearize,
+		opts.JPEGTolerant && opts.ColorMode == COLORMODE_RGB,
+		opts.Denoise != 0,
+		opts.LightingRadius != 0,
+		opts.Blur != 0,
+		opts.Levels != 0,
+	} {
+		if step {
+			n += planes
+		}
+	}
+	if opts.LightingRadius != 0 {
+		// the integral image of each plane
+		n += planes
+	}
+	if opts.AdaptiveTolerance {
+		// local contrast, and the integral images it is computed from
+		n += 8*positions + 2*planes/channels
+	}
+	if opts.EdgeWeighted {
+		n += 9 * objectArea
+	}
+	// scores, and the goroutines computing them
+	if newContext(rect, opts).distanceScores() {
+		size := int64(8)
+		if opts.Float32 {
+			size = 4
+		}
+		// each channel's distances, and their combination
+		n += size*channels*positions + 8*positions
+		n += goroutineStack * int64(rect.Dy())
+		if opts.Trim > 0 || opts.JPEGTolerant || opts.Border == BORDERMODE_OVERLAP {
+			// the differences of the window scored by each goroutine
+			n += 16 * objectArea * int64(rect.Dy())
+		}
+	} else {
+		// the weights and zero-mean object planes
+		n += 8*positions + 8*(channels+1)*objectArea
+		n += goroutineStack * int64(rect.Dy())
+	}
+	// normalized scores, from which hits are taken
+	n += 8 * positions
+	return n
+}
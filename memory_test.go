@@ -0,0 +1,34 @@
+package objsearch
+
+import (
+	"image"
+	"testing"
+)
+
+func TestEstimateMemory(t *testing.T) {
+	field, object := image.Point{1000, 800}, image.Point{20, 20}
+	base := EstimateMemory(field, object, image.Rectangle{}, Options{})
+	// at least the float64 planes and the distances
+	if min := int64(8 * (1000*800 + 981*781)); base < min {
+		t.Fatal("estimate too small", base, min)
+	}
+	for _, opts := range []Options{
+		{ColorMode: COLORMODE_RGB},
+		{Blur: 1},
+		{AdaptiveTolerance: true},
+		{Border: BORDERMODE_ZERO},
+	} {
+		if m := EstimateMemory(field, object, image.Rectangle{}, opts); m <= base {
+			t.Fatal("estimate not larger", opts, m, base)
+		}
+	}
+	if m := EstimateMemory(field, object, image.Rectangle{}, Options{ColorMode: COLORMODE_RGB, Float32: true}); m >= EstimateMemory(field, object, image.Rectangle{}, Options{ColorMode: COLORMODE_RGB}) {
+		t.Fatal("float32 estimate not smaller", m)
+	}
+	if m := EstimateMemory(field, object, image.Rect(0, 0, 100, 100), Options{}); m >= base {
+		t.Fatal("estimate for a smaller rect not smaller", m, base)
+	}
+	if m := EstimateMemory(object, field, image.Rectangle{}, Options{}); m != 0 {
+		t.Fatal("estimate for an object larger than the field", m)
+	}
+}
@@ -0,0 +1,141 @@
+package objsearch
+
+import (
+	"image"
+	"sort"
+)
+
+// How hits are found among the scores of a search
+type HitMode int
+
+const (
+	// Hits are the positions scoring better than Tolerance
+	HITMODE_THRESHOLD HitMode = iota
+	// Hits are the local minima of the scores, or maxima for
+	// SCOREMODE_CCOEFF_NORMED, whose prominence is at least MinProminence.
+	// The prominence of a minimum is how far the scores must rise from it,
+	// in hit score units, before reaching a better minimum. The best minimum
+	// has the prominence of the whole range of scores. Tolerance is ignored,
+	// so that well-separated matches are found without tuning it.
+	HITMODE_LOCALMINIMA
+	// Hits are the K best local minima of the scores, at least MinDist
+	// apart, whether or not they score better than Tolerance, so that the
+	// most promising positions are found even in fields without a match.
+	// Compare their scores with Tolerance, e.g. with ScoreBelow, to tell
+	// which are matches.
+	HITMODE_BESTK
+)
+
+// return the local minima of scores with prominence at least
+// ctx.MinProminence, at least ctx.MinDist apart, best first
+func (ctx objSearchContext) localMinimaHits(scores []float64) []Hit {
+	return ctx.suppress(ctx.minima(scores, ctx.MinProminence))
+}
+
+// return the ctx.K best local minima of scores, at least ctx.MinDist apart
+func (ctx objSearchContext) bestKHits(scores []float64) []Hit {
+	hits := ctx.suppress(ctx.minima(scores, 0))
+	if len(hits) > ctx.K {
+		hits = hits[:ctx.K]
+	}
+	return hits
+}
+
+// return the local minima of scores, as hits are scored, with prominence at
+// least minProminence
+func (ctx objSearchContext) minima(scores []float64, minProminence float64) []Hit {
+	s := ctx.hitScores(scores)
+	if s == nil {
+		// flat scores have no minima
+		return nil
+	}
+	// find the minima of the signed scores, lower is better
+	sign := 1.0
+	if ctx.ScoreMode == SCOREMODE_CCOEFF_NORMED {
+		sign = -1
+	}
+	signed := make([]float64, len(s))
+	for i := range s {
+		signed[i] = sign * s[i]
+	}
+	var hits []Hit
+	for i, p := range prominences(signed, ctx.SearchRect.Dx()) {
+		if p >= minProminence {
+			x, y := ctx.coords(i)
+			hits = append(hits, Hit{image.Point{x, y}, s[i]})
+		}
+	}
+	return hits
+}
+
+// return the prominence of each local minimum of v, a w-wide raster of
+// values, keyed by index. Each plateau is reported once, at its first index
+// in raster order.
+//
+// The values are flooded from the lowest up, and each basin is merged into
+// its neighbor with the lower minimum at the level where they meet, so that
+// its minimum's prominence is that level less the minimum.
+func prominences(v []float64, w int) map[int]float64 {
+	order := make([]int, len(v))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return v[order[i]] < v[order[j]]
+	})
+	// union-find over flooded indices; basin[root] is the index of the
+	// basin's minimum
+	parent := make([]int, len(v))
+	for i := range parent {
+		parent[i] = -1
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	basin := make([]int, len(v))
+	prom := make(map[int]float64)
+	h := len(v) / w
+	for _, i := range order {
+		parent[i], basin[i] = i, i
+		x, y := i%w, i/w
+		root := i
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				nx, ny := x+dx, y+dy
+				if nx < 0 || nx >= w || ny < 0 || ny >= h {
+					continue
+				}
+				j := ny*w + nx
+				if parent[j] < 0 {
+					// not yet flooded
+					continue
+				}
+				r := find(j)
+				if r == root {
+					continue
+				}
+				if root == i {
+					// i joins its first flooded neighbor's basin
+					parent[i], root = r, r
+					continue
+				}
+				// merge the basins meeting at i; the one with the higher
+				// minimum ends here
+				lo, hi := root, r
+				if v[basin[r]] < v[basin[root]] || (v[basin[r]] == v[basin[root]] && basin[r] < basin[root]) {
+					lo, hi = r, root
+				}
+				prom[basin[hi]] = v[i] - v[basin[hi]]
+				parent[hi], root = lo, lo
+			}
+		}
+	}
+	// the surviving basin's minimum spans the whole range
+	min, max := minMax(v)
+	prom[order[0]] = max - min
+	return prom
+}
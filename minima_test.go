@@ -0,0 +1,78 @@
+package objsearch
+
+import (
+	"image"
+	"testing"
+
+	"github.com/hypoactiv/objsearch/objsearchtest"
+)
+
+func TestProminences(t *testing.T) {
+	// two basins, at 0 and 2, meeting at a ridge of height 5
+	v := []float64{
+		0, 1, 5, 3, 2,
+		1, 2, 5, 3, 3,
+		2, 3, 5, 4, 4,
+	}
+	p := prominences(v, 5)
+	if len(p) != 2 || p[0] != 5 || p[4] != 3 {
+		t.Fatal("prominence error", p)
+	}
+	// a plateau is one minimum
+	p = prominences([]float64{1, 1, 2, 1, 1}, 5)
+	if len(p) != 2 || p[0] != 1 || p[3] != 1 {
+		t.Fatal("plateau prominence error", p)
+	}
+}
+
+func TestLocalMinimaHits(t *testing.T) {
+	g := objsearchtest.NewGenerator(1)
+	object := g.Noise(8, 8)
+	bg := g.Noise(70, 50)
+	field := frameWithObject(frameWithObject(bg, object, image.Point{5, 6}), object, image.Point{40, 30})
+	rect := validRect(field.Rect, object.Rect)
+	for _, mode := range []ScoreMode{SCOREMODE_L1, SCOREMODE_CCOEFF_NORMED} {
+		opts := Options{HitMode: HITMODE_LOCALMINIMA, MinProminence: 0.5, MinDist: 8, ScoreMode: mode}
+		hits := SearchImage(field, object, rect, opts)
+		if len(hits) != 2 {
+			t.Fatal("local minima hits error", mode, hits)
+		}
+		SortRaster(hits)
+		if hits[0].P != (image.Point{5, 6}) || hits[1].P != (image.Point{40, 30}) {
+			t.Fatal("local minima position error", mode, hits)
+		}
+		// with no prominence required, every local minimum is a hit
+		opts.MinProminence = 0
+		if all := SearchImage(field, object, rect, opts); len(all) <= 2 {
+			t.Fatal("expected more minima", mode, len(all))
+		}
+	}
+}
+
+func TestBestKHits(t *testing.T) {
+	object := randomRGBImage(8, 8)
+	// no match in the field
+	field := randomRGBImage(60, 50)
+	rect := validRect(field.Rect, object.Rect)
+	opts := Options{HitMode: HITMODE_BESTK, K: 5, MinDist: 8, Tolerance: 0.01}
+	hits := SearchImage(field, object, rect, opts)
+	if len(hits) != 5 || len(FilterHits(hits, ScoreBelow(opts.Tolerance))) != 0 {
+		t.Fatal("best K hits error", hits)
+	}
+	for i, h := range hits {
+		if i > 0 && h.S < hits[i-1].S {
+			t.Fatal("hits not sorted")
+		}
+		for _, g := range hits[:i] {
+			if g.Distance(h) < opts.MinDist {
+				t.Fatal("hits too close")
+			}
+		}
+	}
+	// the best is the global minimum
+	_, dist := searchDistances(field, object, rect, opts)
+	min, max := minMax(dist)
+	if hits[0].S != min/max {
+		t.Fatal("best hit is not the global minimum", hits[0].S, min/max)
+	}
+}
@@ -0,0 +1,74 @@
+package objsearch
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Searches consecutive frames of a video, restricting the search of each
+// frame to the regions that changed since the previous frame. Hits in
+// unchanged regions are carried over from the previous frame, so static
+// scenes cost little more than the frame comparison.
+//
+// Since only parts of each frame are searched, hit scores are not normalized
+// by the distances observed in the frame. Instead, the score of a hit is the
+// mean absolute per-pixel difference between the object and the frame, and
+// hits are reported when their score is below the Searcher's Tolerance.
+type MotionSearcher struct {
+	Searcher *Searcher
+	// Pixels with no color channel changed by more than Threshold are considered
+	// unchanged
+	Threshold uint8
+	// a copy of the previous frame, as the caller may reuse its buffer
+	prev *image.RGBA
+	hits []Hit
+}
+
+// Returns a MotionSearcher searching with s
+func NewMotionSearcher(s *Searcher, threshold uint8) *MotionSearcher {
+	return &MotionSearcher{Searcher: s, Threshold: threshold}
+}
+
+// Searches the next frame. Hits are sorted by score.
+func (m *MotionSearcher) Search(frame image.Image) []Hit {
+	f := toRGBA(frame)
+	valid := m.Searcher.searchRect(f)
+	object := m.Searcher.Object.Bounds()
+	var rects []image.Rectangle
+	var hits []Hit
+	if m.prev == nil || m.prev.Rect != f.Rect {
+		rects = []image.Rectangle{valid}
+		m.prev = image.NewRGBA(f.Rect)
+	} else {
+		// each channel's difference is in [0,1], in steps of 1/255
+		threshold := (float64(m.Threshold) + 0.5) / 255
+		changed := RegionBounds(Diff(m.prev, f, threshold, Options{ColorMode: COLORMODE_RGB}))
+		// keep previous hits whose windows are unchanged
+		for _, h := range m.hits {
+			window := object.Add(h.P)
+			unchanged := true
+			for _, c := range changed {
+				unchanged = unchanged && !window.Overlaps(c)
+			}
+			if unchanged {
+				hits = append(hits, h)
+			}
+		}
+		// search every position whose window overlaps a changed region
+		for _, c := range changed {
+			r := image.Rectangle{c.Min.Sub(object.Max).Add(image.Point{1, 1}), c.Max.Sub(object.Min)}
+			if r = r.Intersect(valid); !r.Empty() {
+				rects = append(rects, r)
+			}
+		}
+		rects = mergeRects(rects)
+	}
+	for _, r := range rects {
+		ctx, dist := searchDistances(f, m.Searcher.Object, r, m.Searcher.Options)
+		// score hits by their absolute distance
+		hits = append(hits, ctx.findHits(dist, 0, 1)...)
+	}
+	draw.Draw(m.prev, f.Rect, f, f.Rect.Min, draw.Src)
+	m.hits = suppressHits(hits, m.Searcher.Options.MinDist)
+	return append([]Hit{}, m.hits...)
+}
@@ -0,0 +1,37 @@
+package objsearch
+
+import (
+	"image"
+	"testing"
+)
+
+func TestMotionSearcher(t *testing.T) {
+	bg := randomRGBImage(80, 60)
+	object := randomRGBImage(8, 8)
+	m := NewMotionSearcher(NewSearcher(object, Options{Tolerance: 0.05, MinDist: 8}), 0)
+	// a static copy of the object, and a moving one
+	bg = frameWithObject(bg, object, image.Point{60, 40})
+	for i := 0; i < 5; i++ {
+		p := image.Point{5 + 4*i, 10}
+		hits := m.Search(frameWithObject(bg, object, p))
+		SortRaster(hits)
+		if len(hits) != 2 || hits[0].P != p || hits[1].P != (image.Point{60, 40}) {
+			t.Fatal("MotionSearcher error", i, hits)
+		}
+	}
+}
+
+// test that frames may be decoded into the same buffer
+func TestMotionSearcherReusedFrame(t *testing.T) {
+	bg := randomRGBImage(80, 60)
+	object := randomRGBImage(8, 8)
+	m := NewMotionSearcher(NewSearcher(object, Options{Tolerance: 0.05, MinDist: 8}), 0)
+	frame := image.NewRGBA(bg.Rect)
+	for i := 0; i < 5; i++ {
+		p := image.Point{5 + 4*i, 10}
+		copy(frame.Pix, frameWithObject(bg, object, p).Pix)
+		if hits := m.Search(frame); len(hits) != 1 || hits[0].P != p {
+			t.Fatal("MotionSearcher error", i, hits)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package objsearch
+
+import (
+	"image"
+	"sort"
+)
+
+// Kind of a TrackEvent
+type TrackEventKind int
+
+const (
+	// a new target was detected and assigned an ID
+	TRACKEVENT_BIRTH TrackEventKind = iota
+	// a known target was matched in the current frame
+	TRACKEVENT_UPDATE
+	// a target went unmatched for too long and its track was dropped
+	TRACKEVENT_DEATH
+)
+
+// A change to one of a MultiTracker's tracks
+type TrackEvent struct {
+	Kind TrackEventKind
+	// ID of the track, unique over the lifetime of the MultiTracker
+	ID    int
+	Track Track
+}
+
+// Follows any number of instances of an object across the frames of a video,
+// assigning each a stable ID.
+//
+// Each frame is searched in full, and the hits found are associated with
+// existing tracks greedily, preferring hits near a track's predicted
+// position and with good scores.
+type MultiTracker struct {
+	// The object being tracked
+	Object *Object
+	// Search options used to detect targets in each frame
+	Options Options
+	// Maximum distance in pixels, as measured by Hit.Distance, between a
+	// track's predicted position and a hit associated with it
+	Gate int
+	// Number of consecutive frames a track may go unmatched before it dies
+	MaxMisses int
+	// If true, each track's matches are recorded. See Trajectories.
+	Record       bool
+	trajectories map[int]Trajectory
+	tracks       []idTrack
+	nextID       int
+	// number of frames processed
+	frames int
+}
+
+// a track and its ID
+type idTrack struct {
+	id int
+	Track
+}
+
+// return the predicted position of the track in the next frame
+func (t idTrack) predict() image.Point {
+	return t.P.Add(roundPoint(t.Vx, t.Vy))
+}
+
+// Returns a MultiTracker for object, associating hits at most gate pixels
+// from a track's predicted position
+func NewMultiTracker(object image.Image, gate int, opts Options) *MultiTracker {
+	return &MultiTracker{
+		Object:  NewObject(object),
+		Options: opts,
+		Gate:    gate,
+		nextID:  1,
+	}
+}
+
+// Returns the current tracks by ID
+func (m *MultiTracker) Tracks() map[int]Track {
+	r := make(map[int]Track, len(m.tracks))
+	for _, t := range m.tracks {
+		r[t.id] = t.Track
+	}
+	return r
+}
+
+// Returns the recorded trajectories of all tracks, including dead ones, by
+// ID. Trajectories are recorded only if m.Record is set.
+func (m *MultiTracker) Trajectories() map[int]Trajectory {
+	return m.trajectories
+}
+
+// record the current state of track id, if recording
+func (m *MultiTracker) record(id int, t Track) {
+	if !m.Record {
+		return
+	}
+	if m.trajectories == nil {
+		m.trajectories = make(map[int]Trajectory)
+	}
+	m.trajectories[id] = append(m.trajectories[id], trajectoryPoint(m.frames-1, t))
+}
+
+// Searches frame for targets, as SearchImage does with m.Options, updates the
+// tracks, and returns the resulting events. Deaths are reported first, then
+// updates and births, each in ascending ID order.
+func (m *MultiTracker) Update(frame image.Image) (events []TrackEvent) {
+	rect := validRect(frame.Bounds(), m.Object.Bounds())
+	if rect.Empty() {
+		return m.associate(nil, nil)
+	}
+	ctx, fieldPlanes, objectPlanes := newImageContext(frame, m.Object, rect, m.Options)
+	if ctx.SearchRect.Empty() {
+		return m.associate(nil, nil)
+	}
+	scores := ctx.scores(fieldPlanes, objectPlanes)
+	if ctx.hitScores(scores) == nil {
+		// the scores cannot be normalized, as in a uniform frame
+		return m.associate(nil, nil)
+	}
+	hits := ctx.hits(scores)
+	raw := make([]float64, len(hits))
+	for i := range hits {
+		c := ctx
+		c.SearchRect = image.Rectangle{hits[i].P, hits[i].P.Add(image.Point{1, 1})}
+		raw[i] = c.distances(fieldPlanes, objectPlanes)[0]
+	}
+	return m.associate(hits, raw)
+}
+
+// associate hits, with raw distances raw, to tracks and return the resulting
+// events
+func (m *MultiTracker) associate(hits []Hit, raw []float64) (events []TrackEvent) {
+	m.frames++
+	// candidate track-hit pairs within the gate
+	type pair struct {
+		t, h int
+		cost float64
+	}
+	pairs := []pair{}
+	for i := range m.tracks {
+		pred := Hit{P: m.tracks[i].predict()}
+		for j := range hits {
+			if d := hits[j].Distance(pred); d <= m.Gate {
+				// a poor match costs as much as a distant position. The
+				// raw distance is used as scores differ by score mode.
+				pairs = append(pairs, pair{i, j, float64(d) + raw[j]*float64(m.Gate)})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].cost < pairs[j].cost
+	})
+	// greedily assign cheapest pairs first
+	trackHit := make([]int, len(m.tracks))
+	for i := range trackHit {
+		trackHit[i] = -1
+	}
+	hitUsed := make([]bool, len(hits))
+	for _, p := range pairs {
+		if trackHit[p.t] < 0 && !hitUsed[p.h] {
+			trackHit[p.t] = p.h
+			hitUsed[p.h] = true
+		}
+	}
+	var updates, deaths []TrackEvent
+	live := m.tracks[:0]
+	for i, t := range m.tracks {
+		t.Frame++
+		if j := trackHit[i]; j >= 0 {
+			p := hits[j].P
+			t.Vx, t.Vy = float64(p.X-t.P.X), float64(p.Y-t.P.Y)
+			t.P, t.M, t.S, t.Misses = p, p, raw[j], 0
+			updates = append(updates, TrackEvent{TRACKEVENT_UPDATE, t.id, t.Track})
+			m.record(t.id, t.Track)
+		} else {
+			t.P = t.predict()
+			t.Misses++
+			if t.Misses > m.MaxMisses {
+				deaths = append(deaths, TrackEvent{TRACKEVENT_DEATH, t.id, t.Track})
+				continue
+			}
+		}
+		live = append(live, t)
+	}
+	m.tracks = live
+	events = append(deaths, updates...)
+	for j := range hits {
+		if hitUsed[j] {
+			continue
+		}
+		t := idTrack{m.nextID, Track{P: hits[j].P, M: hits[j].P, S: raw[j], Frame: 1}}
+		m.record(t.id, t.Track)
+		m.nextID++
+		m.tracks = append(m.tracks, t)
+		events = append(events, TrackEvent{TRACKEVENT_BIRTH, t.id, t.Track})
+	}
+	return
+}
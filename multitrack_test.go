@@ -0,0 +1,114 @@
+package objsearch
+
+import (
+	"image"
+	"image/draw"
+	"testing"
+)
+
+func TestMultiTracker(t *testing.T) {
+	bg := randomRGBImage(120, 80)
+	object := randomRGBImage(8, 8)
+	m := NewMultiTracker(object, 6, Options{Tolerance: 0.3, MinDist: 8})
+	m.MaxMisses = 1
+	a, b := image.Point{10, 10}, image.Point{100, 60}
+	ids := map[image.Point]int{}
+	for i := 0; i < 8; i++ {
+		frame := image.NewRGBA(bg.Rect)
+		copy(frame.Pix, bg.Pix)
+		draw.Draw(frame, object.Rect.Add(a), object, image.ZP, draw.Src)
+		if i < 5 {
+			draw.Draw(frame, object.Rect.Add(b), object, image.ZP, draw.Src)
+		}
+		events := m.Update(frame)
+		for _, e := range events {
+			switch e.Kind {
+			case TRACKEVENT_BIRTH:
+				if i != 0 {
+					t.Fatal("unexpected birth", i, e)
+				}
+				ids[e.Track.P] = e.ID
+			case TRACKEVENT_UPDATE:
+				want := a
+				if e.ID == ids[image.Point{100, 60}] {
+					want = b
+				}
+				if e.Track.P != want {
+					t.Fatal("wrong association", i, e, want)
+				}
+			case TRACKEVENT_DEATH:
+				if i != 6 || e.ID != ids[image.Point{100, 60}] {
+					t.Fatal("unexpected death", i, e)
+				}
+			}
+		}
+		a = a.Add(image.Point{3, 1})
+		b = b.Add(image.Point{-3, -1})
+	}
+	if len(ids) != 2 || len(m.Tracks()) != 1 {
+		t.Fatal("track count error", ids, m.Tracks())
+	}
+}
+
+// test that trajectories are indexed by the MultiTracker's frames, not by
+// the frames since each track's birth
+func TestMultiTrackerTrajectories(t *testing.T) {
+	bg := randomRGBImage(120, 80)
+	object := randomRGBImage(8, 8)
+	m := NewMultiTracker(object, 6, Options{Tolerance: 0.3, MinDist: 8})
+	m.Record = true
+	a, b := image.Point{10, 10}, image.Point{100, 60}
+	for i := 0; i < 5; i++ {
+		frame := frameWithObject(bg, object, a)
+		if i >= 2 {
+			frame = frameWithObject(frame, object, b)
+		}
+		m.Update(frame)
+	}
+	trajs := m.Trajectories()
+	if len(trajs) != 2 || len(trajs[1]) != 5 || len(trajs[2]) != 3 {
+		t.Fatal("trajectory count error", trajs)
+	}
+	for i, p := range trajs[2] {
+		if p.Frame != i+2 || p.P != b {
+			t.Fatal("late track trajectory error", trajs[2])
+		}
+	}
+	if trajs[1][4].Frame != 4 {
+		t.Fatal("trajectory frame error", trajs[1])
+	}
+}
+
+// test that targets are detected according to the score and hit modes, and
+// that a uniform frame has none
+func TestMultiTrackerModes(t *testing.T) {
+	bg := randomRGBImage(80, 60)
+	object := randomRGBImage(8, 8)
+	frame := frameWithObject(frameWithObject(bg, object, image.Point{10, 10}), object, image.Point{50, 30})
+	for _, opts := range []Options{
+		{Tolerance: 0.9, MinDist: 8, ScoreMode: SCOREMODE_CCOEFF_NORMED},
+		{MinDist: 8, HitMode: HITMODE_BESTK, K: 2},
+	} {
+		m := NewMultiTracker(object, 6, opts)
+		events := m.Update(frame)
+		var hits []Hit
+		for _, e := range events {
+			hits = append(hits, Hit{P: e.Track.P, S: e.Track.S})
+		}
+		SortRaster(hits)
+		if len(hits) != 2 || hits[0] != (Hit{image.Point{10, 10}, 0}) || hits[1] != (Hit{image.Point{50, 30}, 0}) {
+			t.Fatal("multi-tracker detection error", opts.HitMode, events)
+		}
+	}
+	uniform := image.NewRGBA(bg.Rect)
+	white := image.NewRGBA(object.Rect)
+	for i := range uniform.Pix {
+		uniform.Pix[i] = 255
+	}
+	for i := range white.Pix {
+		white.Pix[i] = 255
+	}
+	if events := NewMultiTracker(white, 6, Options{Tolerance: 0.1}).Update(uniform); len(events) != 0 {
+		t.Fatal("events in a uniform frame", events)
+	}
+}
@@ -0,0 +1,77 @@
+package objsearch
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"strings"
+)
+
+// Returns the mean absolute per-pixel difference between each plane of
+// object and field, as selected by opts.ColorMode, at each top-left corner in
+// rect, before the planes are weighted and combined into the map returned by
+// DistanceMap. If rect is empty, every position at which object lies
+// entirely within field is used.
+func ChannelDistanceMaps(field, object image.Image, rect image.Rectangle, opts Options) []*FloatImage {
+	if rect.Empty() {
+		rect = searchableRect(field.Bounds(), object.Bounds(), opts.Border)
+	}
+	ctx, fieldPlanes, objectPlanes := newImageContext(field, object, rect, opts)
+	maps := make([]*FloatImage, len(fieldPlanes))
+	for i := range fieldPlanes {
+		maps[i] = &FloatImage{
+			Pix:    ctx.objSearch(fieldPlanes[i], objectPlanes[i]).float64s(),
+			Stride: ctx.SearchRect.Dx(),
+			Rect:   ctx.SearchRect,
+		}
+	}
+	return maps
+}
+
+// Writes d to w in NumPy's .npy format, as a float64 array of shape
+// (height, width), so that it can be loaded with numpy.load
+func WriteNPY(w io.Writer, d *FloatImage) error {
+	return writeNPY(w, []*FloatImage{d}, []int{d.Rect.Dy(), d.Rect.Dx()})
+}
+
+// Writes maps, which must have equal bounds, to w in NumPy's .npy format, as
+// a float64 array of shape (len(maps), height, width)
+func WriteNPYStack(w io.Writer, maps []*FloatImage) error {
+	if len(maps) == 0 {
+		return writeNPY(w, nil, []int{0, 0, 0})
+	}
+	r := maps[0].Rect
+	for _, m := range maps {
+		if m.Rect != r {
+			panic("maps have different bounds")
+		}
+	}
+	return writeNPY(w, maps, []int{len(maps), r.Dy(), r.Dx()})
+}
+
+// write the header for a float64 array of the given shape, followed by the
+// pixels of maps in row-major order
+func writeNPY(w io.Writer, maps []*FloatImage, shape []int) error {
+	dims := make([]string, len(shape))
+	for i := range shape {
+		dims[i] = fmt.Sprint(shape[i])
+	}
+	header := fmt.Sprintf("{'descr': '<f8', 'fortran_order': False, 'shape': (%s,), }", strings.Join(dims, ", "))
+	// pad with spaces and a newline so that the data is 64-byte aligned
+	const prefix = 10
+	header += strings.Repeat(" ", 63-(prefix+len(header))%64) + "\n"
+	b := []byte("\x93NUMPY\x01\x00")
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(header)))
+	b = append(b, header...)
+	for _, m := range maps {
+		for y := m.Rect.Min.Y; y < m.Rect.Max.Y; y++ {
+			for x := m.Rect.Min.X; x < m.Rect.Max.X; x++ {
+				b = binary.LittleEndian.AppendUint64(b, math.Float64bits(m.FloatAt(x, y)))
+			}
+		}
+	}
+	_, err := w.Write(b)
+	return err
+}
@@ -0,0 +1,52 @@
+package objsearch
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestWriteNPY(t *testing.T) {
+	d := FloatImageFromRows([][]float64{{1, 2, 3}, {4, 5, 6.5}})
+	b := &bytes.Buffer{}
+	if err := WriteNPY(b, d); err != nil {
+		t.Fatal(err)
+	}
+	data := b.Bytes()
+	if string(data[:8]) != "\x93NUMPY\x01\x00" {
+		t.Fatal("magic error")
+	}
+	hlen := int(binary.LittleEndian.Uint16(data[8:]))
+	header := string(data[10 : 10+hlen])
+	if (10+hlen)%64 != 0 || !strings.HasSuffix(header, "\n") || !strings.Contains(header, "'shape': (2, 3,)") || !strings.Contains(header, "'<f8'") {
+		t.Fatal("header error", header)
+	}
+	pix := data[10+hlen:]
+	if len(pix) != 6*8 || math.Float64frombits(binary.LittleEndian.Uint64(pix[40:])) != 6.5 {
+		t.Fatal("data error")
+	}
+}
+
+func TestChannelDistanceMaps(t *testing.T) {
+	object := randomRGBImage(8, 8)
+	field := frameWithObject(randomRGBImage(40, 30), object, image.Point{5, 6})
+	opts := Options{ColorMode: COLORMODE_RGB, CombineMode: COMBINEMODE_MAX}
+	maps := ChannelDistanceMaps(field, object, image.Rectangle{}, opts)
+	combined := DistanceMap(field, object, image.Rectangle{}, opts)
+	if len(maps) != 3 || maps[0].Rect != combined.Rect {
+		t.Fatal("channel maps error")
+	}
+	for i := range combined.Pix {
+		max := math.Max(maps[0].Pix[i], math.Max(maps[1].Pix[i], maps[2].Pix[i]))
+		if max != combined.Pix[i] {
+			t.Fatal("channel maps do not combine to distance map")
+		}
+	}
+	b := &bytes.Buffer{}
+	if err := WriteNPYStack(b, maps); err != nil || !strings.Contains(b.String(), "'shape': (3, 23, 33,)") {
+		t.Fatal("stack error", err)
+	}
+}
@@ -3,12 +3,10 @@ package objsearch
 import (
 	"fmt"
 	"image"
+	"image/color"
 	"io"
 	"math"
-	"sort"
 	"sync"
-
-	"github.com/hypoactiv/imutil"
 )
 
 type objSearchContext struct {
@@ -17,6 +15,30 @@ type objSearchContext struct {
 	Tolerance     float64
 	VerboseOut    io.Writer
 	MinDist       int
+	// per-pixel weights of the object image, or nil if all object pixels
+	// are weighted equally
+	Mask           *image.Alpha
+	CombineMode    CombineMode
+	ChannelWeights []float64
+	ScoreMode      ScoreMode
+	HitMode        HitMode
+	MinProminence  float64
+	K              int
+	Trim           float64
+	JPEG           bool
+	// origin of the field's 8x8 JPEG blocks
+	BlockOrigin image.Point
+	Border      BorderMode
+	Float32     bool
+	// bounds of the field before it was extended past its edges according
+	// to Border
+	FieldRect image.Rectangle
+	// distances normalized to hit scores of 0 and 1, if not taken from the
+	// scores searched, e.g. when they are searched in parts
+	ScoreRange *[2]float64
+	// local contrast of the field at each point of SearchRect, or nil if the
+	// tolerance is not adaptive
+	Contrast []float64
 }
 
 // Color processing mode
@@ -27,108 +49,553 @@ type CombineMode int
 
 const (
 	// convert field and image to grayscale before searching
-	COLORMODE_GRAY = iota
+	COLORMODE_GRAY ColorMode = iota
 	// compare RGB channels separately and combine results according to CombineMode
 	COLORMODE_RGB
+)
 
-	COMBINEMODE_MAX // combine results by taking the per-pixel maximum over all channels
+const (
+	COMBINEMODE_MAX  CombineMode = iota // combine results by taking the per-pixel maximum over all channels
+	COMBINEMODE_MEAN                    // combine results by taking the per-pixel mean over all channels
 )
 
+func (m ColorMode) String() string {
+	switch m {
+	case COLORMODE_GRAY:
+		return "gray"
+	case COLORMODE_RGB:
+		return "rgb"
+	}
+	return fmt.Sprintf("ColorMode(%d)", int(m))
+}
+
+func (m CombineMode) String() string {
+	switch m {
+	case COMBINEMODE_MAX:
+		return "max"
+	case COMBINEMODE_MEAN:
+		return "mean"
+	}
+	return fmt.Sprintf("CombineMode(%d)", int(m))
+}
+
+// Search parameters. The zero value searches in grayscale with zero
+// tolerance.
+type Options struct {
+	// Hits returned have scores below Tolerance
+	Tolerance float64
+	// Hits returned are at least MinDist pixels from eachother
+	MinDist int
+	// If not nil, progress is reported here
+	VerboseOut  io.Writer
+	ColorMode   ColorMode
+	CombineMode CombineMode
+	// If not nil, selects which object pixels participate in matching, and
+	// how strongly. Mask coordinates correspond to object coordinates.
+	//
+	// The weight of a pixel is taken from the alpha channel of an
+	// *image.Alpha mask, the luminance of an *image.Gray mask, or the alpha
+	// channel of any other image. Zero weight excludes a pixel entirely.
+	Mask image.Image
+	// If not nil, object pixels of exactly this color are excluded from
+	// matching, e.g. color.RGBA{255, 0, 255, 255} for magenta-keyed sprites
+	ColorKey color.Color
+	// If not nil, the per-channel distances are multiplied by these weights
+	// before being combined. Must have one weight per channel.
+	ChannelWeights []float64
+	// Scale and relative weight of the depth channel in SearchRGBD. Zero is
+	// treated as 1.
+	DepthScale, DepthWeight float64
+	// How hits are scored. See ScoreMode.
+	ScoreMode ScoreMode
+	// How hits are found among the scores. See HitMode.
+	HitMode HitMode
+	// Minimum prominence of a hit in HITMODE_LOCALMINIMA, in hit score units
+	MinProminence float64
+	// Number of hits returned in HITMODE_BESTK
+	K int
+	// If true, the tolerance at each position is scaled by the standard
+	// deviation of the field window there, relative to its mean over the
+	// search rectangle, so that matches in flat regions are held to a
+	// tighter bar than matches in busy ones. Applies to hits found by
+	// thresholding SCOREMODE_L1, SCOREMODE_ZSCORE and SCOREMODE_L1_ABSOLUTE
+	// scores.
+	AdaptiveTolerance bool
+	// Fraction of the object's pixels, those differing most from the field,
+	// left out of each distance, in [0,1). The distance is the mean of the
+	// remaining differences, so that an object partly covered by another,
+	// e.g. 30% covered with a Trim of 0.3, still scores well. Applies to
+	// SCOREMODE_L1, SCOREMODE_ZSCORE and SCOREMODE_L1_ABSOLUTE.
+	Trim float64
+	// If true, each object pixel is weighted by the object's gradient
+	// magnitude there, in addition to any Mask, so that matches are judged
+	// on edges and fine structure rather than on uniform areas. Pixels in
+	// flat areas of the object are excluded. Ignored if the object is flat.
+	EdgeWeighted bool
+	// If not zero, field and object are blurred with a Gaussian of this
+	// standard deviation, in pixels, before scoring, so that sub-pixel
+	// rendering differences such as font smoothing or scaling filters are
+	// absorbed. A Blur of 0.5 to 1 is usually enough.
+	Blur float64
+	// If true, matching is tuned for fields that have been JPEG compressed:
+	// differences on either side of the boundaries of the field's 8x8 JPEG
+	// blocks are weighted less, and in COLORMODE_RGB, channels are compared
+	// as luma and low-passed chroma, so that blocking artifacts and lost
+	// chroma detail count for less. Block boundary weighting applies to
+	// SCOREMODE_L1, SCOREMODE_ZSCORE and SCOREMODE_L1_ABSOLUTE.
+	JPEGTolerant bool
+	// If not zero, field and object are posterized to this many evenly
+	// spaced levels per channel before scoring, after any Blur, so that
+	// dithering and slight palette shifts are absorbed. Must be at least 2.
+	// Pixel values are assumed to be in [0,1].
+	Levels int
+	// If not zero, the mean of the surrounding (2r+1)x(2r+1) pixels, for a
+	// LightingRadius of r, is subtracted from each pixel of the field and
+	// object before scoring, so that smooth illumination gradients across
+	// photographed fields are removed. Means are taken within the bounds of
+	// each image, so the radius should be small relative to the object.
+	// Applied before Blur and Levels.
+	LightingRadius int
+	// If true, gamma-encoded pixel values in [0,1] are decoded to linear
+	// light before scoring, so that dark and bright regions contribute
+	// according to their physical intensity. Values are decoded with the
+	// sRGB transfer function, or as v^Gamma if Gamma is not zero.
+	Linearize bool
+	Gamma     float64
+	// If not zero, the field is median filtered over (2r+1)x(2r+1)
+	// neighbourhoods, for a Denoise of r, before scoring, so that noise in
+	// camera captures is suppressed. The object is filtered too if
+	// DenoiseObject is set. Applied before LightingRadius, Blur and Levels.
+	Denoise       int
+	DenoiseObject bool
+	// How positions at which the object extends past the edges of the field
+	// are scored. See BorderMode. Unless it is BORDERMODE_NONE, the search
+	// rectangle may hold any position at which the object overlaps the
+	// field, and an empty rectangle searches all of them where the Search
+	// functions allow one.
+	Border BorderMode
+	// If true, L1 distances are accumulated in float32, and the distance
+	// map of each channel is stored in float32 until channels are
+	// combined, halving the memory used for large search rectangles at the
+	// cost of precision. Applies to SCOREMODE_L1, SCOREMODE_ZSCORE and
+	// SCOREMODE_L1_ABSOLUTE, except with Trim, JPEGTolerant, or
+	// BORDERMODE_OVERLAP past the field's edges, which are computed in
+	// float64 and stored in float32.
+	Float32 bool
+	// If true, the Search functions panic with a *RectError, or return it
+	// where they return errors, if the search rectangle holds positions at
+	// which the object cannot be scored, such as when the object is larger
+	// than the field. Otherwise, the search rectangle is clamped to the
+	// positions at which it can be, so that such an object simply has no
+	// hits. See CheckRect.
+	StrictRect bool
+}
+
 // Returns a slice of Hits indicating detected occurences of 'object' in 'field'
 // Hits are at the top-left corner of the detected object.
 // Hits returned have scores below tolerance and are at least minDist
 // pixels from eachother.
+//
+// Transparent pixels of 'object' are excluded from matching, and partially
+// transparent pixels contribute in proportion to their alpha.
 func Search(field, object *image.RGBA, rect image.Rectangle, tolerance float64, minDist int, verboseOut io.Writer, colorMode ColorMode, combineMode CombineMode) []Hit {
-	ctx := objSearchContext{
-		Field:      field,
-		Object:     object,
-		SearchRect: rect,
-		Tolerance:  tolerance,
-		VerboseOut: verboseOut,
-		MinDist:    minDist,
-	}
-	// create intermediate field and object images
-	interField, interObject := []*image.Gray{}, []*image.Gray{}
-	switch colorMode {
-	case COLORMODE_GRAY:
-		// generate grayscale intermediate images
-		interField = append(interField, imutil.ToGrayscale(field))
-		interObject = append(interObject, imutil.ToGrayscale(object))
-	case COLORMODE_RGB:
-		interField = imutil.SeparateRGB(field)
-		interObject = imutil.SeparateRGB(object)
-	default:
-		panic("invalid color mode")
+	return SearchWithOptions(field, object, rect, Options{
+		Tolerance:   tolerance,
+		MinDist:     minDist,
+		VerboseOut:  verboseOut,
+		ColorMode:   colorMode,
+		CombineMode: combineMode,
+	})
+}
+
+// Like Search, with parameters given by opts
+func SearchWithOptions(field, object *image.RGBA, rect image.Rectangle, opts Options) []Hit {
+	return SearchImage(field, object, rect, opts)
+}
+
+// Like SearchWithOptions, for field and object images of any type.
+// *image.RGBA, *image.NRGBA, *OrderedRGBA and *OrderedRGB images are used
+// without conversion through image.Image's generic interface, and *Field and
+// *Object images without any conversion.
+//
+// Only the positions in rect at which object can be scored are searched. If
+// opts.StrictRect is set, panics with a *RectError instead if rect holds
+// other positions. See CheckRect.
+func SearchImage(field, object image.Image, rect image.Rectangle, opts Options) []Hit {
+	ctx, fieldPlanes, objectPlanes := newImageContext(field, object, rect, opts)
+	if ctx.SearchRect.Empty() {
+		return nil
+	}
+	return ctx.searchPlanes(fieldPlanes, objectPlanes)
+}
+
+// Like SearchImage, with a depth channel added to the field and object, so
+// that lookalike objects at the wrong depth are rejected. fieldDepth and
+// objectDepth must have the same bounds as field and object.
+//
+// Depth differences are divided by opts.DepthScale, and the depth channel is
+// weighted by opts.DepthWeight relative to the color channels, which are
+// weighted by opts.ChannelWeights if it is not nil. The depth channel is
+// padded and preprocessed with the color channels, but not linearized or
+// filtered for JPEG artifacts.
+func SearchRGBD(field image.Image, fieldDepth *FloatImage, object image.Image, objectDepth *FloatImage, rect image.Rectangle, opts Options) []Hit {
+	if fieldDepth.Rect != field.Bounds() || objectDepth.Rect != object.Bounds() {
+		panic("depth and color bounds differ")
+	}
+	scale, weight := opts.DepthScale, opts.DepthWeight
+	if scale == 0 {
+		scale = 1
+	}
+	if weight == 0 {
+		weight = 1
+	}
+	scaled := func(p *FloatImage) *FloatImage {
+		s := NewFloatImage(p.Rect)
+		for i := range p.Pix {
+			s.Pix[i] = p.Pix[i] / scale
+		}
+		return s
+	}
+	ctx, fieldPlanes, objectPlanes := newImageContextWith(field, object, scaled(fieldDepth), scaled(objectDepth), rect, opts)
+	if ctx.SearchRect.Empty() {
+		return nil
+	}
+	// weight the color channels as the caller did, and the depth channel by
+	// weight
+	ctx.ChannelWeights = make([]float64, len(fieldPlanes))
+	for i := range ctx.ChannelWeights {
+		ctx.ChannelWeights[i] = 1
+	}
+	copy(ctx.ChannelWeights, opts.ChannelWeights)
+	ctx.ChannelWeights[len(fieldPlanes)-1] = weight
+	return ctx.searchPlanes(fieldPlanes, objectPlanes)
+}
+
+// Like SearchWithOptions, for single-channel floating point images. Pixel
+// values may have any range. opts.ColorMode, opts.CombineMode and
+// opts.ColorKey are ignored.
+func SearchFloat(field, object *FloatImage, rect image.Rectangle, opts Options) []Hit {
+	return SearchPlanes([]*FloatImage{field}, []*FloatImage{object}, rect, opts)
+}
+
+// Like SearchWithOptions, for images with any number of channels, such as
+// multispectral images. field[i] and object[i] are the i-th channels of the
+// field and object. Per-channel results are combined according to
+// opts.CombineMode, so channels should have comparable value ranges.
+// opts.ColorMode and opts.ColorKey are ignored.
+func SearchPlanes(field, object []*FloatImage, rect image.Rectangle, opts Options) []Hit {
+	if len(field) != len(object) {
+		panic("field and object have different numbers of channels")
+	}
+	if len(field) == 0 {
+		panic("no channels")
 	}
+	rect, err := CheckRect(field[0].Rect, object[0].Rect, rect, opts)
+	if err != nil {
+		panic(err)
+	}
+	if rect.Empty() {
+		return nil
+	}
+	ctx := newContext(rect, opts)
+	ctx.Mask = toMask(opts.Mask, object[0].Rect)
+	ctx.BlockOrigin = field[0].Rect.Min
+	ctx.FieldRect = field[0].Rect
+	field = padPlanes(field, object[0].Rect, opts.Border)
+	if opts.Linearize {
+		field, object = linearizePlanes(field, opts.Gamma), linearizePlanes(object, opts.Gamma)
+	}
+	field, object = ctx.prepare(field, object, opts)
+	return ctx.searchPlanes(field, object)
+}
+
+// return a search context for rect and opts, without a mask
+func newContext(rect image.Rectangle, opts Options) objSearchContext {
+	return objSearchContext{
+		SearchRect:     rect,
+		Tolerance:      opts.Tolerance,
+		VerboseOut:     opts.VerboseOut,
+		MinDist:        opts.MinDist,
+		CombineMode:    opts.CombineMode,
+		ChannelWeights: opts.ChannelWeights,
+		ScoreMode:      opts.ScoreMode,
+		HitMode:        opts.HitMode,
+		MinProminence:  opts.MinProminence,
+		K:              opts.K,
+		Trim:           opts.Trim,
+		JPEG:           opts.JPEGTolerant,
+		Border:         opts.Border,
+		Float32:        opts.Float32,
+	}
+}
+
+// return a search context for field and object images, and their
+// intermediate planes according to opts.ColorMode. rect is clamped by
+// CheckRect, panicking with its *RectError, so callers must use
+// ctx.SearchRect, and no planes are returned if it is empty.
+func newImageContext(field, object image.Image, rect image.Rectangle, opts Options) (ctx objSearchContext, fieldPlanes, objectPlanes []*FloatImage) {
+	return newImageContextWith(field, object, nil, nil, rect, opts)
+}
+
+// like newImageContext, with fieldExtra and objectExtra, if not nil, added
+// as the last planes. They are padded and preprocessed with the color
+// planes, but not linearized or filtered for JPEG artifacts.
+func newImageContextWith(field, object image.Image, fieldExtra, objectExtra *FloatImage, rect image.Rectangle, opts Options) (ctx objSearchContext, fieldPlanes, objectPlanes []*FloatImage) {
+	rect, err := CheckRect(field.Bounds(), object.Bounds(), rect, opts)
+	if err != nil {
+		panic(err)
+	}
+	ctx = newContext(rect, opts)
+	if rect.Empty() {
+		return
+	}
+	f, o := NewField(field), NewObject(object)
+	// compare the colors of partially transparent pixels, not their
+	// premultiplied values
+	ctx.Field, ctx.Object = f.RGBA, o.opaque
+	ctx.Mask = combineMasks(o.alpha, toMask(opts.Mask, ctx.Object.Rect))
+	ctx.Mask = combineMasks(ctx.Mask, colorKeyMask(ctx.Object, opts.ColorKey))
+	fieldPlanes, objectPlanes = f.planes(opts.ColorMode), o.planes(opts.ColorMode)
+	ctx.BlockOrigin = f.Rect.Min
+	ctx.FieldRect = f.Rect
+	fieldPlanes = padPlanes(fieldPlanes, ctx.Object.Rect, opts.Border)
+	if opts.Linearize {
+		fieldPlanes, objectPlanes = linearizePlanes(fieldPlanes, opts.Gamma), linearizePlanes(objectPlanes, opts.Gamma)
+	}
+	if opts.JPEGTolerant && opts.ColorMode == COLORMODE_RGB {
+		fieldPlanes, objectPlanes = jpegPlanes(fieldPlanes, objectPlanes, rect)
+	}
+	if fieldExtra != nil {
+		fieldPlanes = append(fieldPlanes, padPlanes([]*FloatImage{fieldExtra}, ctx.Object.Rect, opts.Border)...)
+		objectPlanes = append(objectPlanes, objectExtra)
+	}
+	fieldPlanes, objectPlanes = ctx.prepare(fieldPlanes, objectPlanes, opts)
+	return
+}
+
+// return the field and object planes preprocessed according to opts, and
+// set ctx's edge weights and local contrast from them
+func (ctx *objSearchContext) prepare(field, object []*FloatImage, opts Options) ([]*FloatImage, []*FloatImage) {
+	if opts.Denoise != 0 {
+		field, object = denoiseInputs(field, object, ctx.SearchRect, opts.Denoise, opts.DenoiseObject)
+	}
+	if opts.LightingRadius != 0 {
+		field, object = flattenLighting(field, opts.LightingRadius), flattenLighting(object, opts.LightingRadius)
+	}
+	if opts.Blur != 0 {
+		field, object = blurInputs(field, object, ctx.SearchRect, opts.Blur)
+	}
+	if opts.Levels != 0 {
+		field, object = posterizePlanes(field, opts.Levels), posterizePlanes(object, opts.Levels)
+	}
+	if opts.EdgeWeighted {
+		ctx.Mask = combineMasks(ctx.Mask, edgeMask(object))
+	}
+	if opts.AdaptiveTolerance {
+		ctx.Contrast = localContrast(field, object[0].Rect, ctx.SearchRect)
+	}
+	return field, object
+}
+
+// score each field and object plane pair according to ctx.ScoreMode, and
+// return the hits found
+func (ctx objSearchContext) searchPlanes(field, object []*FloatImage) []Hit {
+	return ctx.hits(ctx.scores(field, object))
+}
+
+// return the scores of the object at each point of ctx.SearchRect according
+// to ctx.ScoreMode, before normalization
+func (ctx objSearchContext) scores(field, object []*FloatImage) []float64 {
+	if ctx.distanceScores() {
+		return ctx.distances(field, object)
+	}
+	return ctx.cvScores(field, object)
+}
+
+// return the hits in scores, as returned by ctx.scores
+func (ctx objSearchContext) hits(scores []float64) []Hit {
+	switch ctx.HitMode {
+	case HITMODE_LOCALMINIMA:
+		return ctx.localMinimaHits(scores)
+	case HITMODE_BESTK:
+		return ctx.bestKHits(scores)
+	}
+	if ctx.distanceScores() {
+		min, max := ctx.scoreRange(scores)
+		return ctx.findHits(scores, min, max)
+	}
+	return ctx.cvHits(scores)
+}
+
+// return true if ctx.ScoreMode scores hits by normalizing their distances
+func (ctx objSearchContext) distanceScores() bool {
+	return ctx.ScoreMode == SCOREMODE_L1 || ctx.ScoreMode == SCOREMODE_ZSCORE || ctx.ScoreMode == SCOREMODE_L1_ABSOLUTE
+}
+
+// return true if ctx.ScoreMode normalizes distances by the range of the
+// distances searched
+func (ctx objSearchContext) relativeScores() bool {
+	return ctx.ScoreMode == SCOREMODE_L1 || ctx.ScoreMode == SCOREMODE_ZSCORE
+}
+
+// return scores, as returned by ctx.scores, as hits are scored according to
+// ctx.ScoreMode. Returns nil if the scores cannot be normalized because they
+// are all equal.
+func (ctx objSearchContext) hitScores(scores []float64) []float64 {
+	if !ctx.distanceScores() {
+		return scores
+	}
+	min, max := ctx.scoreRange(scores)
+	if max <= min {
+		return nil
+	}
+	s := make([]float64, len(scores))
+	for i, v := range scores {
+		s[i] = (v - min) / (max - min)
+	}
+	return s
+}
+
+// return true if hit a scores better than hit b according to ctx.ScoreMode
+func (ctx objSearchContext) better(a, b float64) bool {
+	if ctx.ScoreMode == SCOREMODE_CCOEFF_NORMED {
+		return a > b
+	}
+	return a < b
+}
+
+// return the distances in d that are normalized to hit scores of 0 and 1
+// according to ctx.ScoreMode
+func (ctx objSearchContext) scoreRange(d []float64) (min, max float64) {
+	if ctx.ScoreRange != nil {
+		return ctx.ScoreRange[0], ctx.ScoreRange[1]
+	}
+	if ctx.ScoreMode == SCOREMODE_L1_ABSOLUTE {
+		return 0, 1
+	}
+	if ctx.ScoreMode == SCOREMODE_ZSCORE {
+		mean, sd := meanStdDev(d)
+		return mean, mean + sd
+	}
+	_, max = minMax(d)
+	return 0, max
+}
+
+// perform objSearch on each field and object plane pair, and return the
+// per-plane distances combined according to ctx.CombineMode and
+// ctx.ChannelWeights
+func (ctx objSearchContext) distances(field, object []*FloatImage) []float64 {
 	// perform objSearch on each intermediate image pair to get
 	// per-channel field-object distances
-	if len(interField) != len(interObject) {
+	if len(field) != len(object) || len(field) == 0 {
 		panic("internal error")
 	}
-	results := make([]objSearchResult, 0, len(interField))
-	for i := range interField {
-		results = append(results, ctx.objSearch(interField[i], interObject[i]))
-		if len(results[i].distances) != len(results[0].distances) {
+	results := make([]objSearchResult, 0, len(field))
+	for i := range field {
+		results = append(results, ctx.objSearch(field[i], object[i]))
+		if results[i].len() != results[0].len() {
 			// output results inconsistent
 			panic("internal error")
 		}
 	}
+	return ctx.combine(results)
+}
+
+// return the per-plane distances in results combined according to
+// ctx.CombineMode and ctx.ChannelWeights
+func (ctx objSearchContext) combine(results []objSearchResult) []float64 {
+	weights := make([]float64, len(results))
+	for i := range weights {
+		weights[i] = 1
+	}
+	if ctx.ChannelWeights != nil {
+		if len(ctx.ChannelWeights) != len(results) {
+			panic("wrong number of channel weights")
+		}
+		copy(weights, ctx.ChannelWeights)
+	}
 	// combine per-channel distances
-	combined := make([]float64, len(results[0].distances))
-	min := results[0].min
-	max := results[0].max
-	switch combineMode {
+	combined := make([]float64, results[0].len())
+	switch ctx.CombineMode {
 	case COMBINEMODE_MAX:
 		for j := range combined {
-			combined[j] = results[0].distances[j]
+			combined[j] = weights[0] * results[0].at(j)
 			for i := 1; i < len(results); i++ {
-				if combined[j] < results[i].distances[j] {
+				if d := weights[i] * results[i].at(j); combined[j] < d {
 					// replace with larger distance
-					combined[j] = results[i].distances[j]
+					combined[j] = d
 				}
 			}
-			if combined[j] < min {
-				min = combined[j]
-			}
-			if combined[j] > max {
-				max = combined[j]
+		}
+	case COMBINEMODE_MEAN:
+		w := ctx.channelWeight(len(results))
+		for j := range combined {
+			for i := range results {
+				combined[j] += weights[i] * results[i].at(j)
 			}
+			combined[j] /= w
 		}
 	default:
 		panic("invalid combine mode")
 	}
-	return ctx.findHits(combined, 0, max)
+	return combined
 }
 
 // The computed object-field distances, and the minimum and maximum distances
 // observed
 type objSearchResult struct {
 	distances []float64
-	min, max  float64
+	// the distances instead, if they are computed in float32
+	distances32 []float32
+	min, max    float64
 }
 
-func (ctx objSearchContext) objSearch(field, object *image.Gray) (res objSearchResult) {
-	// convert the pixel at (x,y) in img to a float64
-	float := func(img *image.Gray, x, y int) float64 {
-		return float64(img.GrayAt(x, y).Y) / 255.0
+func (ctx objSearchContext) objSearch(field, object *FloatImage) (res objSearchResult) {
+	// return the pixel at (x,y) in img
+	float := func(img *FloatImage, x, y int) float64 {
+		return img.FloatAt(x, y)
 	}
 	wg := sync.WaitGroup{}
-	res.distances = make([]float64, ctx.SearchRect.Dx()*ctx.SearchRect.Dy())
-	// compute the L1-norm distance between 'object' and and 'object'-sized
-	// rectangle of 'field' with top-left corner at (u,v) in 'field'
+	if ctx.Float32 {
+		res.distances32 = make([]float32, ctx.SearchRect.Dx()*ctx.SearchRect.Dy())
+	} else {
+		res.distances = make([]float64, ctx.SearchRect.Dx()*ctx.SearchRect.Dy())
+	}
+	// total weight of all object pixels, used to normalize distances to
+	// per-pixel averages
+	totalWeight := ctx.maskWeight(object.Rect)
+	if ctx.Trim < 0 || ctx.Trim >= 1 {
+		panic("trim out of range")
+	}
+	// compute the weighted L1-norm distance between 'object' and and
+	// 'object'-sized rectangle of 'field' with top-left corner at (u,v) in
+	// 'field'
 	//
 	// store result in res.distances[offset(u,v)]
 	objSearch1 := func(u, v int) {
 		result := 0.0
 		i := ctx.offset(u, v)
-		res.distances[i] = 0
+		if ctx.Trim > 0 || ctx.JPEG || !ctx.covered(object.Rect.Add(image.Point{u, v})) {
+			res.set(i, ctx.windowDistance(field, object, u, v))
+			wg.Done()
+			return
+		}
+		if ctx.Float32 {
+			res.distances32[i] = ctx.distance32(field, object, u, v) / float32(totalWeight)
+			wg.Done()
+			return
+		}
 		// Compute L1-norm
 		for x := object.Rect.Min.X; x < object.Rect.Max.X; x++ {
 			for y := object.Rect.Min.Y; y < object.Rect.Max.Y; y++ {
-				result += math.Abs(float(field, u+x, v+y) - float(object, x, y))
+				if ctx.Mask == nil {
+					result += math.Abs(float(field, u+x, v+y) - float(object, x, y))
+				} else if w := ctx.Mask.AlphaAt(x, y).A; w != 0 {
+					result += float64(w) / 255.0 * math.Abs(float(field, u+x, v+y)-float(object, x, y))
+				}
 			}
 		}
-		res.distances[i] = result
+		res.distances[i] = result / totalWeight
 		wg.Done()
 	}
 	ctx.verboseOut("\n")
@@ -145,16 +612,11 @@ func (ctx objSearchContext) objSearch(field, object *image.Gray) (res objSearchR
 		// start next column
 	}
 	ctx.verboseOut("\n")
-	// compute min and res.max of res.distances
-	res.min = res.distances[0]
-	res.max = res.distances[0]
-	for i := range res.distances {
-		if res.distances[i] < res.min {
-			res.min = res.distances[i]
-		}
-		if res.distances[i] > res.max {
-			res.max = res.distances[i]
-		}
+	res.fillUncovered()
+	if res.distances32 != nil {
+		res.min, res.max = minMax32(res.distances32)
+	} else {
+		res.min, res.max = minMax(res.distances)
 	}
 	// done
 	return
@@ -173,12 +635,13 @@ func (ctx objSearchContext) findHits(d []float64, min, max float64) (hits []Hit)
 	}
 	hitChan := make(chan Hit)
 	dRange := max - min
+	tolerance := ctx.tolerance()
 	go func() {
 		for i := range d {
 			// normalize L1 distances into interval [0,1] to compute each
 			// pixel's score
 			p := (d[i] - min) / dRange
-			if p < ctx.Tolerance {
+			if p < tolerance(i) {
 				// pixel's score is within tolerance, create a hit
 				x, y := ctx.coords(i)
 				hitChan <- Hit{image.Point{x, y}, p}
@@ -201,16 +664,158 @@ nextHit:
 		// h is a new hit
 		hits = append(hits, h)
 	}
-	// sort hits
-	sort.Slice(hits, func(i, j int) bool {
-		return hits[i].S < hits[j].S
-	})
+	SortByScore(hits)
 	return
 }
 
 ////
 // Utility functions
 
+// return the total weight of n channels
+func (ctx objSearchContext) channelWeight(n int) (w float64) {
+	if ctx.ChannelWeights == nil {
+		return float64(n)
+	}
+	for _, cw := range ctx.ChannelWeights {
+		w += cw
+	}
+	return
+}
+
+// return the minimum and maximum values in d
+func minMax(d []float64) (min, max float64) {
+	min, max = d[0], d[0]
+	for i := range d {
+		if d[i] < min {
+			min = d[i]
+		}
+		if d[i] > max {
+			max = d[i]
+		}
+	}
+	return
+}
+
+// return the mean and standard deviation of the values in d
+func meanStdDev(d []float64) (mean, sd float64) {
+	for _, v := range d {
+		mean += v
+	}
+	mean /= float64(len(d))
+	for _, v := range d {
+		sd += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sd / float64(len(d)))
+}
+
+// return the total weight of the object pixels in r
+func (ctx objSearchContext) maskWeight(r image.Rectangle) float64 {
+	if ctx.Mask == nil {
+		return float64(r.Dx() * r.Dy())
+	}
+	w := 0
+	for x := r.Min.X; x < r.Max.X; x++ {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			w += int(ctx.Mask.AlphaAt(x, y).A)
+		}
+	}
+	if w == 0 {
+		panic("object is fully transparent")
+	}
+	return float64(w) / 255.0
+}
+
+// return the alpha channel of img as a mask, or nil if img is fully opaque
+func alphaMask(img *image.RGBA) *image.Alpha {
+	if img.Opaque() {
+		return nil
+	}
+	m := image.NewAlpha(img.Rect)
+	for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+		for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+			m.Pix[m.PixOffset(x, y)] = img.Pix[img.PixOffset(x, y)+3]
+		}
+	}
+	return m
+}
+
+// return a mask excluding the pixels of img with color key, or nil if key is
+// nil
+func colorKeyMask(img *image.RGBA, key color.Color) *image.Alpha {
+	if key == nil {
+		return nil
+	}
+	k := color.RGBAModel.Convert(key).(color.RGBA)
+	m := image.NewAlpha(img.Rect)
+	for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+		for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+			if img.RGBAAt(x, y) != k {
+				m.SetAlpha(x, y, color.Alpha{255})
+			}
+		}
+	}
+	return m
+}
+
+// return m as an *image.Alpha covering r, or nil if m is nil
+func toMask(m image.Image, r image.Rectangle) *image.Alpha {
+	if m == nil {
+		return nil
+	}
+	if !r.In(m.Bounds()) {
+		panic("mask does not cover object")
+	}
+	a := image.NewAlpha(r)
+	for x := r.Min.X; x < r.Max.X; x++ {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			switch m := m.(type) {
+			case *image.Alpha:
+				a.SetAlpha(x, y, m.AlphaAt(x, y))
+			case *image.Gray:
+				a.SetAlpha(x, y, color.Alpha{m.GrayAt(x, y).Y})
+			default:
+				a.Set(x, y, color.AlphaModel.Convert(m.At(x, y)))
+			}
+		}
+	}
+	return a
+}
+
+// return the per-pixel product of masks a and b. nil masks are treated as
+// fully opaque.
+func combineMasks(a, b *image.Alpha) *image.Alpha {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	r := image.NewAlpha(a.Rect)
+	for i := range r.Pix {
+		r.Pix[i] = uint8(uint32(a.Pix[i]) * uint32(b.Pix[i]) / 255)
+	}
+	return r
+}
+
+// return an opaque copy of img with the premultiplied alpha of each pixel
+// divided out of its color channels
+func unpremultiply(img *image.RGBA) *image.RGBA {
+	r := image.NewRGBA(img.Rect)
+	for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+		for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+			c := img.RGBAAt(x, y)
+			if c.A != 0 && c.A != 255 {
+				c.R = uint8(uint32(c.R) * 255 / uint32(c.A))
+				c.G = uint8(uint32(c.G) * 255 / uint32(c.A))
+				c.B = uint8(uint32(c.B) * 255 / uint32(c.A))
+			}
+			c.A = 255
+			r.SetRGBA(x, y, c)
+		}
+	}
+	return r
+}
+
 // output only if verbose output desired
 func (ctx objSearchContext) verboseOut(format string, a ...interface{}) {
 	if ctx.VerboseOut != nil {
@@ -218,6 +823,19 @@ func (ctx objSearchContext) verboseOut(format string, a ...interface{}) {
 	}
 }
 
+// return the rectangle of top-left corners at which object lies entirely
+// within field
+func validRect(field, object image.Rectangle) image.Rectangle {
+	r := image.Rectangle{
+		field.Min.Sub(object.Min),
+		field.Max.Sub(object.Max).Add(image.Point{1, 1}),
+	}
+	if r.Empty() {
+		return image.Rectangle{}
+	}
+	return r
+}
+
 // return the slice index corresponding to (x,y) in the search rectangle
 //
 // the inverse of coords
@@ -2,11 +2,11 @@ package objsearch
 
 import (
 	"image"
+	"image/color"
 	"image/draw"
-	"math/rand"
 	"testing"
 
-	"github.com/hypoactiv/imutil"
+	"github.com/hypoactiv/objsearch/objsearchtest"
 )
 
 func TestCoordTransform(t *testing.T) {
@@ -32,22 +32,8 @@ func TestCoordTransform(t *testing.T) {
 	}
 }
 
-func randomGrayImage(w, h int) (r *image.Gray) {
-	r = image.NewGray(image.Rect(0, 0, w, h))
-	for x := 0; x < w; x++ {
-		for y := 0; y < h; y++ {
-			r.Pix[r.PixOffset(x, y)] = uint8(rand.Intn(256))
-		}
-	}
-	return
-}
-
 func randomRGBImage(w, h int) (r *image.RGBA) {
-	return imutil.CombineRGB(
-		randomGrayImage(w, h),
-		randomGrayImage(w, h),
-		randomGrayImage(w, h),
-	)
+	return (&objsearchtest.Generator{}).Noise(w, h)
 }
 
 func TestFindHits(t *testing.T) {
@@ -84,3 +70,97 @@ func TestObjSearch(t *testing.T) {
 		t.Fatal("objSearch error")
 	}
 }
+
+// test that transparent object pixels are ignored
+func TestAlphaMask(t *testing.T) {
+	field := randomRGBImage(60, 60)
+	object := randomRGBImage(10, 10)
+	// make the left half of object transparent
+	for x := 0; x < 5; x++ {
+		for y := 0; y < 10; y++ {
+			object.SetRGBA(x, y, color.RGBA{})
+		}
+	}
+	draw.Draw(field, object.Bounds().Add(image.Point{30, 20}), object, image.ZP, draw.Over)
+	h := Search(field, object, image.Rect(0, 0, 50, 50), 0.1, 10, nil, COLORMODE_RGB, COMBINEMODE_MAX)
+	if len(h) == 0 || h[0] != (Hit{image.Point{30, 20}, 0}) {
+		t.Error(h)
+		t.Fatal("alpha mask error")
+	}
+}
+
+// test that pixels outside an explicit mask are ignored
+func TestExplicitMask(t *testing.T) {
+	field := randomRGBImage(60, 60)
+	object := randomRGBImage(12, 12)
+	draw.Draw(field, object.Bounds().Add(image.Point{15, 25}), object, image.ZP, draw.Src)
+	// alter the center of the placed object, as with variable text in a dialog
+	inner := image.Rect(3, 3, 9, 9)
+	draw.Draw(field, inner.Add(image.Point{15, 25}), randomRGBImage(6, 6), image.ZP, draw.Src)
+	mask := image.NewGray(object.Bounds())
+	draw.Draw(mask, mask.Bounds(), image.White, image.ZP, draw.Src)
+	draw.Draw(mask, inner, image.Black, image.ZP, draw.Src)
+	h := SearchWithOptions(field, object, image.Rect(0, 0, 48, 48), Options{
+		Tolerance: 0.1,
+		MinDist:   10,
+		ColorMode: COLORMODE_RGB,
+		Mask:      mask,
+	})
+	if len(h) == 0 || h[0] != (Hit{image.Point{15, 25}, 0}) {
+		t.Error(h)
+		t.Fatal("explicit mask error")
+	}
+}
+
+// test that color-keyed object pixels are ignored
+func TestColorKey(t *testing.T) {
+	magenta := color.RGBA{255, 0, 255, 255}
+	field := randomRGBImage(60, 60)
+	object := randomRGBImage(10, 10)
+	draw.Draw(field, object.Bounds().Add(image.Point{40, 5}), object, image.ZP, draw.Src)
+	// key out a border of object, which does not match the field
+	for i := 0; i < 10; i++ {
+		object.SetRGBA(i, 0, magenta)
+		object.SetRGBA(i, 9, magenta)
+		object.SetRGBA(0, i, magenta)
+		object.SetRGBA(9, i, magenta)
+	}
+	h := SearchWithOptions(field, object, image.Rect(0, 0, 50, 50), Options{
+		Tolerance: 0.1,
+		MinDist:   10,
+		ColorKey:  magenta,
+	})
+	if len(h) == 0 || h[0] != (Hit{image.Point{40, 5}, 0}) {
+		t.Error(h)
+		t.Fatal("color key error")
+	}
+}
+
+// return a copy of the r portion of img, with bounds at the origin
+func crop(img image.Image, r image.Rectangle) *image.RGBA {
+	c := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(c, c.Rect, img, r.Min, draw.Src)
+	return c
+}
+
+// test that a partially obscured object scores as well as an exact one with
+// Options.Trim
+func TestTrim(t *testing.T) {
+	field := randomRGBImage(100, 100)
+	object := randomRGBImage(10, 10)
+	// 16% of the object at 20,30 is obscured
+	draw.Draw(field, object.Bounds().Add(image.Point{20, 30}), object, image.ZP, draw.Src)
+	draw.Draw(field, object.Bounds().Add(image.Point{26, 36}), object, image.ZP, draw.Src)
+	opts := Options{Tolerance: 0.05, MinDist: 5, Trim: 0.2}
+	rect := validRect(field.Bounds(), object.Bounds())
+	h := SearchImage(field, object, rect, opts)
+	SortRaster(h)
+	if len(h) != 2 || h[0] != (Hit{image.Point{20, 30}, 0}) || h[1] != (Hit{image.Point{26, 36}, 0}) {
+		t.Fatal("trimmed search error", h)
+	}
+	opts.Trim = 0
+	h = SearchImage(field, object, rect, opts)
+	if len(h) != 1 || h[0].P != (image.Point{26, 36}) {
+		t.Fatal("untrimmed search error", h)
+	}
+}
@@ -0,0 +1,136 @@
+package objsearchpb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/hypoactiv/objsearch"
+)
+
+// Returned by Decode when a request's template labels are not unique
+var ErrDuplicateLabel = errors.New("objsearchpb: duplicate template label")
+
+// Returns h as a message
+func FromHit(h objsearch.Hit) *Hit {
+	return &Hit{X: int32(h.P.X), Y: int32(h.P.Y), Score: h.S}
+}
+
+// Returns h as a message
+func FromLabeledHit(h objsearch.LabeledHit) *Hit {
+	m := FromHit(h.Hit)
+	m.Label = h.Label
+	return m
+}
+
+// Returns the Hit described by m
+func (m *Hit) Hit() objsearch.Hit {
+	return objsearch.Hit{P: image.Point{int(m.X), int(m.Y)}, S: m.Score}
+}
+
+// Returns the LabeledHit described by m
+func (m *Hit) LabeledHit() objsearch.LabeledHit {
+	return objsearch.LabeledHit{Hit: m.Hit(), Label: m.Label}
+}
+
+// Returns r as a message
+func FromRect(r image.Rectangle) *Rect {
+	return &Rect{int32(r.Min.X), int32(r.Min.Y), int32(r.Max.X), int32(r.Max.Y)}
+}
+
+// Returns the rectangle described by m
+func (m *Rect) Rectangle() image.Rectangle {
+	return image.Rect(int(m.MinX), int(m.MinY), int(m.MaxX), int(m.MaxY))
+}
+
+// Returns the Tolerance, MinDist, ColorMode and CombineMode of opts as a
+// message. Other options are not represented.
+func FromOptions(opts objsearch.Options) *Options {
+	return &Options{
+		Tolerance:   opts.Tolerance,
+		MinDist:     int32(opts.MinDist),
+		ColorMode:   ColorMode(opts.ColorMode),
+		CombineMode: CombineMode(opts.CombineMode),
+	}
+}
+
+// Returns the Options described by m, or an error if its color or combine
+// mode is unknown
+func (m *Options) Options() (objsearch.Options, error) {
+	switch m.ColorMode {
+	case ColorMode_COLOR_MODE_GRAY, ColorMode_COLOR_MODE_RGB:
+	default:
+		return objsearch.Options{}, fmt.Errorf("objsearchpb: unknown color mode %d", m.ColorMode)
+	}
+	switch m.CombineMode {
+	case CombineMode_COMBINE_MODE_MAX, CombineMode_COMBINE_MODE_MEAN:
+	default:
+		return objsearch.Options{}, fmt.Errorf("objsearchpb: unknown combine mode %d", m.CombineMode)
+	}
+	return objsearch.Options{
+		Tolerance:   m.Tolerance,
+		MinDist:     int(m.MinDist),
+		ColorMode:   objsearch.ColorMode(m.ColorMode),
+		CombineMode: objsearch.CombineMode(m.CombineMode),
+	}, nil
+}
+
+// Returns hits as a SearchResult
+func FromLabeledHits(hits []objsearch.LabeledHit) *SearchResult {
+	r := &SearchResult{}
+	for _, h := range hits {
+		r.Hits = append(r.Hits, FromLabeledHit(h))
+	}
+	return r
+}
+
+// Returns the hits of m
+func (m *SearchResult) LabeledHits() []objsearch.LabeledHit {
+	hits := make([]objsearch.LabeledHit, len(m.Hits))
+	for i, h := range m.Hits {
+		hits[i] = h.LabeledHit()
+	}
+	return hits
+}
+
+// Decodes the field and templates of m. The templates are registered in the
+// returned TemplateSet under their labels, and if m.Rect is set, are searched
+// for only at the top-left corners within it. Returns ErrDuplicateLabel if
+// two templates share a label.
+func (m *SearchRequest) Decode() (*objsearch.Field, *objsearch.TemplateSet, error) {
+	field, err := objsearch.LoadField(bytes.NewReader(m.Field))
+	if err != nil {
+		return nil, nil, err
+	}
+	opts := objsearch.Options{}
+	if m.Options != nil {
+		if opts, err = m.Options.Options(); err != nil {
+			return nil, nil, err
+		}
+	}
+	set := objsearch.NewTemplateSet(opts)
+	for _, t := range m.Templates {
+		if set.Searcher(t.Label) != nil {
+			return nil, nil, ErrDuplicateLabel
+		}
+		object, err := objsearch.LoadObject(bytes.NewReader(t.Image))
+		if err != nil {
+			return nil, nil, err
+		}
+		set.Add(t.Label, object)
+		if m.Rect != nil {
+			set.Searcher(t.Label).Rect = m.Rect.Rectangle()
+		}
+	}
+	return field, set, nil
+}
+
+// Decodes m as Decode does, and performs the search it describes
+func (m *SearchRequest) Search() ([]objsearch.LabeledHit, error) {
+	field, set, err := m.Decode()
+	if err != nil {
+		return nil, err
+	}
+	return set.SearchAll(field), nil
+}
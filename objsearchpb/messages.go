@@ -0,0 +1,260 @@
+// Package objsearchpb provides Go types for the protobuf messages defined in
+// objsearch.proto, and converters between them and objsearch's types, so that
+// searches and their results can be sent between services or queued.
+//
+// The types encode and decode the protobuf wire format themselves, without
+// depending on a protobuf runtime, and interoperate with code generated from
+// objsearch.proto in any language.
+package objsearchpb
+
+// Enumerated color modes, as in objsearch.ColorMode
+type ColorMode int32
+
+const (
+	ColorMode_COLOR_MODE_GRAY ColorMode = 0
+	ColorMode_COLOR_MODE_RGB  ColorMode = 1
+)
+
+// Enumerated combine modes, as in objsearch.CombineMode
+type CombineMode int32
+
+const (
+	CombineMode_COMBINE_MODE_MAX  CombineMode = 0
+	CombineMode_COMBINE_MODE_MEAN CombineMode = 1
+)
+
+type Hit struct {
+	X, Y  int32
+	Score float64
+	Label string
+}
+
+func (m *Hit) Marshal() []byte {
+	e := encoder{}
+	e.int(1, int64(m.X))
+	e.int(2, int64(m.Y))
+	e.double(3, m.Score)
+	e.bytes(4, []byte(m.Label))
+	return e.b
+}
+
+func (m *Hit) Unmarshal(b []byte) error {
+	*m = Hit{}
+	return decode(b, func(f wireField) error {
+		switch {
+		case f.field == 1 && f.wire == wireVarint:
+			m.X = f.int32()
+		case f.field == 2 && f.wire == wireVarint:
+			m.Y = f.int32()
+		case f.field == 3 && f.wire == wireFixed64:
+			m.Score = f.double()
+		case f.field == 4 && f.wire == wireBytes:
+			m.Label = string(f.data)
+		}
+		return nil
+	})
+}
+
+type Rect struct {
+	MinX, MinY, MaxX, MaxY int32
+}
+
+func (m *Rect) Marshal() []byte {
+	e := encoder{}
+	e.int(1, int64(m.MinX))
+	e.int(2, int64(m.MinY))
+	e.int(3, int64(m.MaxX))
+	e.int(4, int64(m.MaxY))
+	return e.b
+}
+
+func (m *Rect) Unmarshal(b []byte) error {
+	*m = Rect{}
+	return decode(b, func(f wireField) error {
+		if f.wire != wireVarint {
+			return nil
+		}
+		switch f.field {
+		case 1:
+			m.MinX = f.int32()
+		case 2:
+			m.MinY = f.int32()
+		case 3:
+			m.MaxX = f.int32()
+		case 4:
+			m.MaxY = f.int32()
+		}
+		return nil
+	})
+}
+
+type Options struct {
+	Tolerance   float64
+	MinDist     int32
+	ColorMode   ColorMode
+	CombineMode CombineMode
+}
+
+func (m *Options) Marshal() []byte {
+	e := encoder{}
+	e.double(1, m.Tolerance)
+	e.int(2, int64(m.MinDist))
+	e.int(3, int64(m.ColorMode))
+	e.int(4, int64(m.CombineMode))
+	return e.b
+}
+
+func (m *Options) Unmarshal(b []byte) error {
+	*m = Options{}
+	return decode(b, func(f wireField) error {
+		switch {
+		case f.field == 1 && f.wire == wireFixed64:
+			m.Tolerance = f.double()
+		case f.field == 2 && f.wire == wireVarint:
+			m.MinDist = f.int32()
+		case f.field == 3 && f.wire == wireVarint:
+			m.ColorMode = ColorMode(f.int32())
+		case f.field == 4 && f.wire == wireVarint:
+			m.CombineMode = CombineMode(f.int32())
+		}
+		return nil
+	})
+}
+
+type Template struct {
+	Label string
+	// PNG, JPEG or GIF encoded image
+	Image []byte
+}
+
+func (m *Template) Marshal() []byte {
+	e := encoder{}
+	e.bytes(1, []byte(m.Label))
+	e.bytes(2, m.Image)
+	return e.b
+}
+
+func (m *Template) Unmarshal(b []byte) error {
+	*m = Template{}
+	return decode(b, func(f wireField) error {
+		switch {
+		case f.field == 1 && f.wire == wireBytes:
+			m.Label = string(f.data)
+		case f.field == 2 && f.wire == wireBytes:
+			m.Image = append([]byte{}, f.data...)
+		}
+		return nil
+	})
+}
+
+type SearchRequest struct {
+	// PNG, JPEG or GIF encoded image
+	Field     []byte
+	Templates []*Template
+	Options   *Options
+	// Top-left corners to search. If nil, the whole field is searched.
+	Rect *Rect
+}
+
+func (m *SearchRequest) Marshal() []byte {
+	e := encoder{}
+	e.bytes(1, m.Field)
+	for _, t := range m.Templates {
+		e.message(2, t.Marshal())
+	}
+	if m.Options != nil {
+		e.message(3, m.Options.Marshal())
+	}
+	if m.Rect != nil {
+		e.message(4, m.Rect.Marshal())
+	}
+	return e.b
+}
+
+func (m *SearchRequest) Unmarshal(b []byte) error {
+	*m = SearchRequest{}
+	return decode(b, func(f wireField) error {
+		if f.wire != wireBytes {
+			return nil
+		}
+		switch f.field {
+		case 1:
+			m.Field = append([]byte{}, f.data...)
+		case 2:
+			t := &Template{}
+			m.Templates = append(m.Templates, t)
+			return t.Unmarshal(f.data)
+		case 3:
+			m.Options = &Options{}
+			return m.Options.Unmarshal(f.data)
+		case 4:
+			m.Rect = &Rect{}
+			return m.Rect.Unmarshal(f.data)
+		}
+		return nil
+	})
+}
+
+type SearchResult struct {
+	Hits []*Hit
+	// Set if the search failed
+	Error string
+}
+
+func (m *SearchResult) Marshal() []byte {
+	e := encoder{}
+	for _, h := range m.Hits {
+		e.message(1, h.Marshal())
+	}
+	e.bytes(2, []byte(m.Error))
+	return e.b
+}
+
+func (m *SearchResult) Unmarshal(b []byte) error {
+	*m = SearchResult{}
+	return decode(b, func(f wireField) error {
+		if f.wire != wireBytes {
+			return nil
+		}
+		switch f.field {
+		case 1:
+			h := &Hit{}
+			m.Hits = append(m.Hits, h)
+			return h.Unmarshal(f.data)
+		case 2:
+			m.Error = string(f.data)
+		}
+		return nil
+	})
+}
+
+type SearchUpdate struct {
+	// Fraction of the search completed, in [0,1]
+	Progress float64
+	// Hits found since the previous update
+	Hits []*Hit
+}
+
+func (m *SearchUpdate) Marshal() []byte {
+	e := encoder{}
+	e.double(1, m.Progress)
+	for _, h := range m.Hits {
+		e.message(2, h.Marshal())
+	}
+	return e.b
+}
+
+func (m *SearchUpdate) Unmarshal(b []byte) error {
+	*m = SearchUpdate{}
+	return decode(b, func(f wireField) error {
+		switch {
+		case f.field == 1 && f.wire == wireFixed64:
+			m.Progress = f.double()
+		case f.field == 2 && f.wire == wireBytes:
+			h := &Hit{}
+			m.Hits = append(m.Hits, h)
+			return h.Unmarshal(f.data)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,85 @@
+package objsearchpb
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/png"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/hypoactiv/objsearch"
+)
+
+func TestHitWireFormat(t *testing.T) {
+	h := &Hit{X: 1, Y: -1, Score: 0.5, Label: "a"}
+	// as encoded by the reference protobuf implementation
+	want := []byte{
+		0x08, 0x01,
+		0x10, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01,
+		0x19, 0, 0, 0, 0, 0, 0, 0xe0, 0x3f,
+		0x22, 0x01, 'a',
+	}
+	if b := h.Marshal(); !bytes.Equal(b, want) {
+		t.Fatalf("hit encoding error % x", b)
+	}
+	d := &Hit{}
+	// unknown fields are skipped
+	if err := d.Unmarshal(append(want, 0x28, 0x07)); err != nil || *d != *h {
+		t.Fatal("hit decoding error", d, err)
+	}
+	if err := d.Unmarshal(want[:5]); err == nil {
+		t.Fatal("expected truncation error")
+	}
+}
+
+func encodePNG(img image.Image) []byte {
+	b := &bytes.Buffer{}
+	png.Encode(b, img)
+	return b.Bytes()
+}
+
+func TestSearchRequest(t *testing.T) {
+	field := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	for i := range field.Pix {
+		field.Pix[i] = uint8(rand.Intn(256))
+		if i%4 == 3 {
+			field.Pix[i] = 255
+		}
+	}
+	object := image.NewRGBA(image.Rect(0, 0, 6, 6))
+	draw.Draw(object, object.Rect, field, image.Point{12, 20}, draw.Src)
+	req := &SearchRequest{
+		Field:     encodePNG(field),
+		Templates: []*Template{{Label: "obj", Image: encodePNG(object)}},
+		Options:   FromOptions(objsearch.Options{Tolerance: 0.01, MinDist: 5, ColorMode: objsearch.COLORMODE_RGB}),
+		Rect:      FromRect(image.Rect(10, 10, 30, 30)),
+	}
+	var d SearchRequest
+	if err := d.Unmarshal(req.Marshal()); err != nil || !reflect.DeepEqual(&d, req) {
+		t.Fatal("request round trip error", err)
+	}
+	hits, err := d.Search()
+	if err != nil || len(hits) != 1 || hits[0].P != (image.Point{12, 20}) || hits[0].Label != "obj" {
+		t.Fatal("search error", hits, err)
+	}
+	var r SearchResult
+	if err := r.Unmarshal(FromLabeledHits(hits).Marshal()); err != nil || !reflect.DeepEqual(r.LabeledHits(), hits) {
+		t.Fatal("result round trip error", r, err)
+	}
+	d.Templates = append(d.Templates, d.Templates[0])
+	if _, _, err := d.Decode(); err != ErrDuplicateLabel {
+		t.Fatal("expected ErrDuplicateLabel", err)
+	}
+	d.Templates = d.Templates[:1]
+	d.Options.ColorMode = 7
+	if _, _, err := d.Decode(); err == nil {
+		t.Fatal("expected unknown color mode error")
+	}
+	d.Options.ColorMode = ColorMode_COLOR_MODE_GRAY
+	d.Options.CombineMode = -1
+	if _, _, err := d.Decode(); err == nil {
+		t.Fatal("expected unknown combine mode error")
+	}
+}
@@ -0,0 +1,112 @@
+package objsearchpb
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("objsearchpb: truncated message")
+
+// appends protobuf fields to b. Fields with zero values are omitted, as in
+// proto3.
+type encoder struct {
+	b []byte
+}
+
+func (e *encoder) tag(field, wire int) {
+	e.b = binary.AppendUvarint(e.b, uint64(field)<<3|uint64(wire))
+}
+
+func (e *encoder) int(field int, v int64) {
+	if v != 0 {
+		e.tag(field, wireVarint)
+		e.b = binary.AppendUvarint(e.b, uint64(v))
+	}
+}
+
+func (e *encoder) double(field int, v float64) {
+	if v != 0 {
+		e.tag(field, wireFixed64)
+		e.b = binary.LittleEndian.AppendUint64(e.b, math.Float64bits(v))
+	}
+}
+
+func (e *encoder) bytes(field int, v []byte) {
+	if len(v) != 0 {
+		e.message(field, v)
+	}
+}
+
+// append a length-delimited field, even if empty
+func (e *encoder) message(field int, v []byte) {
+	e.tag(field, wireBytes)
+	e.b = binary.AppendUvarint(e.b, uint64(len(v)))
+	e.b = append(e.b, v...)
+}
+
+// a decoded protobuf field. v holds varint and fixed values, and data holds
+// length-delimited values.
+type wireField struct {
+	field, wire int
+	v           uint64
+	data        []byte
+}
+
+func (f wireField) int32() int32 {
+	return int32(f.v)
+}
+
+func (f wireField) double() float64 {
+	return math.Float64frombits(f.v)
+}
+
+// call fn with each field of the message in b
+func decode(b []byte, fn func(wireField) error) error {
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errTruncated
+		}
+		b = b[n:]
+		f := wireField{field: int(tag >> 3), wire: int(tag & 7)}
+		switch f.wire {
+		case wireVarint:
+			f.v, n = binary.Uvarint(b)
+			if n <= 0 {
+				return errTruncated
+			}
+			b = b[n:]
+		case wireFixed64:
+			if len(b) < 8 {
+				return errTruncated
+			}
+			f.v, b = binary.LittleEndian.Uint64(b), b[8:]
+		case wireFixed32:
+			if len(b) < 4 {
+				return errTruncated
+			}
+			f.v, b = uint64(binary.LittleEndian.Uint32(b)), b[4:]
+		case wireBytes:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < l {
+				return errTruncated
+			}
+			f.data, b = b[n:n+int(l)], b[n+int(l):]
+		default:
+			return errors.New("objsearchpb: unsupported wire type")
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package objsearchtest
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"math"
+)
+
+// Returns a copy of img with Gaussian noise of standard deviation stddev, in
+// 8-bit units, added to every color channel
+func (g *Generator) Noisy(img image.Image, stddev float64) *image.RGBA {
+	r := toRGBA(img)
+	g.AddNoise(r, stddev)
+	return r
+}
+
+// Returns img after encoding and decoding it as a JPEG of the given quality,
+// from 1 to 100
+func Recompress(img image.Image, quality int) (*image.RGBA, error) {
+	var b bytes.Buffer
+	if err := jpeg.Encode(&b, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	d, err := jpeg.Decode(&b)
+	if err != nil {
+		return nil, err
+	}
+	// jpeg.Decode returns an image at the origin
+	r := image.NewRGBA(img.Bounds())
+	draw.Draw(r, r.Rect, d, d.Bounds().Min, draw.Src)
+	return r, nil
+}
+
+// Returns a copy of img blurred by a Gaussian kernel of standard deviation
+// sigma pixels. Pixels beyond the edges of img are taken from the nearest
+// edge.
+func Blur(img image.Image, sigma float64) *image.RGBA {
+	src := toRGBA(img)
+	if sigma <= 0 {
+		return src
+	}
+	radius := int(math.Ceil(3 * sigma))
+	kernel := make([]float64, 2*radius+1)
+	sum := 0.0
+	for i := range kernel {
+		x := float64(i - radius)
+		kernel[i] = math.Exp(-x * x / (2 * sigma * sigma))
+		sum += kernel[i]
+	}
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+	// blur rows, then columns
+	tmp := convolve(src, kernel, image.Point{1, 0})
+	return convolve(tmp, kernel, image.Point{0, 1})
+}
+
+// Returns a copy of img with delta, in 8-bit units, added to every color
+// channel
+func Brighten(img image.Image, delta float64) *image.RGBA {
+	r := toRGBA(img)
+	for i := range r.Pix {
+		if i%4 != 3 {
+			r.Pix[i] = clamp(float64(r.Pix[i]) + delta)
+		}
+	}
+	return r
+}
+
+// return img convolved with kernel along direction d, which is (1,0) or
+// (0,1)
+func convolve(img *image.RGBA, kernel []float64, d image.Point) *image.RGBA {
+	b := img.Rect
+	r := image.NewRGBA(b)
+	radius := len(kernel) / 2
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			var acc [4]float64
+			for k, w := range kernel {
+				p := image.Point{x, y}.Add(d.Mul(k - radius))
+				p = nearestIn(p, b)
+				i := img.PixOffset(p.X, p.Y)
+				for c := range acc {
+					acc[c] += w * float64(img.Pix[i+c])
+				}
+			}
+			i := r.PixOffset(x, y)
+			for c := range acc {
+				r.Pix[i+c] = clamp(acc[c])
+			}
+		}
+	}
+	return r
+}
+
+// return the point of r nearest p
+func nearestIn(p image.Point, r image.Rectangle) image.Point {
+	if p.X < r.Min.X {
+		p.X = r.Min.X
+	} else if p.X >= r.Max.X {
+		p.X = r.Max.X - 1
+	}
+	if p.Y < r.Min.Y {
+		p.Y = r.Min.Y
+	} else if p.Y >= r.Max.Y {
+		p.Y = r.Max.Y - 1
+	}
+	return p
+}
+
+// return a copy of img as an *image.RGBA with the same bounds
+func toRGBA(img image.Image) *image.RGBA {
+	r := image.NewRGBA(img.Bounds())
+	draw.Draw(r, r.Rect, img, r.Rect.Min, draw.Src)
+	return r
+}
@@ -0,0 +1,52 @@
+package objsearchtest
+
+import (
+	"image"
+	"testing"
+)
+
+// return the mean absolute difference between the color channels of a and b
+func meanDiff(a, b *image.RGBA) float64 {
+	sum, n := 0.0, 0
+	for i := range a.Pix {
+		if i%4 != 3 {
+			d := float64(a.Pix[i]) - float64(b.Pix[i])
+			if d < 0 {
+				d = -d
+			}
+			sum += d
+			n++
+		}
+	}
+	return sum / float64(n)
+}
+
+func TestDistortions(t *testing.T) {
+	g := NewGenerator(1)
+	img := g.Noise(32, 24)
+	sub := img.SubImage(image.Rect(4, 4, 20, 20))
+	if b := Blur(sub, 0); b.Rect != sub.Bounds() || meanDiff(b, toRGBA(sub)) != 0 {
+		t.Fatal("zero blur should copy")
+	}
+	if meanDiff(Blur(img, 2), img) < 20 {
+		t.Fatal("blur error")
+	}
+	// a blurred solid image is unchanged
+	flat := g.Clutter(10, 10, 0)
+	if meanDiff(Blur(flat, 1.5), flat) != 0 {
+		t.Fatal("blur changed a solid image")
+	}
+	if b := Brighten(flat, 300); b.Pix[0] != 255 || b.Pix[3] != 255 {
+		t.Fatal("brighten error")
+	}
+	j, err := Recompress(sub, 50)
+	if err != nil || j.Rect != sub.Bounds() {
+		t.Fatal("recompress error", err)
+	}
+	if d := meanDiff(j, toRGBA(sub)); d == 0 || d > 60 {
+		t.Fatal("recompression difference error", d)
+	}
+	if d := meanDiff(g.Noisy(img, 5), img); d < 2 || d > 6 {
+		t.Fatal("noise error", d)
+	}
+}
@@ -0,0 +1,219 @@
+// Package objsearchtest generates synthetic fields for testing object
+// searches. Templates are composed into generated backgrounds with
+// controllable noise, occlusion, scale jitter and clutter, and the
+// ground-truth placements are returned alongside the field. Noise, JPEG
+// recompression, blur and brightness shifts can also be applied to fields and
+// templates, to measure how robust search options are to them.
+package objsearchtest
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"math/rand"
+)
+
+// Generates random images. The zero value uses the default source of
+// math/rand.
+type Generator struct {
+	// If not nil, the source of randomness, e.g. rand.New(rand.NewSource(1))
+	// for reproducible scenes
+	Rand *rand.Rand
+}
+
+// Returns a Generator seeded with seed
+func NewGenerator(seed int64) *Generator {
+	return &Generator{Rand: rand.New(rand.NewSource(seed))}
+}
+
+// Parameters of a generated scene
+type SceneOptions struct {
+	// Dimensions of the field
+	Width, Height int
+	// Number of times each template is placed
+	Count int
+	// If not zero, the background is filled with this many random solid
+	// rectangles instead of random noise
+	Clutter int
+	// Standard deviation of the Gaussian noise added to every channel of
+	// the field, in 8-bit units
+	Noise float64
+	// If not zero, a random corner of each placement, covering up to this
+	// fraction of its area, is covered by a solid rectangle
+	Occlusion float64
+	// If not zero, each placement is scaled by a random factor in
+	// [1-ScaleJitter, 1+ScaleJitter]
+	ScaleJitter float64
+	// If true, placements may overlap
+	Overlap bool
+}
+
+// A template placed in a generated scene
+type Placement struct {
+	// Index of the template
+	Template int
+	// Bounds of the placed template in the field. Rect.Min is its top-left
+	// corner.
+	Rect image.Rectangle
+	// Scale factor applied to the template
+	Scale float64
+	// Fraction of the placed template's area that is occluded
+	Occluded float64
+}
+
+// maximum number of random positions tried for each placement
+const placementAttempts = 100
+
+// Returns a field with each of templates placed opts.Count times at random
+// positions, and the placements made, in the order they were made. Fewer
+// placements are made if the field is too crowded to fit them.
+func (g *Generator) Scene(templates []image.Image, opts SceneOptions) (*image.RGBA, []Placement) {
+	var field *image.RGBA
+	if opts.Clutter == 0 {
+		field = g.Noise(opts.Width, opts.Height)
+	} else {
+		field = g.Clutter(opts.Width, opts.Height, opts.Clutter)
+	}
+	var placements []Placement
+	for n := 0; n < opts.Count; n++ {
+		for i, t := range templates {
+			scale := 1.0
+			if opts.ScaleJitter != 0 {
+				scale += opts.ScaleJitter * (2*g.float64() - 1)
+			}
+			img := Scale(t, scale)
+			size := img.Bounds().Size()
+			if size.X > opts.Width || size.Y > opts.Height {
+				continue
+			}
+		attempts:
+			for a := 0; a < placementAttempts; a++ {
+				p := image.Point{g.intn(opts.Width - size.X + 1), g.intn(opts.Height - size.Y + 1)}
+				r := image.Rectangle{p, p.Add(size)}
+				if !opts.Overlap {
+					for _, q := range placements {
+						if q.Rect.Overlaps(r) {
+							continue attempts
+						}
+					}
+				}
+				draw.Draw(field, r, img, img.Bounds().Min, draw.Over)
+				pl := Placement{Template: i, Rect: r, Scale: scale}
+				if opts.Occlusion != 0 {
+					pl.Occluded = g.occlude(field, r, opts.Occlusion)
+				}
+				placements = append(placements, pl)
+				break
+			}
+		}
+	}
+	if opts.Noise != 0 {
+		g.AddNoise(field, opts.Noise)
+	}
+	return field, placements
+}
+
+// Returns a w by h opaque image of uniformly random pixels
+func (g *Generator) Noise(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		if i%4 == 3 {
+			img.Pix[i] = 255
+		} else {
+			img.Pix[i] = uint8(g.intn(256))
+		}
+	}
+	return img
+}
+
+// Returns a w by h opaque image of a random color, overlaid with n solid
+// rectangles of random colors, positions and sizes
+func (g *Generator) Clutter(w, h, n int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Rect, image.NewUniform(g.color()), image.Point{}, draw.Src)
+	for i := 0; i < n; i++ {
+		x0, y0 := g.intn(w), g.intn(h)
+		r := image.Rect(x0, y0, x0+1+g.intn(w/4+1), y0+1+g.intn(h/4+1))
+		draw.Draw(img, r, image.NewUniform(g.color()), image.Point{}, draw.Src)
+	}
+	return img
+}
+
+// Adds Gaussian noise with standard deviation stddev to every color channel
+// of img
+func (g *Generator) AddNoise(img *image.RGBA, stddev float64) {
+	for i := range img.Pix {
+		if i%4 != 3 {
+			img.Pix[i] = clamp(float64(img.Pix[i]) + stddev*g.normFloat64())
+		}
+	}
+}
+
+// Returns img scaled by s with nearest-neighbor sampling. The result has its
+// top-left corner at the origin and is at least 1x1.
+func Scale(img image.Image, s float64) *image.RGBA {
+	b := img.Bounds()
+	w := int(math.Max(1, math.Round(float64(b.Dx())*s)))
+	h := int(math.Max(1, math.Round(float64(b.Dy())*s)))
+	r := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			r.Set(x, y, img.At(b.Min.X+x*b.Dx()/w, b.Min.Y+y*b.Dy()/h))
+		}
+	}
+	return r
+}
+
+// cover a random corner of r in img, up to fraction f of its area, with a
+// random color, and return the fraction covered
+func (g *Generator) occlude(img *image.RGBA, r image.Rectangle, f float64) float64 {
+	area := math.Min(f, 1) * g.float64() * float64(r.Dx()*r.Dy())
+	// a rectangle of r's aspect ratio with the chosen area
+	w := int(math.Round(math.Sqrt(area * float64(r.Dx()) / float64(r.Dy()))))
+	h := int(math.Round(math.Sqrt(area * float64(r.Dy()) / float64(r.Dx()))))
+	o := image.Rect(0, 0, w, h)
+	switch g.intn(4) {
+	case 0:
+		o = o.Add(r.Min)
+	case 1:
+		o = o.Add(image.Point{r.Max.X - w, r.Min.Y})
+	case 2:
+		o = o.Add(image.Point{r.Min.X, r.Max.Y - h})
+	default:
+		o = o.Add(r.Max.Sub(image.Point{w, h}))
+	}
+	draw.Draw(img, o, image.NewUniform(g.color()), image.Point{}, draw.Src)
+	return float64(w*h) / float64(r.Dx()*r.Dy())
+}
+
+// return a random opaque color
+func (g *Generator) color() color.RGBA {
+	return color.RGBA{uint8(g.intn(256)), uint8(g.intn(256)), uint8(g.intn(256)), 255}
+}
+
+func (g *Generator) intn(n int) int {
+	if g.Rand == nil {
+		return rand.Intn(n)
+	}
+	return g.Rand.Intn(n)
+}
+
+func (g *Generator) float64() float64 {
+	if g.Rand == nil {
+		return rand.Float64()
+	}
+	return g.Rand.Float64()
+}
+
+func (g *Generator) normFloat64() float64 {
+	if g.Rand == nil {
+		return rand.NormFloat64()
+	}
+	return g.Rand.NormFloat64()
+}
+
+// return v rounded and clamped to [0,255]
+func clamp(v float64) uint8 {
+	return uint8(math.Max(0, math.Min(255, math.Round(v))))
+}
@@ -0,0 +1,62 @@
+package objsearchtest
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+func TestScene(t *testing.T) {
+	g := NewGenerator(1)
+	templates := []image.Image{g.Noise(8, 8), g.Noise(10, 6)}
+	opts := SceneOptions{Width: 100, Height: 80, Count: 3, Clutter: 20, Occlusion: 0.3, ScaleJitter: 0.2}
+	field, placements := g.Scene(templates, opts)
+	if field.Rect != image.Rect(0, 0, 100, 80) || len(placements) != 6 {
+		t.Fatal("scene error", len(placements))
+	}
+	for i, p := range placements {
+		if !p.Rect.In(field.Rect) || p.Occluded > 0.3 || p.Scale < 0.8 || p.Scale > 1.2 {
+			t.Fatal("placement error", p)
+		}
+		for _, q := range placements[i+1:] {
+			if p.Rect.Overlaps(q.Rect) {
+				t.Fatal("placements overlap")
+			}
+		}
+	}
+	// an unscaled, unoccluded, noiseless placement is an exact copy
+	_, placements = NewGenerator(2).Scene(templates[:1], SceneOptions{Width: 30, Height: 30, Count: 1})
+	field, _ = NewGenerator(2).Scene(templates[:1], SceneOptions{Width: 30, Height: 30, Count: 1})
+	if !reflect.DeepEqual(Scale(field.SubImage(placements[0].Rect), 1).Pix, templates[0].(*image.RGBA).Pix) {
+		t.Fatal("placed template differs")
+	}
+}
+
+func TestScale(t *testing.T) {
+	img := NewGenerator(1).Noise(10, 4)
+	if s := Scale(img, 1.5); s.Rect != image.Rect(0, 0, 15, 6) || s.RGBAAt(14, 5) != img.RGBAAt(9, 3) {
+		t.Fatal("scale error")
+	}
+	if s := Scale(img, 0.01); s.Rect != image.Rect(0, 0, 1, 1) {
+		t.Fatal("scale should be at least 1x1")
+	}
+}
+
+func TestAddNoise(t *testing.T) {
+	g := NewGenerator(1)
+	img := g.Clutter(50, 50, 0)
+	c := img.RGBAAt(0, 0)
+	g.AddNoise(img, 10)
+	sum, n := 0.0, 0
+	for i := 0; i < len(img.Pix); i += 4 {
+		d := float64(img.Pix[i]) - float64(c.R)
+		sum += d * d
+		n++
+		if img.Pix[i+3] != 255 {
+			t.Fatal("noise changed alpha")
+		}
+	}
+	if sd := sum / float64(n); sd < 50 || sd > 150 {
+		t.Fatal("noise variance error", sd)
+	}
+}
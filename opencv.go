@@ -0,0 +1,205 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+	"sort"
+	"sync"
+)
+
+// How Search scores hits.
+//
+// The OpenCV-compatible modes let thresholds tuned with OpenCV's
+// matchTemplate be used unchanged. They are used by the Search functions and
+// by Searcher. Channels are scored together, as OpenCV scores multi-channel
+// images, so Options.CombineMode and Options.ChannelWeights are ignored.
+// Masked object pixels are weighted by their mask values; OpenCV's scores are
+// matched for unmasked objects, and for SQDIFF_NORMED with binary masks.
+type ScoreMode int
+
+const (
+	// The mean absolute per-pixel difference between the object and the
+	// field, normalized so that the largest difference in the search
+	// rectangle scores 1. Hits score below Tolerance.
+	SCOREMODE_L1 ScoreMode = iota
+	// As OpenCV's matchTemplate with TM_SQDIFF_NORMED. Perfect matches score
+	// 0, and hits score below Tolerance.
+	SCOREMODE_SQDIFF_NORMED
+	// As OpenCV's matchTemplate with TM_CCOEFF_NORMED. Perfect matches score
+	// 1, hits score above Tolerance, and hits are sorted highest score
+	// first.
+	SCOREMODE_CCOEFF_NORMED
+	// The mean absolute per-pixel difference between the object and the
+	// field, in standard deviations from the mean difference in the search
+	// rectangle, so that hits adapt to busy and plain fields. Hits score
+	// below Tolerance, which should be negative: a Tolerance of -3 finds
+	// positions more than 3 standard deviations below the mean.
+	SCOREMODE_ZSCORE
+	// The mean absolute per-pixel difference between the object and the
+	// field, unnormalized, so that it is a fraction of the largest possible
+	// difference for pixel values in [0,1]. Unlike SCOREMODE_L1, scores do
+	// not depend on the other positions searched, so a field without the
+	// object has no hits. Hits score below Tolerance.
+	SCOREMODE_L1_ABSOLUTE
+)
+
+// return the OpenCV-compatible score of the object at each point of
+// ctx.SearchRect
+func (ctx objSearchContext) cvScores(field, object []*FloatImage) []float64 {
+	if len(field) != len(object) || len(field) == 0 {
+		panic("internal error")
+	}
+	r := object[0].Rect
+	// per-pixel weights
+	w := make([]float64, r.Dx()*r.Dy())
+	sumW := 0.0
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			i := (y-r.Min.Y)*r.Dx() + (x - r.Min.X)
+			w[i] = 1
+			if ctx.Mask != nil {
+				w[i] = float64(ctx.Mask.AlphaAt(x, y).A) / 255
+			}
+			if ctx.ScoreMode == SCOREMODE_SQDIFF_NORMED {
+				// OpenCV masks the differences before squaring them
+				w[i] *= w[i]
+			}
+			sumW += w[i]
+		}
+	}
+	if sumW == 0 {
+		panic("object is fully transparent")
+	}
+	// object pixels, less their per-channel weighted means for
+	// CCOEFF_NORMED, and their weighted sum of squares
+	template := func(w []float64, sumW float64) (templ [][]float64, templNorm float64) {
+		templ = make([][]float64, len(object))
+		for c := range object {
+			templ[c] = make([]float64, len(w))
+			mean := 0.0
+			for y := r.Min.Y; y < r.Max.Y; y++ {
+				for x := r.Min.X; x < r.Max.X; x++ {
+					i := (y-r.Min.Y)*r.Dx() + (x - r.Min.X)
+					templ[c][i] = object[c].FloatAt(x, y)
+					mean += w[i] * templ[c][i]
+				}
+			}
+			mean /= sumW
+			for i := range templ[c] {
+				if ctx.ScoreMode == SCOREMODE_CCOEFF_NORMED {
+					templ[c][i] -= mean
+				}
+				templNorm += w[i] * templ[c][i] * templ[c][i]
+			}
+		}
+		return templ, math.Sqrt(templNorm)
+	}
+	templ, templNorm := template(w, sumW)
+	scores := make([]float64, ctx.SearchRect.Dx()*ctx.SearchRect.Dy())
+	score1 := func(u, v int) float64 {
+		w, sumW, templ, templNorm := w, sumW, templ, templNorm
+		if !ctx.covered(r.Add(image.Point{u, v})) {
+			// compare only the pixels overlapping the field
+			w, sumW = append([]float64(nil), w...), 0
+			for y := r.Min.Y; y < r.Max.Y; y++ {
+				for x := r.Min.X; x < r.Max.X; x++ {
+					i := (y-r.Min.Y)*r.Dx() + (x - r.Min.X)
+					if !ctx.inField(u+x, v+y) {
+						w[i] = 0
+					}
+					sumW += w[i]
+				}
+			}
+			if sumW == 0 {
+				if ctx.ScoreMode == SCOREMODE_SQDIFF_NORMED {
+					return 1
+				}
+				return 0
+			}
+			templ, templNorm = template(w, sumW)
+		}
+		// weighted sums over the window of the field, and its correlation
+		// with templ
+		var sum2, ccorr, sqdiff, wndMean2 float64
+		for c := range field {
+			sum := 0.0
+			for y := r.Min.Y; y < r.Max.Y; y++ {
+				for x := r.Min.X; x < r.Max.X; x++ {
+					i := (y-r.Min.Y)*r.Dx() + (x - r.Min.X)
+					if w[i] == 0 {
+						continue
+					}
+					f := field[c].FloatAt(u+x, v+y)
+					sum += w[i] * f
+					sum2 += w[i] * f * f
+					ccorr += w[i] * f * templ[c][i]
+					d := f - templ[c][i]
+					sqdiff += w[i] * d * d
+				}
+			}
+			wndMean2 += sum * sum / sumW
+		}
+		num, t := sqdiff, math.Sqrt(sum2)*templNorm
+		if ctx.ScoreMode == SCOREMODE_CCOEFF_NORMED {
+			num, t = ccorr, math.Sqrt(math.Max(sum2-wndMean2, 0))*templNorm
+		}
+		// as OpenCV handles (nearly) flat windows and objects
+		switch {
+		case math.Abs(num) < t:
+			return num / t
+		case math.Abs(num) < t*1.125:
+			if num > 0 {
+				return 1
+			}
+			return -1
+		case ctx.ScoreMode == SCOREMODE_SQDIFF_NORMED:
+			return 1
+		default:
+			return 0
+		}
+	}
+	wg := sync.WaitGroup{}
+	for v := ctx.SearchRect.Min.Y; v < ctx.SearchRect.Max.Y; v++ {
+		wg.Add(1)
+		go func(v int) {
+			for u := ctx.SearchRect.Min.X; u < ctx.SearchRect.Max.X; u++ {
+				scores[ctx.offset(u, v)] = score1(u, v)
+			}
+			wg.Done()
+		}(v)
+	}
+	wg.Wait()
+	return scores
+}
+
+// return the hits in OpenCV-compatible scores, at least ctx.MinDist apart,
+// best first
+func (ctx objSearchContext) cvHits(scores []float64) []Hit {
+	var hits []Hit
+	for i, s := range scores {
+		if ctx.better(s, ctx.Tolerance) {
+			x, y := ctx.coords(i)
+			hits = append(hits, Hit{image.Point{x, y}, s})
+		}
+	}
+	return ctx.suppress(hits)
+}
+
+// return the best hits such that no two are less than ctx.MinDist apart,
+// best first
+func (ctx objSearchContext) suppress(hits []Hit) []Hit {
+	sort.SliceStable(hits, func(i, j int) bool {
+		return ctx.better(hits[i].S, hits[j].S)
+	})
+	var r []Hit
+nextHit:
+	for _, h := range hits {
+		for j := range r {
+			if r[j].Distance(h) < ctx.MinDist {
+				continue nextHit
+			}
+		}
+		r = append(r, h)
+	}
+	return r
+}
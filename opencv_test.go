@@ -0,0 +1,132 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// return the TM_SQDIFF_NORMED and TM_CCOEFF_NORMED scores of object in field
+// at p, computed directly from OpenCV's definitions
+func cvReference(field, object *FloatImage, p image.Point) (sqdiff, ccoeff float64) {
+	var d2, t2, i2, tMean, iMean float64
+	r := object.Rect
+	n := float64(r.Dx() * r.Dy())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			t, i := object.FloatAt(x, y), field.FloatAt(x+p.X, y+p.Y)
+			d2 += (t - i) * (t - i)
+			t2 += t * t
+			i2 += i * i
+			tMean += t / n
+			iMean += i / n
+		}
+	}
+	var num, tv, iv float64
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			t, i := object.FloatAt(x, y)-tMean, field.FloatAt(x+p.X, y+p.Y)-iMean
+			num += t * i
+			tv += t * t
+			iv += i * i
+		}
+	}
+	return d2 / math.Sqrt(t2*i2), num / math.Sqrt(tv*iv)
+}
+
+func TestOpenCVScores(t *testing.T) {
+	field := randomFloatImage(30, 25, 1)
+	object := randomFloatImage(6, 5, 1)
+	rect := validRect(field.Rect, object.Rect)
+	for _, mode := range []ScoreMode{SCOREMODE_SQDIFF_NORMED, SCOREMODE_CCOEFF_NORMED} {
+		ctx := newContext(rect, Options{ScoreMode: mode})
+		scores := ctx.cvScores([]*FloatImage{field}, []*FloatImage{object})
+		for _, p := range []image.Point{{0, 0}, {7, 3}, {24, 20}} {
+			sq, cc := cvReference(field, object, p)
+			want := sq
+			if mode == SCOREMODE_CCOEFF_NORMED {
+				want = cc
+			}
+			if got := scores[ctx.offset(p.X, p.Y)]; math.Abs(got-want) > 1e-9 {
+				t.Fatal("score error", mode, p, got, want)
+			}
+		}
+	}
+}
+
+func TestOpenCVScoreModes(t *testing.T) {
+	object := randomRGBImage(8, 8)
+	field := frameWithObject(randomRGBImage(50, 40), object, image.Point{17, 11})
+	hits := SearchImage(field, object, validRect(field.Rect, object.Rect), Options{
+		ScoreMode: SCOREMODE_SQDIFF_NORMED,
+		Tolerance: 0.01,
+		ColorMode: COLORMODE_RGB,
+	})
+	if len(hits) != 1 || hits[0].P != (image.Point{17, 11}) || hits[0].S != 0 {
+		t.Fatal("SQDIFF_NORMED hits error", hits)
+	}
+	s := NewSearcher(object, Options{ScoreMode: SCOREMODE_CCOEFF_NORMED, Tolerance: 0.5, MinDist: 5})
+	hits = s.Search(field)
+	if len(hits) == 0 || hits[0].P != (image.Point{17, 11}) || math.Abs(hits[0].S-1) > 1e-9 {
+		t.Fatal("CCOEFF_NORMED hits error", hits)
+	}
+	for i := 1; i < len(hits); i++ {
+		if hits[i].S > hits[i-1].S || hits[i].S <= 0.5 {
+			t.Fatal("CCOEFF_NORMED hits not sorted highest first", hits)
+		}
+	}
+	if p := s.SearchWithProgress(field, func(float64) {}); len(p) != len(hits) || p[0] != hits[0] {
+		t.Fatal("progress search differs", p)
+	}
+}
+
+func TestZScore(t *testing.T) {
+	object := randomRGBImage(8, 8)
+	p := image.Point{30, 20}
+	// a plain field with a little noise, and a busy one
+	plain := image.NewRGBA(image.Rect(0, 0, 60, 50))
+	for i := range plain.Pix {
+		plain.Pix[i] = 128 + uint8(i%3)
+	}
+	for _, bg := range []*image.RGBA{plain, randomRGBImage(60, 50)} {
+		field := frameWithObject(bg, object, p)
+		rect := validRect(field.Rect, object.Rect)
+		opts := Options{Tolerance: -6, MinDist: 8, ScoreMode: SCOREMODE_ZSCORE}
+		hits := SearchImage(field, object, rect, opts)
+		if len(hits) != 1 || hits[0].P != p {
+			t.Fatal("z-score hits error", hits)
+		}
+		// scores are standard deviations from the mean distance
+		_, dist := searchDistances(field, object, rect, opts)
+		mean, sd := meanStdDev(dist)
+		if math.Abs(hits[0].S-(0-mean)/sd) > 1e-9 {
+			t.Fatal("z-score error", hits[0].S)
+		}
+		r := SearchResult(field, object, rect, opts)
+		if hs := r.HitScores(); math.Abs(hs.Pix[0]-(r.Scores.Pix[0]-mean)/sd) > 1e-9 {
+			t.Fatal("hit scores error")
+		}
+	}
+}
+
+func TestL1Absolute(t *testing.T) {
+	object := randomRGBImage(8, 8)
+	field := frameWithObject(randomRGBImage(60, 50), object, image.Point{30, 20})
+	rect := validRect(field.Rect, object.Rect)
+	opts := Options{Tolerance: 0.1, MinDist: 8, ScoreMode: SCOREMODE_L1_ABSOLUTE}
+	hits := SearchImage(field, object, rect, opts)
+	if len(hits) != 1 || hits[0] != (Hit{image.Point{30, 20}, 0}) {
+		t.Fatal("absolute L1 hits error", hits)
+	}
+	// scores are the distances themselves
+	r := SearchResult(field, object, rect, opts)
+	if hs := r.HitScores(); !reflect.DeepEqual(hs.Pix, DistanceMap(field, object, rect, opts).Pix) {
+		t.Fatal("hit scores error")
+	}
+	// a field without the object has no hits
+	empty := randomRGBImage(60, 50)
+	if hits := SearchImage(empty, object, rect, opts); len(hits) != 0 {
+		t.Fatal("hits in a field without the object", hits)
+	}
+}
@@ -0,0 +1,209 @@
+package objsearch
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"image"
+	"image/color"
+)
+
+// version of the encoding produced by Searcher.MarshalBinary
+const searcherVersion = 1
+
+// the encoded form of a Searcher
+type searcherState struct {
+	Version        int
+	Object         objectState
+	Negatives      []objectState
+	NegativeMargin float64
+	Rect           image.Rectangle
+	Options        optionsState
+}
+
+// the encoded form of Options, less VerboseOut, with Mask converted to an
+// *image.Alpha
+type optionsState struct {
+	Tolerance               float64
+	MinDist                 int
+	ColorMode               ColorMode
+	CombineMode             CombineMode
+	Mask                    *image.Alpha
+	ColorKey                *color.NRGBA
+	ChannelWeights          []float64
+	DepthScale, DepthWeight float64
+	ScoreMode               ScoreMode
+	HitMode                 HitMode
+	MinProminence           float64
+	K                       int
+	AdaptiveTolerance       bool
+	Trim                    float64
+	EdgeWeighted            bool
+	Blur                    float64
+	JPEGTolerant            bool
+	Levels                  int
+	LightingRadius          int
+	Linearize               bool
+	Gamma                   float64
+	Denoise                 int
+	DenoiseObject           bool
+	Border                  BorderMode
+	StrictRect              bool
+	Float32                 bool
+}
+
+// return the encoded form of opts, with mask in place of opts.Mask
+func newOptionsState(opts Options, mask *image.Alpha) optionsState {
+	st := optionsState{
+		Tolerance:         opts.Tolerance,
+		MinDist:           opts.MinDist,
+		ColorMode:         opts.ColorMode,
+		CombineMode:       opts.CombineMode,
+		Mask:              mask,
+		ChannelWeights:    opts.ChannelWeights,
+		DepthScale:        opts.DepthScale,
+		DepthWeight:       opts.DepthWeight,
+		ScoreMode:         opts.ScoreMode,
+		HitMode:           opts.HitMode,
+		MinProminence:     opts.MinProminence,
+		K:                 opts.K,
+		AdaptiveTolerance: opts.AdaptiveTolerance,
+		Trim:              opts.Trim,
+		EdgeWeighted:      opts.EdgeWeighted,
+		Blur:              opts.Blur,
+		JPEGTolerant:      opts.JPEGTolerant,
+		Levels:            opts.Levels,
+		LightingRadius:    opts.LightingRadius,
+		Linearize:         opts.Linearize,
+		Gamma:             opts.Gamma,
+		Denoise:           opts.Denoise,
+		DenoiseObject:     opts.DenoiseObject,
+		Border:            opts.Border,
+		StrictRect:        opts.StrictRect,
+		Float32:           opts.Float32,
+	}
+	if opts.ColorKey != nil {
+		c := color.NRGBAModel.Convert(opts.ColorKey).(color.NRGBA)
+		st.ColorKey = &c
+	}
+	return st
+}
+
+// return the Options encoded by st
+func (st optionsState) options() Options {
+	opts := Options{
+		Tolerance:         st.Tolerance,
+		MinDist:           st.MinDist,
+		ColorMode:         st.ColorMode,
+		CombineMode:       st.CombineMode,
+		ChannelWeights:    st.ChannelWeights,
+		DepthScale:        st.DepthScale,
+		DepthWeight:       st.DepthWeight,
+		ScoreMode:         st.ScoreMode,
+		HitMode:           st.HitMode,
+		MinProminence:     st.MinProminence,
+		K:                 st.K,
+		AdaptiveTolerance: st.AdaptiveTolerance,
+		Trim:              st.Trim,
+		EdgeWeighted:      st.EdgeWeighted,
+		Blur:              st.Blur,
+		JPEGTolerant:      st.JPEGTolerant,
+		Levels:            st.Levels,
+		LightingRadius:    st.LightingRadius,
+		Linearize:         st.Linearize,
+		Gamma:             st.Gamma,
+		Denoise:           st.Denoise,
+		DenoiseObject:     st.DenoiseObject,
+		Border:            st.Border,
+		StrictRect:        st.StrictRect,
+		Float32:           st.Float32,
+	}
+	// avoid storing typed nils in the interface fields
+	if st.Mask != nil {
+		opts.Mask = st.Mask
+	}
+	if st.ColorKey != nil {
+		opts.ColorKey = *st.ColorKey
+	}
+	return opts
+}
+
+// the encoded form of an Object
+type objectState struct {
+	Opaque *image.RGBA
+	Alpha  *image.Alpha
+	Planes map[ColorMode][]*FloatImage
+}
+
+// Encodes s, including the intermediate planes of its objects, so that a
+// large library of Searchers can be loaded with UnmarshalBinary instead of
+// recomputed. The planes for s.Options.ColorMode are computed first if they
+// have not been already. Options.VerboseOut is not encoded.
+func (s *Searcher) MarshalBinary() ([]byte, error) {
+	st := searcherState{
+		Version:        searcherVersion,
+		Object:         newObjectState(s.Object, s.Options.ColorMode),
+		NegativeMargin: s.NegativeMargin,
+		Rect:           s.Rect,
+		Options:        newOptionsState(s.Options, toMask(s.Options.Mask, s.Object.Bounds())),
+	}
+	for _, n := range s.Negatives {
+		st.Negatives = append(st.Negatives, newObjectState(n, s.Options.ColorMode))
+	}
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(st); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Decodes a Searcher encoded by MarshalBinary into s
+func (s *Searcher) UnmarshalBinary(data []byte) error {
+	var st searcherState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&st); err != nil {
+		return err
+	}
+	if st.Version != searcherVersion {
+		return errors.New("unsupported searcher encoding version")
+	}
+	*s = Searcher{
+		Object:         st.Object.object(),
+		NegativeMargin: st.NegativeMargin,
+		Rect:           st.Rect,
+		Options:        st.Options.options(),
+	}
+	for _, n := range st.Negatives {
+		s.Negatives = append(s.Negatives, n.object())
+	}
+	return nil
+}
+
+// return the encoded form of o, with the planes for mode computed
+func newObjectState(o *Object, mode ColorMode) objectState {
+	o.planes(mode)
+	o.cache.mu.Lock()
+	defer o.cache.mu.Unlock()
+	st := objectState{Opaque: o.opaque, Alpha: o.alpha, Planes: make(map[ColorMode][]*FloatImage)}
+	for m, p := range o.cache.planes {
+		st.Planes[m] = p
+	}
+	return st
+}
+
+// return the Object encoded by st
+func (st objectState) object() *Object {
+	o := &Object{Image: st.Opaque, opaque: st.Opaque, alpha: st.Alpha}
+	if st.Alpha != nil {
+		// restore the object's transparency
+		img := image.NewNRGBA(st.Opaque.Rect)
+		for i := range img.Pix {
+			img.Pix[i] = st.Opaque.Pix[i]
+			if i%4 == 3 {
+				img.Pix[i] = st.Alpha.Pix[i/4]
+			}
+		}
+		o.Image = img
+	}
+	o.cache.planes = st.Planes
+	return o
+}
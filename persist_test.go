@@ -0,0 +1,47 @@
+package objsearch
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestSearcherMarshalBinary(t *testing.T) {
+	object := randomRGBImage(10, 10)
+	// a transparent corner
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			object.SetRGBA(x, y, color.RGBA{})
+		}
+	}
+	field := frameWithObject(randomRGBImage(60, 60), object, image.Point{20, 30})
+	s := NewSearcher(object, Options{
+		Tolerance:   0.2,
+		MinDist:     5,
+		ColorMode:   COLORMODE_RGB,
+		CombineMode: COMBINEMODE_MEAN,
+		ColorKey:    color.RGBA{255, 0, 255, 255},
+	})
+	s.AddNegative(randomRGBImage(10, 10))
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d Searcher
+	if err := d.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Object.cache.planes[COLORMODE_RGB]) != 3 || len(d.Negatives) != 1 {
+		t.Fatal("decoded searcher is missing state")
+	}
+	if d.Object.At(0, 0).(color.NRGBA).A != 0 {
+		t.Fatal("decoded object lost its transparency")
+	}
+	if a, b := s.Search(field), d.Search(field); len(a) == 0 || !reflect.DeepEqual(a, b) {
+		t.Fatal("decoded searcher finds different hits", a, b)
+	}
+	if err := d.UnmarshalBinary(data[:len(data)/2]); err == nil {
+		t.Fatal("expected error decoding truncated data")
+	}
+}
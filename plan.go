@@ -0,0 +1,147 @@
+package objsearch
+
+import (
+	"fmt"
+	"image"
+	"math/rand"
+	"runtime"
+	"time"
+)
+
+// How the scores of a search are computed
+type Backend int
+
+const (
+	// Weighted sums of absolute differences over each window, for
+	// SCOREMODE_L1, SCOREMODE_ZSCORE and SCOREMODE_L1_ABSOLUTE
+	BACKEND_L1 Backend = iota
+	// Absolute differences collected and weighted per window, for the L1
+	// score modes with Trim or JPEGTolerant, many times slower. Windows
+	// past the field's edges with BORDERMODE_OVERLAP are scored so too.
+	BACKEND_L1_WINDOW
+	// Weighted sums of products over each window, for
+	// SCOREMODE_SQDIFF_NORMED and SCOREMODE_CCOEFF_NORMED
+	BACKEND_CORRELATION
+)
+
+func (b Backend) String() string {
+	switch b {
+	case BACKEND_L1:
+		return "l1"
+	case BACKEND_L1_WINDOW:
+		return "l1-window"
+	case BACKEND_CORRELATION:
+		return "correlation"
+	}
+	return fmt.Sprintf("Backend(%d)", int(b))
+}
+
+// nominal time per object pixel compared on one core, by backend
+var backendCost = map[Backend]time.Duration{
+	BACKEND_L1:          6 * time.Nanosecond,
+	BACKEND_L1_WINDOW:   150 * time.Nanosecond,
+	BACKEND_CORRELATION: 8 * time.Nanosecond,
+}
+
+// The expected cost of a search, as returned by PlanSearch
+type Plan struct {
+	// Rectangle of top-left corners searched, as returned by CheckRect
+	Rect image.Rectangle
+	// Number of windows of the field scored, one per position and channel
+	// for the L1 backends, and one per position for BACKEND_CORRELATION
+	Evaluations int64
+	// Number of object pixel comparisons, over all windows
+	PixelOps int64
+	Backend  Backend
+	// Approximate peak allocation, as returned by EstimateMemory
+	Memory int64
+	// Rough estimate of the time spent scoring, not counting conversion
+	// and preprocessing of the images
+	Time time.Duration
+	// True if Time was scaled from a micro-benchmark of the backend on this
+	// machine, rather than from nominal costs
+	Benchmarked bool
+}
+
+// Returns the expected cost of SearchImage for a field and object of the
+// given sizes, searching rect with opts, without searching, so that batch
+// scans can be scheduled. An empty rect is taken as every position at
+// which the object can be scored, as for SearchResult. If benchmark is set,
+// the time estimate is scaled from a quick benchmark of the backend with
+// opts on this machine, which takes a few milliseconds; otherwise it is
+// scaled from nominal costs, divided among GOMAXPROCS cores.
+func PlanSearch(fieldSize, objectSize image.Point, rect image.Rectangle, opts Options, benchmark bool) Plan {
+	field, object := image.Rectangle{Max: fieldSize}, image.Rectangle{Max: objectSize}
+	if rect.Empty() {
+		rect = searchableRect(field, object, opts.Border)
+	}
+	p := Plan{Memory: EstimateMemory(fieldSize, objectSize, rect, opts)}
+	rect, err := CheckRect(field, object, rect, opts)
+	if err != nil {
+		return p
+	}
+	p.Rect = rect
+	ctx := newContext(rect, opts)
+	p.Backend = ctx.backend()
+	channels := int64(1)
+	if opts.ColorMode == COLORMODE_RGB {
+		channels = 3
+	}
+	positions := int64(rect.Dx()) * int64(rect.Dy())
+	p.Evaluations = positions * channels
+	if p.Backend == BACKEND_CORRELATION {
+		p.Evaluations = positions
+	}
+	p.PixelOps = positions * channels * int64(object.Dx()) * int64(object.Dy())
+	if p.PixelOps == 0 {
+		return p
+	}
+	if benchmark {
+		p.Time = time.Duration(float64(p.PixelOps) * benchmarkCost(ctx, int(channels), objectSize))
+		p.Benchmarked = true
+	} else {
+		p.Time = time.Duration(p.PixelOps) * backendCost[p.Backend] / time.Duration(runtime.GOMAXPROCS(0))
+	}
+	return p
+}
+
+// return the backend that computes ctx's scores
+func (ctx objSearchContext) backend() Backend {
+	switch {
+	case !ctx.distanceScores():
+		return BACKEND_CORRELATION
+	case ctx.Trim > 0 || ctx.JPEG:
+		return BACKEND_L1_WINDOW
+	}
+	return BACKEND_L1
+}
+
+// return the wall time in nanoseconds per object pixel comparison of
+// scoring random planes with ctx's settings, with an object of at most
+// 32x32 pixels and enough rows to occupy every core
+func benchmarkCost(ctx objSearchContext, channels int, objectSize image.Point) float64 {
+	o := image.Rect(0, 0, objectSize.X, objectSize.Y).Intersect(image.Rect(0, 0, 32, 32))
+	rect := image.Rect(0, 0, 32, 32)
+	if n := runtime.GOMAXPROCS(0); n > 32 {
+		rect.Max.Y = n
+	}
+	f := image.Rectangle{Max: rect.Max.Add(o.Max)}
+	rnd := rand.New(rand.NewSource(1))
+	random := func(r image.Rectangle) *FloatImage {
+		p := NewFloatImage(r)
+		for i := range p.Pix {
+			p.Pix[i] = rnd.Float64()
+		}
+		return p
+	}
+	var field, object []*FloatImage
+	for c := 0; c < channels; c++ {
+		field, object = append(field, random(f)), append(object, random(o))
+	}
+	ctx.SearchRect, ctx.FieldRect = rect, f
+	ctx.Mask, ctx.Contrast, ctx.ChannelWeights, ctx.VerboseOut = nil, nil, nil, nil
+	start := time.Now()
+	ctx.scores(field, object)
+	elapsed := time.Since(start)
+	return float64(elapsed) / float64(rect.Dx()*rect.Dy()*channels*o.Dx()*o.Dy())
+}
@@ -0,0 +1,32 @@
+package objsearch
+
+import (
+	"image"
+	"testing"
+)
+
+func TestPlanSearch(t *testing.T) {
+	field, object := image.Point{200, 150}, image.Point{10, 10}
+	p := PlanSearch(field, object, image.Rectangle{}, Options{ColorMode: COLORMODE_RGB}, false)
+	if p.Rect != image.Rect(0, 0, 191, 141) || p.Evaluations != 3*191*141 || p.PixelOps != 100*3*191*141 || p.Backend != BACKEND_L1 || p.Benchmarked {
+		t.Fatal("plan error", p)
+	}
+	if p.Memory != EstimateMemory(field, object, image.Rectangle{}, Options{ColorMode: COLORMODE_RGB}) || p.Time <= 0 {
+		t.Fatal("plan estimate error", p)
+	}
+	for _, test := range []struct {
+		opts    Options
+		backend Backend
+	}{
+		{Options{Trim: 0.1}, BACKEND_L1_WINDOW},
+		{Options{ScoreMode: SCOREMODE_CCOEFF_NORMED}, BACKEND_CORRELATION},
+	} {
+		if p := PlanSearch(field, object, image.Rectangle{}, test.opts, true); p.Backend != test.backend || !p.Benchmarked || p.Time <= 0 {
+			t.Fatal("plan error", test.backend, p)
+		}
+	}
+	// nothing to search
+	if p := PlanSearch(object, field, image.Rectangle{}, Options{}, true); p.PixelOps != 0 || p.Time != 0 || p.Memory != 0 {
+		t.Fatal("plan error", p)
+	}
+}
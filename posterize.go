@@ -0,0 +1,24 @@
+package objsearch
+
+import "math"
+
+// return planes with each pixel rounded to the nearest of n evenly spaced
+// levels in [0,1]
+func posterizePlanes(planes []*FloatImage, n int) []*FloatImage {
+	if n < 2 {
+		panic("fewer than 2 levels")
+	}
+	steps := float64(n - 1)
+	posterized := make([]*FloatImage, len(planes))
+	for i, p := range planes {
+		q := NewFloatImage(p.Rect)
+		for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+			for x := p.Rect.Min.X; x < p.Rect.Max.X; x++ {
+				v := math.Max(0, math.Min(1, p.Pix[p.PixOffset(x, y)]))
+				q.Pix[q.PixOffset(x, y)] = math.Round(v*steps) / steps
+			}
+		}
+		posterized[i] = q
+	}
+	return posterized
+}
@@ -0,0 +1,33 @@
+package objsearch
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+)
+
+// test that a palette-shifted object matches exactly with Options.Levels
+func TestLevels(t *testing.T) {
+	object := NewFloatImage(image.Rect(0, 0, 10, 10))
+	for i := range object.Pix {
+		object.Pix[i] = float64(rand.Intn(4)) / 3
+	}
+	field := randomFloatImage(50, 50, 1)
+	p := image.Point{17, 25}
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			// shift the palette toward the middle
+			v := object.FloatAt(x, y)
+			field.Pix[field.PixOffset(p.X+x, p.Y+y)] = v + 0.08*(0.5-v)
+		}
+	}
+	rect := validRect(field.Rect, object.Rect)
+	opts := Options{Tolerance: 0.5, HitMode: HITMODE_BESTK, K: 1}
+	if h := SearchFloat(field, object, rect, opts); len(h) != 1 || h[0].P != p || h[0].S == 0 {
+		t.Fatal("unposterized search error", h)
+	}
+	opts.Levels = 4
+	if h := SearchFloat(field, object, rect, opts); len(h) != 1 || h[0] != (Hit{p, 0}) {
+		t.Fatal("posterized search error", h)
+	}
+}
@@ -0,0 +1,99 @@
+package objsearch
+
+import (
+	"image"
+	"sync"
+
+	"github.com/hypoactiv/imutil"
+)
+
+// A field image that caches its intermediate planes, so that searching it
+// repeatedly (e.g. for many different objects) converts it only once.
+//
+// A Field is an image.Image, and may be passed to SearchImage.
+type Field struct {
+	*image.RGBA
+	cache planeCache
+}
+
+// Returns img as a Field. If img is an *image.RGBA it is used without
+// copying, and must not be modified while the Field is in use.
+func NewField(img image.Image) *Field {
+	if f, ok := img.(*Field); ok {
+		return f
+	}
+	return &Field{RGBA: toRGBA(img)}
+}
+
+// Returns the intermediate planes of f according to mode
+func (f *Field) planes(mode ColorMode) []*FloatImage {
+	return f.cache.get(f.RGBA, mode)
+}
+
+// An object image that caches its transparency mask and intermediate planes,
+// so that searching for it repeatedly converts it only once.
+//
+// An Object is an image.Image, and may be passed to SearchImage.
+type Object struct {
+	image.Image
+	// opaque colors and alpha mask of Image, as returned by opaqueRGBA
+	opaque *image.RGBA
+	alpha  *image.Alpha
+	cache  planeCache
+}
+
+// Returns img as an Object. img must not be modified while the Object is in
+// use.
+func NewObject(img image.Image) *Object {
+	if o, ok := img.(*Object); ok {
+		return o
+	}
+	o := &Object{Image: img}
+	o.opaque, o.alpha = opaqueRGBA(img)
+	return o
+}
+
+// Returns the intermediate planes of o according to mode
+func (o *Object) planes(mode ColorMode) []*FloatImage {
+	return o.cache.get(o.opaque, mode)
+}
+
+// a lazily computed, concurrency-safe cache of intermediate planes by
+// ColorMode
+type planeCache struct {
+	mu     sync.Mutex
+	planes map[ColorMode][]*FloatImage
+}
+
+func (c *planeCache) get(img *image.RGBA, mode ColorMode) []*FloatImage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.planes[mode]
+	if !ok {
+		p = colorPlanes(img, mode)
+		if c.planes == nil {
+			c.planes = make(map[ColorMode][]*FloatImage)
+		}
+		c.planes[mode] = p
+	}
+	// callers may append to the slice returned, but not modify the cache
+	return p[:len(p):len(p)]
+}
+
+// return the intermediate planes of img according to mode
+func colorPlanes(img *image.RGBA, mode ColorMode) (planes []*FloatImage) {
+	inter := []*image.Gray{}
+	switch mode {
+	case COLORMODE_GRAY:
+		// generate grayscale intermediate image
+		inter = append(inter, imutil.ToGrayscale(img))
+	case COLORMODE_RGB:
+		inter = imutil.SeparateRGB(img)
+	default:
+		panic("invalid color mode")
+	}
+	for i := range inter {
+		planes = append(planes, FloatImageFromGray(inter[i]))
+	}
+	return
+}
@@ -0,0 +1,75 @@
+package objsearch
+
+import "image"
+
+// Searches field for object in each of regions of top-left corners in turn,
+// such as where the object was last seen and then the rest of the field, so
+// that likely areas are searched first. found, if not nil, is called with
+// each hit as soon as its region has been searched, best first within each
+// region, and the search stops early if it returns false. Positions in more
+// than one region are searched only in the first, and hits less than
+// opts.MinDist from a hit in an earlier region are dropped. Returns the hits
+// found, in the order found was called with them.
+//
+// Panics if opts.ScoreMode is relative, such as SCOREMODE_L1, as hits in
+// different regions could then not be compared.
+func SearchPriority(field, object image.Image, regions []image.Rectangle, opts Options, found func(Hit) bool) []Hit {
+	if newContext(image.Rectangle{}, opts).relativeScores() {
+		panic("priority search requires an absolute score mode")
+	}
+	f, o := NewField(field), NewObject(object)
+	valid := searchableRect(f.Bounds(), o.Bounds(), opts.Border)
+	var hits []Hit
+	var searched []image.Rectangle
+	for _, region := range regions {
+		pieces := []image.Rectangle{region.Intersect(valid)}
+		for _, s := range searched {
+			pieces = subtractRects(pieces, s)
+		}
+		searched = append(searched, region)
+		for _, piece := range pieces {
+			if piece.Empty() {
+				continue
+			}
+			ctx, scores := searchScores(f, o, piece, opts)
+		nextHit:
+			for _, h := range ctx.hits(scores) {
+				for _, prev := range hits {
+					if prev.Distance(h) < opts.MinDist {
+						continue nextHit
+					}
+				}
+				hits = append(hits, h)
+				if found != nil && !found(h) {
+					return hits
+				}
+			}
+		}
+	}
+	return hits
+}
+
+// return the parts of rs not in s
+func subtractRects(rs []image.Rectangle, s image.Rectangle) []image.Rectangle {
+	var d []image.Rectangle
+	for _, r := range rs {
+		i := r.Intersect(s)
+		if i.Empty() {
+			d = append(d, r)
+			continue
+		}
+		// the bands above and below i, and the parts of its rows to its
+		// left and right
+		for _, p := range []image.Rectangle{
+			image.Rect(r.Min.X, r.Min.Y, r.Max.X, i.Min.Y),
+			image.Rect(r.Min.X, i.Max.Y, r.Max.X, r.Max.Y),
+			image.Rect(r.Min.X, i.Min.Y, i.Min.X, i.Max.Y),
+			image.Rect(i.Max.X, i.Min.Y, r.Max.X, i.Max.Y),
+		} {
+			if !p.Empty() {
+				d = append(d, p)
+			}
+		}
+	}
+	return d
+}
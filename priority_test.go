@@ -0,0 +1,49 @@
+package objsearch
+
+import (
+	"image"
+	"testing"
+)
+
+func TestSearchPriority(t *testing.T) {
+	object := randomRGBImage(10, 10)
+	field := frameWithObject(frameWithObject(randomRGBImage(100, 80), object, image.Point{10, 10}), object, image.Point{70, 50})
+	opts := Options{Tolerance: 0.05, MinDist: 5, ScoreMode: SCOREMODE_SQDIFF_NORMED}
+	// last seen near 70,50, then anywhere
+	regions := []image.Rectangle{image.Rect(60, 40, 80, 60), field.Rect}
+	hits := SearchPriority(field, object, regions, opts, nil)
+	if len(hits) != 2 || hits[0].P != (image.Point{70, 50}) || hits[1].P != (image.Point{10, 10}) {
+		t.Fatal("priority search error", hits)
+	}
+	// stop at the first hit
+	n := 0
+	hits = SearchPriority(field, object, regions, opts, func(Hit) bool {
+		n++
+		return false
+	})
+	if n != 1 || len(hits) != 1 || hits[0].P != (image.Point{70, 50}) {
+		t.Fatal("early stop error", n, hits)
+	}
+}
+
+func TestSubtractRects(t *testing.T) {
+	r := image.Rect(0, 0, 10, 10)
+	for _, s := range []image.Rectangle{image.Rect(2, 3, 5, 7), image.Rect(-5, -5, 5, 5), image.Rect(20, 20, 30, 30), r} {
+		d := subtractRects([]image.Rectangle{r}, s)
+		area := 0
+		for i, p := range d {
+			if p.Overlaps(s) || !p.In(r) {
+				t.Fatal("piece overlaps s or lies outside r", p, s)
+			}
+			for _, q := range d[:i] {
+				if p.Overlaps(q) {
+					t.Fatal("pieces overlap", p, q)
+				}
+			}
+			area += p.Dx() * p.Dy()
+		}
+		if i := r.Intersect(s); area != 100-i.Dx()*i.Dy() {
+			t.Fatal("wrong area", s, area)
+		}
+	}
+}
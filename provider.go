@@ -0,0 +1,148 @@
+package objsearch
+
+import (
+	"errors"
+	"image"
+	"image/draw"
+	"math"
+)
+
+// A source of the pixels of a field too large to hold in memory at once,
+// such as a gigapixel scan stored as tiles on disk, a deep-zoom pyramid, or
+// a network image service. Regions are requested on demand.
+type FieldProvider interface {
+	// Bounds of the whole field
+	Bounds() image.Rectangle
+	// Returns the pixels of the field within r, which lies within Bounds.
+	// The image returned must have bounds r.
+	Region(r image.Rectangle) (image.Image, error)
+}
+
+// A FieldProvider serving the regions of an image in memory
+type ImageProvider struct {
+	image.Image
+}
+
+func (p ImageProvider) Region(r image.Rectangle) (image.Image, error) {
+	if s, ok := p.Image.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return s.SubImage(r), nil
+	}
+	c := image.NewRGBA(r)
+	draw.Draw(c, r, p.Image, r.Min, draw.Src)
+	return c, nil
+}
+
+// default number of positions searched along each side of a tile by
+// SearchProvider
+const providerTileSize = 1024
+
+// Like SearchImage, for the field served by p, which is fetched and searched
+// in tiles of at most tileSize by tileSize positions, so that only one tile's
+// region of the field is in memory at a time. If tileSize is zero, 1024 is
+// used. An empty rect searches every position at which object lies within
+// the field.
+//
+// Hits are as SearchImage would find them, with these exceptions: for
+// SCOREMODE_L1 and SCOREMODE_ZSCORE, each tile is fetched and scored twice,
+// first to find the range of distances over all tiles that hits are
+// normalized by; AdaptiveTolerance compares local contrast with its mean
+// over each tile; and HITMODE_LOCALMINIMA measures prominence within each
+// tile.
+func SearchProvider(p FieldProvider, object image.Image, rect image.Rectangle, opts Options, tileSize int) ([]Hit, error) {
+	if tileSize <= 0 {
+		tileSize = providerTileSize
+	}
+	o := NewObject(object)
+	if rect.Empty() {
+		rect = validRect(p.Bounds(), o.Bounds())
+	}
+	if rect.Empty() {
+		return nil, nil
+	}
+	var tiles []image.Rectangle
+	for y := rect.Min.Y; y < rect.Max.Y; y += tileSize {
+		for x := rect.Min.X; x < rect.Max.X; x += tileSize {
+			tiles = append(tiles, image.Rect(x, y, x+tileSize, y+tileSize).Intersect(rect))
+		}
+	}
+	// search each tile in the part of the field it needs
+	scores := func(tile image.Rectangle) (objSearchContext, []float64, error) {
+		need := image.Rectangle{tile.Min.Add(o.Bounds().Min), tile.Max.Add(o.Bounds().Max).Sub(image.Point{1, 1})}
+		if !need.In(p.Bounds()) {
+			return objSearchContext{}, nil, errors.New("object does not lie within the field at every position searched")
+		}
+		field, err := p.Region(need)
+		if err != nil {
+			return objSearchContext{}, nil, err
+		}
+		if field.Bounds() != need {
+			return objSearchContext{}, nil, errors.New("provider returned a region with the wrong bounds")
+		}
+		ctx, fieldPlanes, objectPlanes := newImageContext(field, o, tile, opts)
+		ctx.BlockOrigin = p.Bounds().Min
+		return ctx, ctx.scores(fieldPlanes, objectPlanes), nil
+	}
+	var scoreRange *[2]float64
+	if newContext(rect, opts).relativeScores() {
+		// the range of distances over all tiles
+		var stats distanceStats
+		for _, tile := range tiles {
+			_, d, err := scores(tile)
+			if err != nil {
+				return nil, err
+			}
+			stats.add(d)
+		}
+		if scoreRange = stats.scoreRange(opts.ScoreMode); scoreRange == nil {
+			return nil, nil
+		}
+	}
+	var hits []Hit
+	for _, tile := range tiles {
+		ctx, s, err := scores(tile)
+		if err != nil {
+			return nil, err
+		}
+		ctx.ScoreRange = scoreRange
+		hits = append(hits, ctx.hits(s)...)
+	}
+	// hits at least MinDist apart across tiles too
+	ctx := newContext(rect, opts)
+	hits = ctx.suppress(hits)
+	if opts.HitMode == HITMODE_BESTK && len(hits) > opts.K {
+		hits = hits[:opts.K]
+	}
+	return hits, nil
+}
+
+// running statistics of distances searched in parts
+type distanceStats struct {
+	n, sum, sum2, max float64
+}
+
+func (s *distanceStats) add(d []float64) {
+	for _, v := range d {
+		s.n++
+		s.sum += v
+		s.sum2 += v * v
+		s.max = math.Max(s.max, v)
+	}
+}
+
+// return the distances normalized to hit scores of 0 and 1 according to
+// mode, as objSearchContext.scoreRange would for all the distances added,
+// or nil if they are all equal
+func (s distanceStats) scoreRange(mode ScoreMode) *[2]float64 {
+	r := &[2]float64{0, s.max}
+	if mode == SCOREMODE_ZSCORE {
+		mean := s.sum / s.n
+		sd := math.Sqrt(math.Max(0, s.sum2/s.n-mean*mean))
+		r = &[2]float64{mean, mean + sd}
+	}
+	if r[1] <= r[0] {
+		return nil
+	}
+	return r
+}
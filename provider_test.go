@@ -0,0 +1,61 @@
+package objsearch
+
+import (
+	"errors"
+	"image"
+	"math"
+	"testing"
+)
+
+// a FieldProvider recording the largest region served, and failing if fail
+// is set
+type testProvider struct {
+	ImageProvider
+	largest int
+	fail    bool
+}
+
+func (p *testProvider) Region(r image.Rectangle) (image.Image, error) {
+	if p.fail {
+		return nil, errors.New("unavailable")
+	}
+	if n := r.Dx() * r.Dy(); n > p.largest {
+		p.largest = n
+	}
+	return p.ImageProvider.Region(r)
+}
+
+func TestSearchProvider(t *testing.T) {
+	object := randomRGBImage(10, 10)
+	field := randomRGBImage(150, 120)
+	for _, p := range []image.Point{{3, 4}, {70, 50}, {131, 100}} {
+		field = frameWithObject(field, object, p)
+	}
+	for _, opts := range []Options{
+		{Tolerance: 0.2, MinDist: 5},
+		{Tolerance: -4, MinDist: 5, ScoreMode: SCOREMODE_ZSCORE},
+		{Tolerance: 0.9, MinDist: 5, ScoreMode: SCOREMODE_CCOEFF_NORMED},
+		{MinDist: 5, HitMode: HITMODE_BESTK, K: 3},
+	} {
+		want := SearchImage(field, object, validRect(field.Rect, object.Rect), opts)
+		p := &testProvider{ImageProvider: ImageProvider{field}}
+		hits, err := SearchProvider(p, object, image.Rectangle{}, opts, 32)
+		// the hits tie
+		SortRaster(hits)
+		SortRaster(want)
+		if err != nil || len(hits) != len(want) {
+			t.Fatal("provider search error", opts.ScoreMode, hits, want, err)
+		}
+		for i := range hits {
+			if hits[i].P != want[i].P || math.Abs(hits[i].S-want[i].S) > 1e-9 {
+				t.Fatal("provider search error", opts.ScoreMode, hits, want)
+			}
+		}
+		if p.largest > 41*41 {
+			t.Fatal("region larger than a tile", p.largest)
+		}
+	}
+	if _, err := SearchProvider(&testProvider{fail: true, ImageProvider: ImageProvider{field}}, object, image.Rectangle{}, Options{}, 32); err == nil {
+		t.Fatal("provider error not returned")
+	}
+}
@@ -0,0 +1,160 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+)
+
+// Shape of a point spread function
+type PSFKind int
+
+const (
+	// A 2D Gaussian of standard deviation Sigma
+	PSF_GAUSSIAN PSFKind = iota
+	// An Airy disk, the diffraction pattern of a circular aperture, scaled
+	// so that its central peak best fits a Gaussian of standard deviation
+	// Sigma. Its first dark ring is at about 2.9 Sigma.
+	PSF_AIRY
+)
+
+// A point spread function, the image of a point source such as a star or a
+// fluorescent bead
+type PSF struct {
+	Kind  PSFKind
+	Sigma float64
+	// The template spans Radius pixels either side of its center pixel
+	Radius int
+}
+
+// Returns p as a template of (2*Radius+1)x(2*Radius+1) pixels with bounds at
+// the origin, peaking at 1 at its center pixel
+func (p PSF) Template() *FloatImage {
+	if p.Sigma <= 0 || p.Radius < 1 {
+		panic("invalid PSF")
+	}
+	n := 2*p.Radius + 1
+	t := NewFloatImage(image.Rect(0, 0, n, n))
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			r := math.Hypot(float64(x-p.Radius), float64(y-p.Radius))
+			var v float64
+			switch p.Kind {
+			case PSF_GAUSSIAN:
+				v = math.Exp(-r * r / (2 * p.Sigma * p.Sigma))
+			case PSF_AIRY:
+				v = 1
+				if k := 1.3191 * r / p.Sigma; k != 0 {
+					v = 2 * math.J1(k) / k
+					v *= v
+				}
+			default:
+				panic("invalid PSF kind")
+			}
+			t.Pix[t.PixOffset(x, y)] = v
+		}
+	}
+	return t
+}
+
+// A point source found by DetectPoints
+type PointSource struct {
+	// Top-left corner of the PSF template at the source, and the score of
+	// the match
+	Hit
+	// Intensity-weighted centroid of the source, in field coordinates of
+	// pixel centers, to subpixel precision
+	X, Y float64
+	// Local background level at the source, from the plane best fitting the
+	// pixels on the edge of the template's window
+	Background float64
+	// Integrated intensity of the source above the background plane, over
+	// the template's window
+	Flux float64
+}
+
+// Detects point sources in field matching psf, such as stars or fluorescent
+// beads, which may differ in brightness and sit on varying backgrounds.
+// field may have any value range, e.g. as returned by FloatImageFromGray16.
+//
+// Sources are matched with SCOREMODE_CCOEFF_NORMED, whatever opts.ScoreMode,
+// so that they are scored by shape alone: opts.Tolerance is the least
+// correlation with psf, e.g. 0.8. If opts.MinDist is zero, psf.Radius is
+// used. Sources are returned best first.
+func DetectPoints(field *FloatImage, psf PSF, opts Options) []PointSource {
+	t := psf.Template()
+	opts.ScoreMode = SCOREMODE_CCOEFF_NORMED
+	if opts.MinDist == 0 {
+		opts.MinDist = psf.Radius
+	}
+	hits := SearchFloat(field, t, validRect(field.Rect, t.Rect), opts)
+	sources := make([]PointSource, len(hits))
+	for i, h := range hits {
+		sources[i] = measurePoint(field, t.Rect.Add(h.P))
+		sources[i].Hit = h
+	}
+	return sources
+}
+
+// return the background, flux and centroid of the point source in window w
+// of field
+func measurePoint(field *FloatImage, w image.Rectangle) (s PointSource) {
+	bg := edgePlane(field, w)
+	cx, cy := float64(w.Min.X+w.Max.X)/2, float64(w.Min.Y+w.Max.Y)/2
+	s.Background = bg(cx, cy)
+	var mx, my, m float64
+	for y := w.Min.Y; y < w.Max.Y; y++ {
+		for x := w.Min.X; x < w.Max.X; x++ {
+			px, py := float64(x)+0.5, float64(y)+0.5
+			v := field.FloatAt(x, y) - bg(px, py)
+			s.Flux += v
+			if v > 0 {
+				mx += v * px
+				my += v * py
+				m += v
+			}
+		}
+	}
+	s.X, s.Y = cx, cy
+	if m > 0 {
+		s.X, s.Y = mx/m, my/m
+	}
+	return
+}
+
+// return the plane best fitting, by least squares, the pixels on the edge
+// of window w of field, as a function of pixel center coordinates, so that
+// sloping backgrounds do not bias measurements
+func edgePlane(field *FloatImage, w image.Rectangle) func(x, y float64) float64 {
+	// normal equations of v = a + b*x + c*y, relative to the window's
+	// center
+	cx, cy := float64(w.Min.X+w.Max.X)/2, float64(w.Min.Y+w.Max.Y)/2
+	var n, sx, sy, sxx, sxy, syy, sv, sxv, syv float64
+	for y := w.Min.Y; y < w.Max.Y; y++ {
+		for x := w.Min.X; x < w.Max.X; x++ {
+			if x != w.Min.X && x != w.Max.X-1 && y != w.Min.Y && y != w.Max.Y-1 {
+				continue
+			}
+			px, py, v := float64(x)+0.5-cx, float64(y)+0.5-cy, field.FloatAt(x, y)
+			n++
+			sx += px
+			sy += py
+			sxx += px * px
+			sxy += px * py
+			syy += py * py
+			sv += v
+			sxv += px * v
+			syv += py * v
+		}
+	}
+	// solve by Cramer's rule
+	det3 := func(a, b, c, d, e, f, g, h, i float64) float64 {
+		return a*(e*i-f*h) - b*(d*i-f*g) + c*(d*h-e*g)
+	}
+	d := det3(n, sx, sy, sx, sxx, sxy, sy, sxy, syy)
+	a := det3(sv, sx, sy, sxv, sxx, sxy, syv, sxy, syy) / d
+	b := det3(n, sv, sy, sx, sxv, sxy, sy, syv, syy) / d
+	c := det3(n, sx, sv, sx, sxx, sxv, sy, sxy, syv) / d
+	return func(x, y float64) float64 {
+		return a + b*(x-cx) + c*(y-cy)
+	}
+}
@@ -0,0 +1,62 @@
+package objsearch
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestDetectPoints(t *testing.T) {
+	type star struct{ x, y, amp float64 }
+	stars := []star{{20.3, 15.7, 0.5}, {60.5, 40.2, 0.2}, {95.8, 70.4, 0.8}, {30.1, 75.6, 0.35}}
+	sigma := 1.5
+	// a 16-bit exposure with a sloping background and a little noise
+	img := image.NewGray16(image.Rect(0, 0, 120, 90))
+	for y := 0; y < 90; y++ {
+		for x := 0; x < 120; x++ {
+			v := 0.1 + 0.05*float64(x)/120 + rand.NormFloat64()*0.001
+			for _, s := range stars {
+				dx, dy := float64(x)+0.5-s.x, float64(y)+0.5-s.y
+				v += s.amp * math.Exp(-(dx*dx+dy*dy)/(2*sigma*sigma))
+			}
+			img.SetGray16(x, y, color.Gray16{uint16(math.Round(v * 0xffff))})
+		}
+	}
+	field := FloatImageFromGray16(img)
+	found := DetectPoints(field, PSF{PSF_GAUSSIAN, sigma, 7}, Options{Tolerance: 0.9})
+	if len(found) != len(stars) {
+		t.Fatal("wrong number of sources", found)
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Flux > found[j].Flux })
+	sort.Slice(stars, func(i, j int) bool { return stars[i].amp > stars[j].amp })
+	for i, s := range stars {
+		f := found[i]
+		if math.Hypot(f.X-s.x, f.Y-s.y) > 0.25 {
+			t.Fatal("centroid error", s, f)
+		}
+		if flux := s.amp * 2 * math.Pi * sigma * sigma; math.Abs(f.Flux-flux) > 0.05*flux {
+			t.Fatal("flux error", s, f, flux)
+		}
+	}
+}
+
+func TestPSFTemplate(t *testing.T) {
+	for _, kind := range []PSFKind{PSF_GAUSSIAN, PSF_AIRY} {
+		tp := PSF{kind, 2, 6}.Template()
+		if tp.Rect != image.Rect(0, 0, 13, 13) || tp.FloatAt(6, 6) != 1 {
+			t.Fatal("template error", kind)
+		}
+		// falls off from the center
+		if !(tp.FloatAt(7, 6) < 1 && tp.FloatAt(8, 6) < tp.FloatAt(7, 6) && tp.FloatAt(12, 6) < 0.05) {
+			t.Fatal("template falloff error", kind, tp.FloatAt(7, 6), tp.FloatAt(8, 6), tp.FloatAt(12, 6))
+		}
+	}
+	// the Airy disk's first dark ring is at about 2.9 sigma
+	airy := PSF{PSF_AIRY, 2, 8}.Template()
+	if v := airy.FloatAt(8+6, 8); v > 1e-3 {
+		t.Fatal("Airy dark ring error", v)
+	}
+}
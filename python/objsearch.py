@@ -0,0 +1,115 @@
+"""Python bindings for objsearch.
+
+Searches numpy images for objects using the objsearch C shared library, built
+from the Go sources with
+
+    go build -buildmode=c-shared -o python/libobjsearch.so ./cshared
+
+The library is loaded from the path in the OBJSEARCH_LIB environment
+variable, or from libobjsearch.so next to this file.
+
+Example:
+
+    import numpy as np
+    import objsearch
+
+    hits = objsearch.search(field, template, tolerance=0.05, min_dist=10)
+    for x, y, score in hits:
+        ...
+"""
+
+import ctypes
+import os
+
+import numpy as np
+
+__all__ = ["search", "Hit", "GRAY", "RGB", "MAX", "MEAN"]
+
+# objsearch.ColorMode values
+GRAY, RGB = 0, 1
+# objsearch.CombineMode values
+MAX, MEAN = 0, 1
+
+# objsearch.PixelFormat values, by number of channels
+_FORMATS = {1: 8, 3: 6, 4: 0}  # GRAY8, RGB24, RGBA
+
+
+class _Hit(ctypes.Structure):
+    _fields_ = [("x", ctypes.c_int32), ("y", ctypes.c_int32), ("score", ctypes.c_double)]
+
+
+class Hit(tuple):
+    """A detected occurence of the object, as an (x, y, score) tuple, where
+    (x, y) is the top-left corner of the object in the field."""
+
+    __slots__ = ()
+
+    def __new__(cls, x, y, score):
+        return tuple.__new__(cls, (x, y, score))
+
+    x = property(lambda self: self[0])
+    y = property(lambda self: self[1])
+    score = property(lambda self: self[2])
+
+    def __repr__(self):
+        return "Hit(x=%d, y=%d, score=%g)" % self
+
+
+_lib = None
+
+
+def _library():
+    global _lib
+    if _lib is None:
+        path = os.environ.get("OBJSEARCH_LIB") or os.path.join(
+            os.path.dirname(os.path.abspath(__file__)), "libobjsearch.so"
+        )
+        lib = ctypes.CDLL(path)
+        image_args = [ctypes.c_void_p] + [ctypes.c_int] * 4
+        lib.objsearch_search.argtypes = (
+            image_args
+            + image_args
+            + [ctypes.c_double, ctypes.c_int, ctypes.c_int, ctypes.c_int]
+            + [ctypes.POINTER(_Hit), ctypes.c_int]
+        )
+        lib.objsearch_search.restype = ctypes.c_int
+        lib.objsearch_last_error.restype = ctypes.c_char_p
+        _lib = lib
+    return _lib
+
+
+def _image_args(img, name):
+    """Returns the pointer, width, height, stride and format of a uint8 numpy
+    image of shape (h, w), (h, w, 3) or (h, w, 4)."""
+    img = np.asarray(img)
+    if img.dtype != np.uint8:
+        raise TypeError("%s must have dtype uint8, not %s" % (name, img.dtype))
+    channels = 1 if img.ndim == 2 else img.shape[2] if img.ndim == 3 else 0
+    if channels not in _FORMATS:
+        raise ValueError("%s must have shape (h, w), (h, w, 3) or (h, w, 4)" % name)
+    if img.strides[-1] != 1 or img.strides[1] != channels or img.strides[0] < 0:
+        img = np.ascontiguousarray(img)
+    h, w = img.shape[:2]
+    return img, (img.ctypes.data, w, h, img.strides[0], _FORMATS[channels])
+
+
+def search(field, obj, tolerance=0.0, min_dist=0, color_mode=GRAY, combine_mode=MAX):
+    """Searches field for obj, both uint8 numpy arrays of shape (h, w)
+    (grayscale), (h, w, 3) (RGB) or (h, w, 4) (RGBA), and returns a list of
+    Hits sorted by score. Scores are in [0, 1], and only hits scoring below
+    tolerance and at least min_dist pixels apart are returned."""
+    lib = _library()
+    # keep references to any contiguous copies until the call returns
+    field, field_args = _image_args(field, "field")
+    obj, obj_args = _image_args(obj, "obj")
+    n = 16
+    while True:
+        out = (_Hit * n)()
+        found = lib.objsearch_search(
+            *field_args, *obj_args, tolerance, min_dist, color_mode, combine_mode, out, n
+        )
+        if found < 0:
+            raise ValueError(lib.objsearch_last_error().decode())
+        if found <= n:
+            return [Hit(h.x, h.y, h.score) for h in out[:found]]
+        n = found
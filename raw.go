@@ -0,0 +1,133 @@
+package objsearch
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// Pixel layout of a raw framebuffer
+type PixelFormat int
+
+const (
+	// 4 bytes per pixel, non-premultiplied alpha
+	PIXELFORMAT_RGBA PixelFormat = iota
+	PIXELFORMAT_BGRA
+	PIXELFORMAT_ARGB
+	PIXELFORMAT_ABGR
+	// 4 bytes per pixel, fourth byte is padding
+	PIXELFORMAT_RGBX
+	PIXELFORMAT_BGRX
+	// 3 bytes per pixel
+	PIXELFORMAT_RGB24
+	PIXELFORMAT_BGR24
+	// 1 byte per pixel
+	PIXELFORMAT_GRAY8
+)
+
+// Returns the number of bytes per pixel of f
+func (f PixelFormat) BytesPerPixel() int {
+	switch f {
+	case PIXELFORMAT_RGBA, PIXELFORMAT_BGRA, PIXELFORMAT_ARGB, PIXELFORMAT_ABGR, PIXELFORMAT_RGBX, PIXELFORMAT_BGRX:
+		return 4
+	case PIXELFORMAT_RGB24, PIXELFORMAT_BGR24:
+		return 3
+	case PIXELFORMAT_GRAY8:
+		return 1
+	default:
+		panic("invalid pixel format")
+	}
+}
+
+// Returns an image wrapping the raw framebuffer pix, which holds a w by h
+// image with rows stride bytes apart, in the given format. pix is not copied,
+// so the image reflects later changes to pix.
+//
+// The image returned is an *OrderedRGBA, *OrderedRGB or *image.Gray, and has
+// bounds (0,0)-(w,h). It may be passed to SearchImage as a field or object.
+func NewRawImage(pix []byte, w, h, stride int, format PixelFormat) (image.Image, error) {
+	bpp := format.BytesPerPixel()
+	if w < 0 || h < 0 {
+		return nil, fmt.Errorf("invalid dimensions %dx%d", w, h)
+	}
+	if stride < w*bpp {
+		return nil, fmt.Errorf("stride %d too small for width %d", stride, w)
+	}
+	if h > 0 && len(pix) < (h-1)*stride+w*bpp {
+		return nil, fmt.Errorf("buffer of %d bytes too small for %dx%d image with stride %d", len(pix), w, h, stride)
+	}
+	r := image.Rect(0, 0, w, h)
+	switch format {
+	case PIXELFORMAT_RGBA:
+		return &OrderedRGBA{pix, stride, r, CHANNELORDER_RGBA}, nil
+	case PIXELFORMAT_BGRA:
+		return &OrderedRGBA{pix, stride, r, CHANNELORDER_BGRA}, nil
+	case PIXELFORMAT_ARGB:
+		return &OrderedRGBA{pix, stride, r, CHANNELORDER_ARGB}, nil
+	case PIXELFORMAT_ABGR:
+		return &OrderedRGBA{pix, stride, r, CHANNELORDER_ABGR}, nil
+	case PIXELFORMAT_RGBX:
+		return &OrderedRGBA{pix, stride, r, CHANNELORDER_RGBX}, nil
+	case PIXELFORMAT_BGRX:
+		return &OrderedRGBA{pix, stride, r, CHANNELORDER_BGRX}, nil
+	case PIXELFORMAT_RGB24:
+		return &OrderedRGB{pix, stride, r, CHANNELORDER_RGBX}, nil
+	case PIXELFORMAT_BGR24:
+		return &OrderedRGB{pix, stride, r, CHANNELORDER_BGRX}, nil
+	default:
+		return &image.Gray{Pix: pix, Stride: stride, Rect: r}, nil
+	}
+}
+
+// Like SearchImageChecked, for field and object given as raw framebuffers.
+// See NewRawImage.
+func SearchRaw(field []byte, fieldW, fieldH, fieldStride int, fieldFormat PixelFormat, object []byte, objectW, objectH, objectStride int, objectFormat PixelFormat, rect image.Rectangle, opts Options) ([]Hit, error) {
+	f, err := NewRawImage(field, fieldW, fieldH, fieldStride, fieldFormat)
+	if err != nil {
+		return nil, fmt.Errorf("field: %v", err)
+	}
+	o, err := NewRawImage(object, objectW, objectH, objectStride, objectFormat)
+	if err != nil {
+		return nil, fmt.Errorf("object: %v", err)
+	}
+	return SearchImageChecked(f, o, rect, opts)
+}
+
+// An in-memory opaque image with 3 bytes per pixel. Order gives the channel
+// order of the first three bytes of a 4-byte pixel, and must be
+// CHANNELORDER_RGBX or CHANNELORDER_BGRX.
+type OrderedRGB struct {
+	// Pix holds the image's pixels. The pixel at (x,y) starts at
+	// Pix[(y-Rect.Min.Y)*Stride+(x-Rect.Min.X)*3]
+	Pix []uint8
+	// Stride is the Pix distance in bytes between vertically adjacent pixels
+	Stride int
+	Rect   image.Rectangle
+	Order  ChannelOrder
+}
+
+// Returns the index of the first byte of the pixel at (x,y) in p.Pix
+func (p *OrderedRGB) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x-p.Rect.Min.X)*3
+}
+
+func (p *OrderedRGB) NRGBAAt(x, y int) color.NRGBA {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return color.NRGBA{}
+	}
+	i := p.PixOffset(x, y)
+	r, g, b, _ := p.Order.offsets()
+	return color.NRGBA{p.Pix[i+r], p.Pix[i+g], p.Pix[i+b], 255}
+}
+
+func (p *OrderedRGB) Bounds() image.Rectangle {
+	return p.Rect
+}
+
+func (p *OrderedRGB) ColorModel() color.Model {
+	return color.NRGBAModel
+}
+
+func (p *OrderedRGB) At(x, y int) color.Color {
+	return p.NRGBAAt(x, y)
+}
@@ -0,0 +1,114 @@
+package objsearch
+
+import (
+	"image"
+	"sort"
+)
+
+// A field with known object locations, for RecommendOptions
+type Example struct {
+	Field image.Image
+	// Top-left corners of every occurence of the object in Field
+	Objects []image.Point
+}
+
+// The result of RecommendOptions
+type Recommendation struct {
+	Tolerance float64
+	MinDist   int
+	// Precision and recall achieved on the examples with Tolerance and
+	// MinDist
+	Precision, Recall float64
+}
+
+// Suggests a Tolerance and MinDist for searching for object, by searching
+// the examples with opts and choosing the tolerance that maximizes the F1
+// score of the hits. MinDist is the object's larger dimension, reduced if
+// necessary so that no two known objects suppress one another. A hit is
+// correct if it lies within MinDist/2 pixels of an unmatched known object.
+// Tolerance applies to opts.ScoreMode.
+func RecommendOptions(object image.Image, examples []Example, opts Options) (r Recommendation) {
+	size := object.Bounds().Size()
+	r.MinDist = size.X
+	if size.Y > r.MinDist {
+		r.MinDist = size.Y
+	}
+	total := 0
+	for _, e := range examples {
+		total += len(e.Objects)
+		for i := range e.Objects {
+			for j := i + 1; j < len(e.Objects); j++ {
+				if d := (Hit{P: e.Objects[i]}).Distance(Hit{P: e.Objects[j]}); d < r.MinDist {
+					r.MinDist = d
+				}
+			}
+		}
+	}
+	if r.MinDist < 1 {
+		r.MinDist = 1
+	}
+	// score every candidate hit as correct or not
+	type candidate struct {
+		s       float64
+		correct bool
+	}
+	var candidates []candidate
+	for _, e := range examples {
+		rect := validRect(e.Field.Bounds(), object.Bounds())
+		if rect.Empty() {
+			continue
+		}
+		ctx, scores := searchScores(e.Field, object, rect, opts)
+		matched := make([]bool, len(e.Objects))
+		for _, h := range candidateHits(ctx, scores, r.MinDist) {
+			c := candidate{s: h.S}
+			for i, p := range e.Objects {
+				if !matched[i] && (Hit{P: p}).Distance(h) <= r.MinDist/2 {
+					matched[i], c.correct = true, true
+					break
+				}
+			}
+			candidates = append(candidates, c)
+		}
+	}
+	ctx := newContext(image.Rectangle{}, opts)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return ctx.better(candidates[i].s, candidates[j].s)
+	})
+	// the worst score, beyond which the tolerance accepts every candidate
+	worst := 1.0
+	if ctx.ScoreMode == SCOREMODE_CCOEFF_NORMED {
+		worst = -1
+	}
+	// accept the k best candidates, choosing k to maximize F1
+	bestF1, tp := 0.0, 0
+	for k := 1; k <= len(candidates); k++ {
+		if candidates[k-1].correct {
+			tp++
+		}
+		if k < len(candidates) && candidates[k].s == candidates[k-1].s {
+			// no tolerance separates candidates k-1 and k
+			continue
+		}
+		f1 := 2 * float64(tp) / float64(k+total)
+		if f1 > bestF1 {
+			bestF1 = f1
+			r.Precision = float64(tp) / float64(k)
+			r.Recall = float64(tp) / float64(total)
+			next := worst
+			if k < len(candidates) {
+				next = candidates[k].s
+			}
+			r.Tolerance = (candidates[k-1].s + next) / 2
+		}
+	}
+	return
+}
+
+// return the local minima of scores, as hits are scored according to
+// ctx.ScoreMode, at least minDist apart, best first. These are the hits at
+// every tolerance.
+func candidateHits(ctx objSearchContext, scores []float64, minDist int) []Hit {
+	ctx.MinDist = minDist
+	return ctx.suppress(ctx.minima(scores, 0))
+}
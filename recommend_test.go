@@ -0,0 +1,33 @@
+package objsearch
+
+import (
+	"image"
+	"testing"
+)
+
+func TestRecommendOptions(t *testing.T) {
+	object := randomRGBImage(8, 8)
+	var examples []Example
+	for i := 0; i < 3; i++ {
+		field := randomRGBImage(60, 60)
+		e := Example{Field: field}
+		for _, p := range []image.Point{{5, 5}, {30, 8}, {12 + 10*i, 40}} {
+			field = frameWithObject(field, object, p)
+			e.Objects = append(e.Objects, p)
+		}
+		e.Field = field
+		examples = append(examples, e)
+	}
+	for _, mode := range []ScoreMode{SCOREMODE_L1, SCOREMODE_ZSCORE, SCOREMODE_CCOEFF_NORMED} {
+		r := RecommendOptions(object, examples, Options{ScoreMode: mode})
+		if r.Precision != 1 || r.Recall != 1 || r.MinDist != 8 {
+			t.Fatal("recommendation error", mode, r)
+		}
+		for _, e := range examples {
+			hits := SearchImage(e.Field, object, validRect(e.Field.Bounds(), object.Bounds()), Options{Tolerance: r.Tolerance, MinDist: r.MinDist, ScoreMode: mode})
+			if len(hits) != len(e.Objects) {
+				t.Fatal("recommended options find wrong hits", mode, r, hits)
+			}
+		}
+	}
+}
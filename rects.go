@@ -0,0 +1,57 @@
+package objsearch
+
+import "image"
+
+// Like SearchImage, searching only the top-left corners in rects, which may
+// overlap, as one search: positions in more than one rect are scored once,
+// SCOREMODE_L1 and SCOREMODE_ZSCORE distances are normalized over all the
+// rects together, and hits are at least opts.MinDist apart across rects,
+// so that no duplicate hits are found where rects meet.
+//
+// AdaptiveTolerance compares local contrast with its mean over each
+// rect, and HITMODE_LOCALMINIMA measures prominence within each rect.
+func SearchRects(field, object image.Image, rects []image.Rectangle, opts Options) []Hit {
+	f, o := NewField(field), NewObject(object)
+	valid := searchableRect(f.Bounds(), o.Bounds(), opts.Border)
+	// split rects into disjoint pieces
+	var pieces, searched []image.Rectangle
+	for _, r := range rects {
+		p := []image.Rectangle{r.Intersect(valid)}
+		for _, s := range searched {
+			p = subtractRects(p, s)
+		}
+		for _, r := range p {
+			if !r.Empty() {
+				pieces = append(pieces, r)
+			}
+		}
+		searched = append(searched, r)
+	}
+	if len(pieces) == 0 {
+		return nil
+	}
+	ctxs := make([]objSearchContext, len(pieces))
+	scores := make([][]float64, len(pieces))
+	var stats distanceStats
+	for i, piece := range pieces {
+		ctxs[i], scores[i] = searchScores(f, o, piece, opts)
+		stats.add(scores[i])
+	}
+	var scoreRange *[2]float64
+	if newContext(valid, opts).relativeScores() {
+		if scoreRange = stats.scoreRange(opts.ScoreMode); scoreRange == nil {
+			// all distances are equal
+			return nil
+		}
+	}
+	var hits []Hit
+	for i := range pieces {
+		ctxs[i].ScoreRange = scoreRange
+		hits = append(hits, ctxs[i].hits(scores[i])...)
+	}
+	hits = newContext(valid, opts).suppress(hits)
+	if opts.HitMode == HITMODE_BESTK && len(hits) > opts.K {
+		hits = hits[:opts.K]
+	}
+	return hits
+}
@@ -0,0 +1,53 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/hypoactiv/objsearch/objsearchtest"
+)
+
+func TestSearchRects(t *testing.T) {
+	g := objsearchtest.NewGenerator(1)
+	object := g.Noise(10, 10)
+	field := g.Noise(100, 80)
+	for _, p := range []image.Point{{3, 4}, {48, 30}, {80, 60}} {
+		field = frameWithObject(field, object, p)
+	}
+	// overlapping rects covering the field, meeting at the second object
+	rects := []image.Rectangle{
+		image.Rect(0, 0, 50, 71),
+		image.Rect(40, 0, 91, 71),
+		image.Rect(0, 0, 91, 31),
+	}
+	for _, opts := range []Options{
+		{Tolerance: 0.2, MinDist: 5},
+		{Tolerance: -4, MinDist: 5, ScoreMode: SCOREMODE_ZSCORE},
+		{Tolerance: 0.9, MinDist: 5, ScoreMode: SCOREMODE_CCOEFF_NORMED},
+		{MinDist: 5, HitMode: HITMODE_BESTK, K: 3},
+	} {
+		want := SearchImage(field, object, validRect(field.Rect, object.Rect), opts)
+		hits := SearchRects(field, object, rects, opts)
+		// the hits tie
+		SortRaster(hits)
+		SortRaster(want)
+		if len(hits) != 3 || len(hits) != len(want) {
+			t.Fatal("rects search error", opts.ScoreMode, hits, want)
+		}
+		for i := range hits {
+			if hits[i].P != want[i].P || math.Abs(hits[i].S-want[i].S) > 1e-9 {
+				t.Fatal("rects search error", opts.ScoreMode, hits, want)
+			}
+		}
+	}
+	// only the rects are searched
+	hits := SearchRects(field, object, rects[:1], Options{Tolerance: 0.2, MinDist: 5})
+	SortRaster(hits)
+	if len(hits) != 2 || hits[0].P != (image.Point{3, 4}) || hits[1].P != (image.Point{48, 30}) {
+		t.Fatal("rects search error", hits)
+	}
+	if hits := SearchRects(field, object, []image.Rectangle{image.Rect(200, 200, 210, 210)}, Options{}); hits != nil {
+		t.Fatal("hits outside the field", hits)
+	}
+}
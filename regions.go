@@ -0,0 +1,94 @@
+package objsearch
+
+import (
+	"image"
+	"sort"
+)
+
+// A connected region of positions scoring better than the tolerance
+type RegionHit struct {
+	// The best-scoring position of the region, and its score
+	Hit
+	// Bounding box of the region's positions, which are top-left corners of
+	// the object
+	Bounds image.Rectangle
+	// Number of positions in the region
+	Area int
+	// Mean of the region's positions
+	CentroidX, CentroidY float64
+}
+
+// Like SearchImage, returning each 8-connected region of positions scoring
+// better than the tolerance as a single hit, best first, instead of
+// suppressing neighboring positions by opts.MinDist. For elongated or
+// repeated objects, regions describe the matches better than individual
+// positions. opts.MinDist and opts.HitMode are ignored.
+func SearchRegions(field, object image.Image, rect image.Rectangle, opts Options) []RegionHit {
+	ctx, scores := searchScores(field, object, rect, opts)
+	if ctx.SearchRect.Empty() {
+		return nil
+	}
+	return ctx.regions(scores)
+}
+
+// Returns the regions of r's scores, as SearchRegions does
+func (r *Result) Regions() []RegionHit {
+	return r.context(r.Options).regions(r.Scores.Pix)
+}
+
+// return the connected regions of scores scoring better than ctx.Tolerance,
+// best first
+func (ctx objSearchContext) regions(scores []float64) (regions []RegionHit) {
+	s := ctx.hitScores(scores)
+	if s == nil {
+		return nil
+	}
+	w, h := ctx.SearchRect.Dx(), ctx.SearchRect.Dy()
+	tolerance := ctx.tolerance()
+	visited := make([]bool, len(s))
+	var stack []int
+	for i := range s {
+		if visited[i] || !ctx.better(s[i], tolerance(i)) {
+			continue
+		}
+		// flood fill the region containing i
+		x, y := ctx.coords(i)
+		r := RegionHit{Hit: Hit{image.Point{x, y}, s[i]}}
+		var sx, sy int
+		visited[i] = true
+		stack = append(stack[:0], i)
+		for len(stack) > 0 {
+			j := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			x, y := ctx.coords(j)
+			p := image.Point{x, y}
+			r.Bounds = r.Bounds.Union(image.Rectangle{p, p.Add(image.Point{1, 1})})
+			r.Area++
+			sx, sy = sx+x, sy+y
+			if ctx.better(s[j], r.S) {
+				r.Hit = Hit{p, s[j]}
+			}
+			u, v := j%w, j/w
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nu, nv := u+dx, v+dy
+					if nu < 0 || nu >= w || nv < 0 || nv >= h {
+						continue
+					}
+					k := nv*w + nu
+					if !visited[k] && ctx.better(s[k], tolerance(k)) {
+						visited[k] = true
+						stack = append(stack, k)
+					}
+				}
+			}
+		}
+		r.CentroidX = float64(sx) / float64(r.Area)
+		r.CentroidY = float64(sy) / float64(r.Area)
+		regions = append(regions, r)
+	}
+	sort.SliceStable(regions, func(i, j int) bool {
+		return ctx.better(regions[i].S, regions[j].S)
+	})
+	return
+}
@@ -0,0 +1,45 @@
+package objsearch
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/draw"
+	"reflect"
+	"testing"
+)
+
+func TestRegions(t *testing.T) {
+	// a horizontal stripe matches the object anywhere along it
+	object := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	draw.Draw(object, object.Rect, image.NewUniform(color.White), image.Point{}, draw.Src)
+	field := randomRGBImage(60, 40)
+	draw.Draw(field, image.Rect(10, 5, 40, 9), image.NewUniform(color.White), image.Point{}, draw.Src)
+	// and a single square elsewhere
+	draw.Draw(field, image.Rect(20, 30, 24, 34), image.NewUniform(color.White), image.Point{}, draw.Src)
+	rect := validRect(field.Rect, object.Rect)
+	opts := Options{Tolerance: 0.01}
+	regions := SearchRegions(field, object, rect, opts)
+	if len(regions) != 2 {
+		t.Fatal("regions error", regions)
+	}
+	// both score 0, so they remain in raster order
+	stripe, square := regions[0], regions[1]
+	if stripe.Bounds != image.Rect(10, 5, 37, 6) || stripe.Area != 27 || stripe.CentroidX != 23 || stripe.CentroidY != 5 || stripe.S != 0 {
+		t.Fatal("stripe region error", stripe)
+	}
+	if square.Bounds != image.Rect(20, 30, 21, 31) || square.Area != 1 || square.P != (image.Point{20, 30}) {
+		t.Fatal("square region error", square)
+	}
+	if r := SearchResult(field, object, rect, opts).Regions(); len(r) != 2 {
+		t.Fatal("result regions error")
+	}
+	b, err := json.Marshal(stripe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d RegionHit
+	if err := json.Unmarshal(b, &d); err != nil || !reflect.DeepEqual(d, stripe) {
+		t.Fatal("JSON round trip error", string(b), err)
+	}
+}
@@ -0,0 +1,97 @@
+package objsearch
+
+import (
+	"bytes"
+	"encoding/base64"
+	"html/template"
+	"image"
+	"image/png"
+	"io"
+	"time"
+)
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+td, th { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+img { image-rendering: pixelated; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>Generated {{.Time.Format "2006-01-02 15:04:05 MST"}}. {{len .Hits}} hit(s) found.</p>
+<h2>Parameters</h2>
+<table>
+<tr><th>Object</th><th>Size</th><th>Tolerance</th><th>MinDist</th><th>ColorMode</th><th>CombineMode</th></tr>
+{{range .Objects}}<tr><td>{{.Label}}</td><td>{{.Size.X}}x{{.Size.Y}}</td><td>{{.Options.Tolerance}}</td><td>{{.Options.MinDist}}</td><td>{{.Options.ColorMode}}</td><td>{{.Options.CombineMode}}</td></tr>
+{{end}}</table>
+<h2>Field</h2>
+<img src="{{.Field}}" alt="annotated field">
+<h2>Hits</h2>
+<table>
+<tr><th>#</th><th>Object</th><th>Crop</th><th>X</th><th>Y</th><th>Score</th></tr>
+{{range $i, $h := .Hits}}<tr><td>{{$i}}</td><td>{{$h.Label}}</td><td><img src="{{$h.Crop}}" alt="crop"></td><td>{{$h.P.X}}</td><td>{{$h.P.Y}}</td><td>{{printf "%.4f" $h.S}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// data for reportTemplate
+type reportData struct {
+	Title   string
+	Time    time.Time
+	Field   template.URL
+	Objects []reportObject
+	Hits    []reportHit
+}
+
+type reportObject struct {
+	Label   string
+	Size    image.Point
+	Options Options
+}
+
+type reportHit struct {
+	LabeledHit
+	Crop template.URL
+}
+
+// Writes a self-contained HTML report of a search of field for the objects in
+// set to w. The report embeds the field annotated with hits, a crop of the
+// field at each hit, the hits' scores, and the options each object was
+// searched for with.
+func WriteReport(w io.Writer, title string, field image.Image, set *TemplateSet, hits []LabeledHit) error {
+	f := NewField(field)
+	d := reportData{Title: title, Time: time.Now()}
+	var err error
+	if d.Field, err = pngDataURL(DrawLabeledHits(f, hits, set, HitStyle{Score: true})); err != nil {
+		return err
+	}
+	for _, label := range set.Labels() {
+		s := set.Searcher(label)
+		d.Objects = append(d.Objects, reportObject{label, s.Object.Bounds().Size(), s.Options})
+	}
+	for _, h := range hits {
+		r := set.Searcher(h.Label).Object.Bounds().Add(h.P)
+		crop, err := pngDataURL(f.SubImage(r))
+		if err != nil {
+			return err
+		}
+		d.Hits = append(d.Hits, reportHit{h, crop})
+	}
+	return reportTemplate.Execute(w, d)
+}
+
+// return img encoded as a PNG data URL
+func pngDataURL(img image.Image) (template.URL, error) {
+	b := &bytes.Buffer{}
+	if err := png.Encode(b, img); err != nil {
+		return "", err
+	}
+	return template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(b.Bytes())), nil
+}
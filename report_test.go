@@ -0,0 +1,43 @@
+package objsearch
+
+import (
+	"bytes"
+	"encoding/base64"
+	"html"
+	"image"
+	"image/png"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestWriteReport(t *testing.T) {
+	object := randomRGBImage(8, 8)
+	field := frameWithObject(randomRGBImage(50, 40), object, image.Point{12, 9})
+	set := NewTemplateSet(Options{Tolerance: 0.05, ColorMode: COLORMODE_RGB})
+	set.Add("<button>", object)
+	hits := set.SearchAll(field)
+	b := &bytes.Buffer{}
+	if err := WriteReport(b, "Check", field, set, hits); err != nil {
+		t.Fatal(err)
+	}
+	page := b.String()
+	if !strings.Contains(page, "&lt;button&gt;") || strings.Contains(page, "<button>") {
+		t.Fatal("label not escaped")
+	}
+	if !strings.Contains(page, "<td>rgb</td>") || !strings.Contains(page, "<td>0.05</td>") {
+		t.Fatal("parameters missing")
+	}
+	urls := regexp.MustCompile(`src="data:image/png;base64,([^"]*)"`).FindAllStringSubmatch(page, -1)
+	if len(urls) != 2 {
+		t.Fatal("expected field and crop images", len(urls))
+	}
+	data, err := base64.StdEncoding.DecodeString(html.UnescapeString(urls[1][1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	crop, err := png.Decode(bytes.NewReader(data))
+	if err != nil || crop.Bounds().Size() != (image.Point{8, 8}) {
+		t.Fatal("crop error", err)
+	}
+}
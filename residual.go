@@ -0,0 +1,52 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+)
+
+// Returns the absolute per-pixel difference between object and the window of
+// field at hit h, with the bounds of object, to show which parts of a weak
+// match deviate from the object. Differences are taken between the planes
+// selected by opts.ColorMode and combined according to opts.CombineMode and
+// opts.ChannelWeights, and are scaled by the weight of each object pixel, so
+// that excluded pixels are 0. For 8-bit images, differences are in [0,1].
+//
+// The weighted mean of the residual is the distance of h, as returned by
+// DistanceMap. Returns nil if h is not a position at which object can be
+// scored in field, as set by opts.Border, or panics with a *RectError if
+// opts.StrictRect is set.
+func Residual(field, object image.Image, h Hit, opts Options) *FloatImage {
+	ctx, fieldPlanes, objectPlanes := newImageContext(field, object, image.Rectangle{h.P, h.P.Add(image.Point{1, 1})}, opts)
+	if ctx.SearchRect.Empty() {
+		return nil
+	}
+	r := objectPlanes[0].Rect
+	res := NewFloatImage(r)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			w := 1.0
+			if ctx.Mask != nil {
+				w = float64(ctx.Mask.AlphaAt(x, y).A) / 255
+			}
+			d := 0.0
+			for c := range fieldPlanes {
+				f, o := fieldPlanes[c], objectPlanes[c]
+				dc := math.Abs(f.Pix[f.PixOffset(x+h.P.X, y+h.P.Y)] - o.Pix[o.PixOffset(x, y)])
+				if ctx.ChannelWeights != nil {
+					dc *= ctx.ChannelWeights[c]
+				}
+				if ctx.CombineMode == COMBINEMODE_MAX {
+					d = math.Max(d, dc)
+				} else {
+					d += dc
+				}
+			}
+			if ctx.CombineMode == COMBINEMODE_MEAN {
+				d /= ctx.channelWeight(len(fieldPlanes))
+			}
+			res.Pix[res.PixOffset(x, y)] = w * d
+		}
+	}
+	return res
+}
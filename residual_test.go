@@ -0,0 +1,41 @@
+package objsearch
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestResidual(t *testing.T) {
+	object := randomRGBImage(10, 8)
+	field := frameWithObject(randomRGBImage(40, 30), object, image.Point{12, 7})
+	// changed text inside an otherwise identical window
+	for x := 15; x < 18; x++ {
+		field.SetRGBA(x, 10, color.RGBA{255, 255, 255, 255})
+	}
+	opts := Options{ColorMode: COLORMODE_RGB, CombineMode: COMBINEMODE_MEAN}
+	h := Hit{P: image.Point{12, 7}}
+	res := Residual(field, object, h, opts)
+	if res.Rect != object.Rect {
+		t.Fatal("residual bounds error")
+	}
+	sum := 0.0
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 10; x++ {
+			v := res.FloatAt(x, y)
+			if changed := y == 3 && x >= 3 && x < 6; changed != (v > 0) {
+				t.Fatal("residual error at", x, y, v)
+			}
+			sum += v
+		}
+	}
+	d := DistanceMap(field, object, image.Rectangle{h.P, h.P.Add(image.Point{1, 1})}, opts)
+	if math.Abs(sum/80-d.Pix[0]) > 1e-12 {
+		t.Fatal("residual mean is not the distance", sum/80, d.Pix[0])
+	}
+	// a window past the field's edge
+	if res := Residual(field, object, Hit{P: image.Point{35, 7}}, opts); res != nil {
+		t.Fatal("expected no residual outside the field", res.Rect)
+	}
+}
@@ -0,0 +1,211 @@
+package objsearch
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"image"
+	"io"
+)
+
+// The full result of searching a field for an object: the score at every
+// position searched, as well as the hits found. A Result can be saved with
+// MarshalBinary and later re-thresholded with Rethreshold or searched for
+// further occurrences with Next, so that expensive scans need only be
+// computed once.
+type Result struct {
+	// Score of the object at each top-left corner searched, before
+	// normalization, according to Options.ScoreMode. For SCOREMODE_L1, this
+	// is the distance map returned by DistanceMap.
+	Scores *FloatImage
+	// Minimum and maximum of Scores
+	Min, Max float64
+	// Options used for the search. VerboseOut is not saved.
+	Options Options
+	// Hits found with Options
+	Hits []Hit
+	// If Options.AdaptiveTolerance is set, the local contrast of the field at
+	// each position, by which the tolerance is scaled
+	Contrast *FloatImage
+}
+
+// Like SearchImage, returning the full Result of the search. If rect is
+// empty, every position at which object lies entirely within field is
+// searched. Returns nil if there are no positions to search.
+func SearchResult(field, object image.Image, rect image.Rectangle, opts Options) *Result {
+	if rect.Empty() {
+		rect = searchableRect(field.Bounds(), object.Bounds(), opts.Border)
+	}
+	rect, err := CheckRect(field.Bounds(), object.Bounds(), rect, opts)
+	if err != nil {
+		panic(err)
+	}
+	if rect.Empty() {
+		return nil
+	}
+	ctx, scores := searchScores(field, object, rect, opts)
+	r := &Result{
+		Scores:  &FloatImage{Pix: scores, Stride: rect.Dx(), Rect: rect},
+		Options: opts,
+		Hits:    ctx.hits(scores),
+	}
+	r.Min, r.Max = minMax(scores)
+	if ctx.Contrast != nil {
+		r.Contrast = &FloatImage{Pix: ctx.Contrast, Stride: rect.Dx(), Rect: rect}
+	}
+	return r
+}
+
+// return a search context for r's scores
+func (r *Result) context(opts Options) objSearchContext {
+	ctx := newContext(r.Scores.Rect, opts)
+	if r.Contrast != nil {
+		ctx.Contrast = r.Contrast.Pix
+	}
+	return ctx
+}
+
+// Returns r.Scores as hits are scored according to Options.ScoreMode, e.g.
+// normalized so that the largest distance scores 1 for SCOREMODE_L1. Returns
+// nil if the scores cannot be normalized because they are all equal.
+func (r *Result) HitScores() *FloatImage {
+	s := r.context(r.Options).hitScores(r.Scores.Pix)
+	if s == nil {
+		return nil
+	}
+	return &FloatImage{Pix: s, Stride: r.Scores.Stride, Rect: r.Scores.Rect}
+}
+
+// Returns the hits in r's scores for the given tolerance and minimum
+// distance between hits, as if the search were repeated with them
+func (r *Result) Rethreshold(tolerance float64, minDist int) []Hit {
+	opts := r.Options
+	opts.Tolerance, opts.MinDist = tolerance, minDist
+	return r.context(opts).hits(r.Scores.Pix)
+}
+
+// Returns the hits in r's scores, as found with Options, at positions less
+// than Options.MinDist from every hit in known, or other than their
+// positions if MinDist is zero, so that further occurrences can be found
+// without repeating the search or reporting known hits again. Scores are
+// normalized as for the whole of r, so that the hits found score as they
+// did before.
+func (r *Result) HitsExcluding(known []Hit) []Hit {
+	ctx := r.context(r.Options)
+	if ctx.distanceScores() {
+		min, max := ctx.scoreRange(r.Scores.Pix)
+		if max <= min {
+			// all scores are equal
+			return nil
+		}
+		ctx.ScoreRange = &[2]float64{min, max}
+	}
+	// excluded positions score worst
+	worst := r.Max
+	if ctx.ScoreMode == SCOREMODE_CCOEFF_NORMED {
+		worst = r.Min
+	}
+	scores := append([]float64(nil), r.Scores.Pix...)
+	for i := range scores {
+		x, y := ctx.coords(i)
+		p := Hit{P: image.Point{x, y}}
+		for _, k := range known {
+			if k.P == p.P || k.Distance(p) < ctx.MinDist {
+				scores[i] = worst
+				break
+			}
+		}
+	}
+	return ctx.hits(scores)
+}
+
+// Returns the best hit of HitsExcluding(known), the next occurrence after
+// known, or false if there is none
+func (r *Result) Next(known []Hit) (Hit, bool) {
+	hits := r.HitsExcluding(known)
+	if len(hits) == 0 {
+		return Hit{}, false
+	}
+	return hits[0], true
+}
+
+// version of the encoding produced by Result.MarshalBinary
+const resultVersion = 1
+
+// the encoded form of a Result
+type resultState struct {
+	Version  int
+	Scores   *FloatImage
+	Min, Max float64
+	Hits     []Hit
+	Contrast *FloatImage
+	Options  optionsState
+}
+
+// Encodes r. Options.VerboseOut is not encoded.
+func (r *Result) MarshalBinary() ([]byte, error) {
+	var mask *image.Alpha
+	if r.Options.Mask != nil {
+		mask = toMask(r.Options.Mask, r.Options.Mask.Bounds())
+	}
+	st := resultState{
+		Version:  resultVersion,
+		Scores:   r.Scores,
+		Min:      r.Min,
+		Max:      r.Max,
+		Hits:     r.Hits,
+		Contrast: r.Contrast,
+		Options:  newOptionsState(r.Options, mask),
+	}
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(st); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Decodes a Result encoded by MarshalBinary into r
+func (r *Result) UnmarshalBinary(data []byte) error {
+	var st resultState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&st); err != nil {
+		return err
+	}
+	if st.Version != resultVersion {
+		return errors.New("unsupported result encoding version")
+	}
+	if st.Scores == nil || len(st.Scores.Pix) != st.Scores.Rect.Dx()*st.Scores.Rect.Dy() {
+		return errors.New("invalid result scores")
+	}
+	*r = Result{
+		Scores:   st.Scores,
+		Min:      st.Min,
+		Max:      st.Max,
+		Hits:     st.Hits,
+		Options:  st.Options.options(),
+		Contrast: st.Contrast,
+	}
+	return nil
+}
+
+// Writes r to w, as encoded by MarshalBinary
+func (r *Result) WriteTo(w io.Writer) (int64, error) {
+	data, err := r.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// Reads a Result written by WriteTo from rd
+func ReadResult(rd io.Reader) (*Result, error) {
+	data, err := io.ReadAll(rd)
+	if err != nil {
+		return nil, err
+	}
+	r := &Result{}
+	if err := r.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,75 @@
+package objsearch
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestResult(t *testing.T) {
+	object := randomRGBImage(10, 10)
+	field := frameWithObject(randomRGBImage(60, 50), object, image.Point{20, 30})
+	opts := Options{Tolerance: 0.1, MinDist: 5, ColorMode: COLORMODE_RGB, ColorKey: color.RGBA{255, 0, 255, 255}}
+	if SearchResult(object, field, image.Rectangle{}, opts) != nil {
+		t.Fatal("object larger than field should return nil")
+	}
+	rect := validRect(field.Bounds(), object.Bounds())
+	r := SearchResult(field, object, image.Rectangle{}, opts)
+	if !reflect.DeepEqual(r.Hits, SearchImage(field, object, rect, opts)) {
+		t.Fatal("result hits differ from SearchImage")
+	}
+	if len(r.Hits) != 1 || r.Hits[0].P != (image.Point{20, 30}) || r.Min != 0 {
+		t.Fatal("result error", r.Hits, r.Min)
+	}
+	b := &bytes.Buffer{}
+	if _, err := r.WriteTo(b); err != nil {
+		t.Fatal(err)
+	}
+	d, err := ReadResult(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(d.Scores, r.Scores) || !reflect.DeepEqual(d.Hits, r.Hits) || d.Max != r.Max || d.Options.ColorKey != (color.NRGBA{255, 0, 255, 255}) {
+		t.Fatal("decoded result differs")
+	}
+	loose := d.Rethreshold(0.5, 5)
+	opts.Tolerance = 0.5
+	if !reflect.DeepEqual(loose, SearchImage(field, object, rect, opts)) {
+		t.Fatal("rethresholded hits differ from SearchImage")
+	}
+	if err := d.UnmarshalBinary([]byte("junk")); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestResultNext(t *testing.T) {
+	object := randomRGBImage(10, 10)
+	field := randomRGBImage(80, 60)
+	for _, p := range []image.Point{{5, 5}, {40, 20}, {60, 45}} {
+		field = frameWithObject(field, object, p)
+	}
+	for _, opts := range []Options{
+		{Tolerance: 0.2, MinDist: 5},
+		{Tolerance: 0.9, MinDist: 5, ScoreMode: SCOREMODE_CCOEFF_NORMED},
+		{MinDist: 5, HitMode: HITMODE_BESTK, K: 1},
+	} {
+		r := SearchResult(field, object, image.Rectangle{}, opts)
+		var known []Hit
+		for len(known) < 3 {
+			h, ok := r.Next(known)
+			if !ok || h.S != r.Hits[0].S {
+				t.Fatal("next hit error", opts.ScoreMode, h, ok, known)
+			}
+			known = append(known, h)
+		}
+		SortRaster(known)
+		if known[0].P != (image.Point{5, 5}) || known[1].P != (image.Point{40, 20}) || known[2].P != (image.Point{60, 45}) {
+			t.Fatal("next hit error", known)
+		}
+		if h, ok := r.Next(known); opts.HitMode != HITMODE_BESTK && ok {
+			t.Fatal("hit found after all occurrences", h)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+)
+
+// Returns the mean absolute difference between the grayscale versions of
+// frames a and b, in [0,1]. Frames with different bounds have score 1.
+func ChangeScore(a, b image.Image) float64 {
+	if a.Bounds() != b.Bounds() {
+		return 1
+	}
+	return grayChange(NewField(a).planes(COLORMODE_GRAY)[0], NewField(b).planes(COLORMODE_GRAY)[0])
+}
+
+// return the mean absolute difference between a and b, which have equal
+// bounds
+func grayChange(a, b *FloatImage) float64 {
+	if a.Rect.Empty() {
+		return 0
+	}
+	sum := 0.0
+	for y := a.Rect.Min.Y; y < a.Rect.Max.Y; y++ {
+		for x := a.Rect.Min.X; x < a.Rect.Max.X; x++ {
+			sum += math.Abs(a.Pix[a.PixOffset(x, y)] - b.Pix[b.PixOffset(x, y)])
+		}
+	}
+	return sum / float64(a.Rect.Dx()*a.Rect.Dy())
+}
+
+// Detects substantial changes in a sequence of frames, so that expensive
+// searches can be skipped for frames that are effectively identical to the
+// last frame searched.
+type SceneChangeDetector struct {
+	// Frames whose ChangeScore against the reference frame exceeds Threshold
+	// are changed
+	Threshold float64
+	// grayscale reference frame
+	ref *FloatImage
+}
+
+// Returns a SceneChangeDetector with the given threshold
+func NewSceneChangeDetector(threshold float64) *SceneChangeDetector {
+	return &SceneChangeDetector{Threshold: threshold}
+}
+
+// Compares frame to the reference frame, which is the last frame found to
+// have changed. Returns whether frame has changed, and its change score. If
+// it has, frame becomes the new reference frame. The first frame has always
+// changed.
+func (d *SceneChangeDetector) Changed(frame image.Image) (bool, float64) {
+	gray := NewField(frame).planes(COLORMODE_GRAY)[0]
+	score := 1.0
+	if d.ref != nil && d.ref.Rect == gray.Rect {
+		score = grayChange(d.ref, gray)
+	}
+	if d.ref != nil && score <= d.Threshold {
+		return false, score
+	}
+	d.ref = gray
+	return true, score
+}
@@ -0,0 +1,24 @@
+package objsearch
+
+import (
+	"image"
+	"testing"
+)
+
+func TestSceneChange(t *testing.T) {
+	a := randomRGBImage(40, 40)
+	b := frameWithObject(a, randomRGBImage(2, 2), image.Point{5, 5})
+	c := randomRGBImage(40, 40)
+	if ChangeScore(a, a) != 0 || ChangeScore(a, randomRGBImage(10, 10)) != 1 {
+		t.Fatal("ChangeScore error")
+	}
+	d := NewSceneChangeDetector(0.05)
+	for i, f := range []struct {
+		frame   image.Image
+		changed bool
+	}{{a, true}, {a, false}, {b, false}, {c, true}, {c, false}} {
+		if changed, score := d.Changed(f.frame); changed != f.changed {
+			t.Fatal("scene change error", i, score)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package objsearch
+
+import (
+	"image"
+)
+
+// Searches fields for a fixed object. The object's mask and intermediate
+// planes are computed once and reused for every field searched.
+type Searcher struct {
+	Object  *Object
+	Options Options
+	// Top-left corners to search. If empty, every position at which the
+	// object lies entirely within the field is searched.
+	Rect image.Rectangle
+	// Objects known to be confused with Object, such as the disabled twin of
+	// an enabled button. A hit is dropped if the mean absolute per-pixel
+	// difference between a negative and the hit's window is more than
+	// NegativeMargin below that of Object. Negatives must have the same
+	// bounds as Object.
+	Negatives      []*Object
+	NegativeMargin float64
+}
+
+// Returns a Searcher for object with options opts
+func NewSearcher(object image.Image, opts Options) *Searcher {
+	return &Searcher{
+		Object:  NewObject(object),
+		Options: opts,
+	}
+}
+
+// Returns the rectangle of top-left corners searched in field
+func (s *Searcher) searchRect(field image.Image) image.Rectangle {
+	if !s.Rect.Empty() {
+		return s.Rect
+	}
+	return searchableRect(field.Bounds(), s.Object.Bounds(), s.Options.Border)
+}
+
+// Adds a negative object. See Searcher.Negatives.
+func (s *Searcher) AddNegative(object image.Image) {
+	o := NewObject(object)
+	if o.Bounds() != s.Object.Bounds() {
+		panic("negative and object have different bounds")
+	}
+	s.Negatives = append(s.Negatives, o)
+}
+
+// Searches field for the object, as SearchImage does. Returns nil if there
+// are no positions to search.
+func (s *Searcher) Search(field image.Image) []Hit {
+	return s.SearchWithProgress(field, nil)
+}
+
+// number of bands of rows searched by SearchWithProgress
+const progressBands = 64
+
+// Like Search, calling progress, if not nil, with the fraction of the field
+// searched as the search proceeds. The last call is with 1.
+func (s *Searcher) SearchWithProgress(field image.Image, progress func(float64)) []Hit {
+	rect, err := CheckRect(field.Bounds(), s.Object.Bounds(), s.searchRect(field), s.Options)
+	if err != nil {
+		panic(err)
+	}
+	if rect.Empty() {
+		return nil
+	}
+	if len(s.Negatives) == 0 && progress == nil {
+		return SearchImage(field, s.Object, rect, s.Options)
+	}
+	f := NewField(field)
+	ctx := newContext(rect, s.Options)
+	var scores []float64
+	if progress == nil {
+		ctx, scores = searchScores(f, s.Object, rect, s.Options)
+	} else {
+		// search bands of rows, in raster order, so the scores and
+		// contrasts concatenate
+		rows := (rect.Dy() + progressBands - 1) / progressBands
+		for y := rect.Min.Y; y < rect.Max.Y; y += rows {
+			band := image.Rect(rect.Min.X, y, rect.Max.X, y+rows).Intersect(rect)
+			bandCtx, d := searchScores(f, s.Object, band, s.Options)
+			scores = append(scores, d...)
+			ctx.Contrast = append(ctx.Contrast, bandCtx.Contrast...)
+			progress(float64(band.Max.Y-rect.Min.Y) / float64(rect.Dy()))
+		}
+	}
+	return FilterHits(ctx.hits(scores), func(h Hit) bool {
+		if len(s.Negatives) == 0 {
+			return true
+		}
+		d := distanceAt(f, s.Object, h.P, s.Options)
+		for _, n := range s.Negatives {
+			if distanceAt(f, n, h.P, s.Options) < d-s.NegativeMargin {
+				// the window looks more like the negative
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// return a search context for field and object, and the scores of object at
+// each point of rect according to opts.ScoreMode
+func searchScores(field, object image.Image, rect image.Rectangle, opts Options) (objSearchContext, []float64) {
+	ctx, fieldPlanes, objectPlanes := newImageContext(field, object, rect, opts)
+	if ctx.SearchRect.Empty() {
+		return ctx, nil
+	}
+	return ctx, ctx.scores(fieldPlanes, objectPlanes)
+}
+
+// return the combined distance between field and object at p
+func distanceAt(field, object image.Image, p image.Point, opts Options) float64 {
+	_, dist := searchDistances(field, object, image.Rectangle{p, p.Add(image.Point{1, 1})}, opts)
+	return dist[0]
+}
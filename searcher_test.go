@@ -0,0 +1,49 @@
+package objsearch
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestNegatives(t *testing.T) {
+	bg := randomRGBImage(60, 60)
+	enabled := randomRGBImage(10, 10)
+	// the disabled twin differs only in a few pixels
+	disabled := crop(enabled, enabled.Rect)
+	for i := 0; i < 10; i++ {
+		disabled.SetRGBA(i, 5, color.RGBA{128, 128, 128, 255})
+	}
+	field := frameWithObject(bg, enabled, image.Point{5, 5})
+	field = frameWithObject(field, disabled, image.Point{40, 40})
+	s := NewSearcher(enabled, Options{Tolerance: 0.3, MinDist: 10})
+	if h := s.Search(field); len(h) != 2 {
+		t.Fatal("expected disabled twin to match", h)
+	}
+	s.AddNegative(disabled)
+	if h := s.Search(field); len(h) != 1 || h[0].P != (image.Point{5, 5}) {
+		t.Fatal("negative template error", h)
+	}
+}
+
+func TestSearchWithProgress(t *testing.T) {
+	object := randomRGBImage(8, 8)
+	field := frameWithObject(randomRGBImage(200, 150), object, image.Point{120, 90})
+	s := NewSearcher(object, Options{Tolerance: 0.2, MinDist: 10})
+	var calls []float64
+	hits := s.SearchWithProgress(field, func(f float64) {
+		calls = append(calls, f)
+	})
+	if len(calls) < 2 || calls[len(calls)-1] != 1 {
+		t.Fatal("progress error", calls)
+	}
+	for i := 1; i < len(calls); i++ {
+		if calls[i] <= calls[i-1] {
+			t.Fatal("progress not increasing", calls)
+		}
+	}
+	if want := s.Search(field); !reflect.DeepEqual(hits, want) {
+		t.Fatal("hits differ from Search", hits, want)
+	}
+}
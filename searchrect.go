@@ -0,0 +1,53 @@
+package objsearch
+
+import (
+	"fmt"
+	"image"
+)
+
+// The error returned when a search rectangle holds positions at which the
+// object cannot be scored, such as when the object is larger than the field
+type RectError struct {
+	// The search rectangle given
+	Rect image.Rectangle
+	// The positions at which the object can be scored, as returned by
+	// SearchableRect. Empty if there are none.
+	Searchable image.Rectangle
+}
+
+func (e *RectError) Error() string {
+	if e.Searchable.Empty() {
+		return fmt.Sprintf("objsearch: object does not fit the field at any position in %v", e.Rect)
+	}
+	return fmt.Sprintf("objsearch: search rectangle %v is not within %v, where the object fits the field", e.Rect, e.Searchable)
+}
+
+// Returns the rectangle of top-left corners at which object can be scored
+// in field according to opts.Border: those at which it lies entirely within
+// field for BORDERMODE_NONE, and those at which it overlaps field
+// otherwise. Returns an empty rectangle if there are none.
+func SearchableRect(field, object image.Rectangle, opts Options) image.Rectangle {
+	return searchableRect(field, object, opts.Border)
+}
+
+// Returns the rectangle of top-left corners that the Search functions
+// search for object in field when given rect: the part of rect within
+// SearchableRect, which may be empty. If opts.StrictRect is set, a
+// *RectError is returned instead if rect is not within SearchableRect.
+func CheckRect(field, object, rect image.Rectangle, opts Options) (image.Rectangle, error) {
+	s := SearchableRect(field, object, opts)
+	if opts.StrictRect && !rect.In(s) {
+		return rect, &RectError{rect, s}
+	}
+	return rect.Intersect(s), nil
+}
+
+// Like SearchImage, returning the *RectError returned by CheckRect rather
+// than panicking if opts.StrictRect is set and rect holds positions at which
+// object cannot be scored
+func SearchImageChecked(field, object image.Image, rect image.Rectangle, opts Options) ([]Hit, error) {
+	if _, err := CheckRect(field.Bounds(), object.Bounds(), rect, opts); err != nil {
+		return nil, err
+	}
+	return SearchImage(field, object, rect, opts), nil
+}
@@ -0,0 +1,97 @@
+package objsearch
+
+import (
+	"errors"
+	"image"
+	"reflect"
+	"testing"
+)
+
+func TestCheckRect(t *testing.T) {
+	object := randomRGBImage(10, 10)
+	field := frameWithObject(randomRGBImage(60, 50), object, image.Point{40, 30})
+	opts := Options{Tolerance: 0.1, MinDist: 5}
+	// the field's bounds are clamped to the positions at which the object
+	// fits
+	hits := SearchImage(field, object, field.Rect, opts)
+	if !reflect.DeepEqual(hits, SearchImage(field, object, validRect(field.Rect, object.Rect), opts)) || len(hits) != 1 {
+		t.Fatal("clamped search error", hits)
+	}
+	if hits := SearchImage(object, field, object.Rect, opts); hits != nil {
+		t.Fatal("hits for an object larger than the field", hits)
+	}
+	if hits := SearchFloat(NewFloatImage(object.Rect), NewFloatImage(field.Rect), object.Rect, opts); hits != nil {
+		t.Fatal("hits for an object larger than the field", hits)
+	}
+	opts.StrictRect = true
+	var re *RectError
+	if _, err := SearchImageChecked(field, object, field.Rect, opts); !errors.As(err, &re) || re.Rect != field.Rect || re.Searchable != image.Rect(0, 0, 51, 41) {
+		t.Fatal("expected rect error", err)
+	}
+	if _, err := SearchImageChecked(object, field, object.Rect, opts); !errors.As(err, &re) || !re.Searchable.Empty() {
+		t.Fatal("expected rect error", err)
+	}
+	if hits, err := SearchImageChecked(field, object, image.Rect(0, 0, 51, 41), opts); err != nil || len(hits) != 1 {
+		t.Fatal("checked search error", hits, err)
+	}
+	// positions past the field's edges may be searched with a border mode
+	opts.Border = BORDERMODE_ZERO
+	if r, err := CheckRect(field.Rect, object.Rect, image.Rect(-9, -9, 60, 50), opts); err != nil || r != image.Rect(-9, -9, 60, 50) {
+		t.Fatal("rect check error", r, err)
+	}
+	if _, err := CheckRect(field.Rect, object.Rect, image.Rect(-10, 0, 60, 50), opts); err == nil {
+		t.Fatal("expected rect error")
+	}
+}
+
+func TestCheckRectEntryPoints(t *testing.T) {
+	object := randomRGBImage(10, 10)
+	field := frameWithObject(randomRGBImage(60, 50), object, image.Point{40, 30})
+	opts := Options{Tolerance: 0.1, MinDist: 5}
+	valid := validRect(field.Rect, object.Rect)
+	// an oversized rect is clamped by every entry point
+	hits := []Hit{{image.Point{40, 30}, 0}}
+	if h := SearchRGBD(field, NewFloatImage(field.Rect), object, NewFloatImage(object.Rect), field.Rect, opts); len(h) != 1 || h[0].P != hits[0].P {
+		t.Fatal("rgbd search error", h)
+	}
+	if h := SearchAny(field, []image.Image{object}, field.Rect, opts); len(h) != 1 || h[0].P != hits[0].P {
+		t.Fatal("alternatives search error", h)
+	}
+	if r := SearchRegions(field, object, field.Rect, opts); len(r) != 1 || r[0].P != hits[0].P {
+		t.Fatal("regions search error", r)
+	}
+	if d := DistanceMap(field, object, field.Rect, opts); d.Rect != valid || len(d.Pix) != valid.Dx()*valid.Dy() {
+		t.Fatal("distance map error", d.Rect)
+	}
+	if m := ChannelDistanceMaps(field, object, field.Rect, opts); len(m) != 1 || m[0].Rect != valid {
+		t.Fatal("channel distance maps error", len(m))
+	}
+	twoStage := TwoStageOptions{Options: Options{Tolerance: 0.1, MinDist: 5, ScoreMode: SCOREMODE_SQDIFF_NORMED}}
+	if h := SearchTwoStage(field, object, field.Rect, twoStage); len(h) != 1 || h[0].P != hits[0].P {
+		t.Fatal("two-stage search error", h)
+	}
+	s := NewSearcher(object, opts)
+	s.Rect = field.Rect
+	if h := s.SearchWithProgress(field, func(float64) {}); len(h) != 1 || h[0].P != hits[0].P {
+		t.Fatal("searcher error", h)
+	}
+	// and nothing is found if the object is larger than the field
+	if SearchRGBD(object, NewFloatImage(object.Rect), field, NewFloatImage(field.Rect), object.Rect, opts) != nil ||
+		SearchAny(object, []image.Image{field}, object.Rect, opts) != nil ||
+		SearchRegions(object, field, object.Rect, opts) != nil ||
+		SearchTwoStage(object, field, object.Rect, twoStage) != nil ||
+		NewSearcher(field, opts).SearchWithProgress(object, func(float64) {}) != nil {
+		t.Fatal("hits for an object larger than the field")
+	}
+	if d := DistanceMap(object, field, object.Rect, opts); !d.Rect.Empty() {
+		t.Fatal("distance map error", d.Rect)
+	}
+	// StrictRect applies to every entry point
+	opts.StrictRect = true
+	defer func() {
+		if _, ok := recover().(*RectError); !ok {
+			t.Fatal("expected rect error")
+		}
+	}()
+	DistanceMap(field, object, field.Rect, opts)
+}
@@ -0,0 +1,321 @@
+package objsearch
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"image"
+	"io"
+	"strconv"
+	"time"
+)
+
+// the JSON representation of a Hit
+type jsonHit struct {
+	X     int     `json:"x"`
+	Y     int     `json:"y"`
+	Score float64 `json:"score"`
+}
+
+func newJSONHit(h Hit) jsonHit {
+	return jsonHit{h.P.X, h.P.Y, h.S}
+}
+
+func (j jsonHit) hit() Hit {
+	return Hit{image.Point{j.X, j.Y}, j.Score}
+}
+
+// Encodes h as {"x","y","score"}
+func (h Hit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONHit(h))
+}
+
+func (h *Hit) UnmarshalJSON(b []byte) error {
+	j := jsonHit{}
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*h = j.hit()
+	return nil
+}
+
+// the JSON representation of a LabeledHit
+type jsonLabeledHit struct {
+	Label string `json:"label"`
+	jsonHit
+}
+
+// Encodes h as {"label","x","y","score"}
+func (h LabeledHit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonLabeledHit{h.Label, newJSONHit(h.Hit)})
+}
+
+func (h *LabeledHit) UnmarshalJSON(b []byte) error {
+	j := jsonLabeledHit{}
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*h = LabeledHit{j.hit(), j.Label}
+	return nil
+}
+
+// the JSON representation of a FrameHit
+type jsonFrameHit struct {
+	Frame int `json:"frame"`
+	// Time in nanoseconds
+	Time int64 `json:"time_ns"`
+	jsonHit
+}
+
+// Encodes h as {"frame","time_ns","x","y","score"}
+func (h FrameHit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonFrameHit{h.Frame, int64(h.Time), newJSONHit(h.Hit)})
+}
+
+func (h *FrameHit) UnmarshalJSON(b []byte) error {
+	j := jsonFrameHit{}
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*h = FrameHit{j.hit(), j.Frame, time.Duration(j.Time)}
+	return nil
+}
+
+// the JSON representation of an AlternativeHit
+type jsonAlternativeHit struct {
+	Index int `json:"index"`
+	jsonHit
+}
+
+// Encodes h as {"index","x","y","score"}
+func (h AlternativeHit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonAlternativeHit{h.Index, newJSONHit(h.Hit)})
+}
+
+func (h *AlternativeHit) UnmarshalJSON(b []byte) error {
+	j := jsonAlternativeHit{}
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*h = AlternativeHit{j.hit(), j.Index}
+	return nil
+}
+
+// the JSON representation of a ConsensusHit
+type jsonConsensusHit struct {
+	Count  int `json:"count"`
+	Streak int `json:"streak"`
+	jsonHit
+}
+
+// Encodes h as {"count","streak","x","y","score"}
+func (h ConsensusHit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonConsensusHit{h.Count, h.Streak, newJSONHit(h.Hit)})
+}
+
+func (h *ConsensusHit) UnmarshalJSON(b []byte) error {
+	j := jsonConsensusHit{}
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*h = ConsensusHit{j.hit(), j.Count, j.Streak}
+	return nil
+}
+
+// the JSON representation of a RegionHit
+type jsonRegionHit struct {
+	jsonHit
+	MinX      int     `json:"min_x"`
+	MinY      int     `json:"min_y"`
+	MaxX      int     `json:"max_x"`
+	MaxY      int     `json:"max_y"`
+	Area      int     `json:"area"`
+	CentroidX float64 `json:"centroid_x"`
+	CentroidY float64 `json:"centroid_y"`
+}
+
+// Encodes h as {"x","y","score","min_x","min_y","max_x","max_y","area",
+// "centroid_x","centroid_y"}
+func (h RegionHit) MarshalJSON() ([]byte, error) {
+	b := h.Bounds
+	return json.Marshal(jsonRegionHit{newJSONHit(h.Hit), b.Min.X, b.Min.Y, b.Max.X, b.Max.Y, h.Area, h.CentroidX, h.CentroidY})
+}
+
+func (h *RegionHit) UnmarshalJSON(b []byte) error {
+	j := jsonRegionHit{}
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*h = RegionHit{j.hit(), image.Rect(j.MinX, j.MinY, j.MaxX, j.MaxY), j.Area, j.CentroidX, j.CentroidY}
+	return nil
+}
+
+// the JSON representation of a MeasuredHit
+type jsonMeasuredHit struct {
+	jsonHit
+	MeanDiff float64 `json:"mean_diff"`
+}
+
+// Encodes h as {"x","y","score","mean_diff"}
+func (h MeasuredHit) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonMeasuredHit{newJSONHit(h.Hit), h.MeanDiff})
+}
+
+func (h *MeasuredHit) UnmarshalJSON(b []byte) error {
+	j := jsonMeasuredHit{}
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*h = MeasuredHit{j.hit(), j.MeanDiff}
+	return nil
+}
+
+// the JSON representation of a PointSource
+type jsonPointSource struct {
+	jsonHit
+	CentroidX  float64 `json:"centroid_x"`
+	CentroidY  float64 `json:"centroid_y"`
+	Background float64 `json:"background"`
+	Flux       float64 `json:"flux"`
+}
+
+// Encodes p as {"x","y","score","centroid_x","centroid_y","background",
+// "flux"}
+func (p PointSource) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonPointSource{newJSONHit(p.Hit), p.X, p.Y, p.Background, p.Flux})
+}
+
+func (p *PointSource) UnmarshalJSON(b []byte) error {
+	j := jsonPointSource{}
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*p = PointSource{j.hit(), j.CentroidX, j.CentroidY, j.Background, j.Flux}
+	return nil
+}
+
+// the JSON representation of a FileHits
+type jsonFileHits struct {
+	Path  string    `json:"path"`
+	Hits  []jsonHit `json:"hits"`
+	Error string    `json:"error,omitempty"`
+}
+
+// Encodes h as {"path","hits","error"}, where "hits" is an array of
+// {"x","y","score"} objects, and "error" is omitted if h.Err is nil
+func (h FileHits) MarshalJSON() ([]byte, error) {
+	j := jsonFileHits{Path: h.Path, Hits: []jsonHit{}}
+	for _, hit := range h.Hits {
+		j.Hits = append(j.Hits, newJSONHit(hit))
+	}
+	if h.Err != nil {
+		j.Error = h.Err.Error()
+	}
+	return json.Marshal(j)
+}
+
+func (h *FileHits) UnmarshalJSON(b []byte) error {
+	j := jsonFileHits{}
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*h = FileHits{Path: j.Path}
+	for _, hit := range j.Hits {
+		h.Hits = append(h.Hits, hit.hit())
+	}
+	if j.Error != "" {
+		h.Err = errors.New(j.Error)
+	}
+	return nil
+}
+
+// Writes hits to w as CSV, with a header row "x,y,score"
+func WriteHitsCSV(w io.Writer, hits []Hit) error {
+	c := csv.NewWriter(w)
+	c.Write([]string{"x", "y", "score"})
+	for _, h := range hits {
+		c.Write(hitRecord(h))
+	}
+	c.Flush()
+	return c.Error()
+}
+
+// Reads hits written by WriteHitsCSV from r
+func ReadHitsCSV(r io.Reader) (hits []Hit, err error) {
+	err = readCSV(r, []string{"x", "y", "score"}, func(rec []string) error {
+		h, err := parseHitRecord(rec)
+		hits = append(hits, h)
+		return err
+	})
+	return
+}
+
+// Writes hits to w as CSV, with a header row "label,x,y,score"
+func WriteLabeledHitsCSV(w io.Writer, hits []LabeledHit) error {
+	c := csv.NewWriter(w)
+	c.Write([]string{"label", "x", "y", "score"})
+	for _, h := range hits {
+		c.Write(append([]string{h.Label}, hitRecord(h.Hit)...))
+	}
+	c.Flush()
+	return c.Error()
+}
+
+// Reads hits written by WriteLabeledHitsCSV from r
+func ReadLabeledHitsCSV(r io.Reader) (hits []LabeledHit, err error) {
+	err = readCSV(r, []string{"label", "x", "y", "score"}, func(rec []string) error {
+		h, err := parseHitRecord(rec[1:])
+		hits = append(hits, LabeledHit{h, rec[0]})
+		return err
+	})
+	return
+}
+
+// return the CSV fields x, y and score of h
+func hitRecord(h Hit) []string {
+	return []string{
+		strconv.Itoa(h.P.X),
+		strconv.Itoa(h.P.Y),
+		strconv.FormatFloat(h.S, 'g', -1, 64),
+	}
+}
+
+// parse the CSV fields x, y and score
+func parseHitRecord(rec []string) (h Hit, err error) {
+	if h.P.X, err = strconv.Atoi(rec[0]); err != nil {
+		return
+	}
+	if h.P.Y, err = strconv.Atoi(rec[1]); err != nil {
+		return
+	}
+	h.S, err = strconv.ParseFloat(rec[2], 64)
+	return
+}
+
+// read CSV records from r, checking that the header row equals header and
+// calling fn with each following record
+func readCSV(r io.Reader, header []string, fn func([]string) error) error {
+	c := csv.NewReader(r)
+	c.FieldsPerRecord = len(header)
+	rec, err := c.Read()
+	if err != nil {
+		return err
+	}
+	for i := range header {
+		if rec[i] != header[i] {
+			return errors.New("unexpected CSV header")
+		}
+	}
+	for {
+		rec, err := c.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+}
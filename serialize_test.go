@@ -0,0 +1,72 @@
+package objsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHitJSON(t *testing.T) {
+	b, err := json.Marshal([]Hit{{image.Point{1, 2}, 0.5}})
+	if err != nil || string(b) != `[{"x":1,"y":2,"score":0.5}]` {
+		t.Fatal("hit encoding error", string(b), err)
+	}
+	b, err = json.Marshal(LabeledHit{Hit{image.Point{3, 4}, 0.25}, "ok"})
+	if err != nil || string(b) != `{"label":"ok","x":3,"y":4,"score":0.25}` {
+		t.Fatal("labeled hit encoding error", string(b), err)
+	}
+	roundTrip := func(in, out interface{}) {
+		b, err := json.Marshal(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(b, out); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(in, reflect.ValueOf(out).Elem().Interface()) {
+			t.Fatal("round trip error", in, string(b))
+		}
+	}
+	h := Hit{image.Point{5, 6}, 0.125}
+	roundTrip(h, &Hit{})
+	roundTrip(LabeledHit{h, "button"}, &LabeledHit{})
+	roundTrip(FrameHit{h, 3, 250 * time.Millisecond}, &FrameHit{})
+	roundTrip(AlternativeHit{h, 2}, &AlternativeHit{})
+	roundTrip(ConsensusHit{h, 4, 3}, &ConsensusHit{})
+	roundTrip(PointSource{h, 7.5, 8.25, 0.5, 12}, &PointSource{})
+	b, err = json.Marshal(PointSource{h, 7.5, 8.25, 0.5, 12})
+	if err != nil || string(b) != `{"x":5,"y":6,"score":0.125,"centroid_x":7.5,"centroid_y":8.25,"background":0.5,"flux":12}` {
+		t.Fatal("point source encoding error", string(b), err)
+	}
+}
+
+func TestHitCSV(t *testing.T) {
+	hits := []Hit{{image.Point{1, 2}, 0.5}, {image.Point{-3, 4}, 1e-9}}
+	b := &bytes.Buffer{}
+	if err := WriteHitsCSV(b, hits); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "x,y,score\n1,2,0.5\n-3,4,1e-09\n" {
+		t.Fatal("CSV encoding error", b.String())
+	}
+	if h, err := ReadHitsCSV(b); err != nil || !reflect.DeepEqual(h, hits) {
+		t.Fatal("CSV decoding error", h, err)
+	}
+	labeled := []LabeledHit{{hits[0], "a,b"}, {hits[1], "c"}}
+	b.Reset()
+	if err := WriteLabeledHitsCSV(b, labeled); err != nil {
+		t.Fatal(err)
+	}
+	if h, err := ReadLabeledHitsCSV(b); err != nil || !reflect.DeepEqual(h, labeled) {
+		t.Fatal("labeled CSV decoding error", h, err)
+	}
+	if _, err := ReadHitsCSV(bytes.NewBufferString("label,x,y,score\n")); err == nil {
+		t.Fatal("expected header error")
+	}
+	if _, err := ReadHitsCSV(bytes.NewBufferString("x,y,score\n1,a,0\n")); err == nil {
+		t.Fatal("expected parse error")
+	}
+}
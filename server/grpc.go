@@ -0,0 +1,73 @@
+//go:build grpc
+
+package server
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hypoactiv/objsearch/objsearchpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// the ObjSearch service of objsearch.proto
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: "objsearch.ObjSearch",
+	HandlerType: (*interface{})(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Search",
+			Handler:       searchHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "objsearch.proto",
+}
+
+func searchHandler(srv interface{}, stream grpc.ServerStream) error {
+	req := &objsearchpb.SearchRequest{}
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	err := srv.(*Server).Search(stream.Context(), req, func(u *objsearchpb.SearchUpdate) error {
+		return stream.SendMsg(u)
+	})
+	if errors.Is(err, ErrInvalidRequest) || errors.Is(err, ErrDuplicateLabel) || errors.Is(err, ErrSearchFailed) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return err
+}
+
+// Returns a gRPC server serving the ObjSearch service with s. opts are
+// passed to grpc.NewServer.
+func NewGRPCServer(s *Server, opts ...grpc.ServerOption) *grpc.Server {
+	g := grpc.NewServer(append(opts, grpc.ForceServerCodec(wireCodec{}))...)
+	g.RegisterService(&serviceDesc, s)
+	return g
+}
+
+// a gRPC codec for objsearchpb's messages, which encode themselves in the
+// protobuf wire format
+type wireCodec struct{}
+
+func (wireCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(interface{ Marshal() []byte })
+	if !ok {
+		return nil, fmt.Errorf("server: cannot marshal %T", v)
+	}
+	return m.Marshal(), nil
+}
+
+func (wireCodec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(interface{ Unmarshal([]byte) error })
+	if !ok {
+		return fmt.Errorf("server: cannot unmarshal %T", v)
+	}
+	return m.Unmarshal(data)
+}
+
+func (wireCodec) Name() string {
+	return "proto"
+}
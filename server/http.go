@@ -0,0 +1,158 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/hypoactiv/objsearch"
+)
+
+// Maximum size of a request's uploaded images held in memory; larger uploads
+// are stored in temporary files
+const maxMemory = 32 << 20
+
+// Returns an http.Handler that performs searches posted as
+// multipart/form-data, with the following parts:
+//
+//	field      the field image (PNG, JPEG or GIF)
+//	template   an object image; may be repeated. Hits are labeled with the
+//	           file name of the template part, which must be unique.
+//	tolerance  Options.Tolerance (optional)
+//	mindist    Options.MinDist (optional)
+//	color      "gray" or "rgb" (optional)
+//	combine    "max" or "mean" (optional)
+//	format     "json" (default) for a JSON array of hits, or "png" for the
+//	           field annotated with the hits
+//
+// Searches count against s's concurrency limit.
+func (s *Server) HTTPHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err := r.ParseMultipartForm(maxMemory); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+		field, set, err := parseForm(r.MultipartForm)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.acquire(r.Context()); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		defer s.release()
+		hits, err := searchAll(field, set)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		switch r.FormValue("format") {
+		case "", "json":
+			if hits == nil {
+				hits = []objsearch.LabeledHit{}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(hits)
+		case "png":
+			w.Header().Set("Content-Type", "image/png")
+			png.Encode(w, objsearch.DrawLabeledHits(field, hits, set, objsearch.HitStyle{Score: true}))
+		default:
+			http.Error(w, "unknown format", http.StatusBadRequest)
+		}
+	})
+}
+
+// return set.SearchAll(field), or ErrSearchFailed if the search panics
+func searchAll(field *objsearch.Field, set *objsearch.TemplateSet) (hits []objsearch.LabeledHit, err error) {
+	defer recoverSearch(&err)
+	return set.SearchAll(field), nil
+}
+
+// return the field and templates of a search form, with the options given
+func parseForm(form *multipart.Form) (*objsearch.Field, *objsearch.TemplateSet, error) {
+	opts := objsearch.Options{}
+	value := func(name string) string {
+		if v := form.Value[name]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	var err error
+	if v := value("tolerance"); v != "" {
+		if opts.Tolerance, err = strconv.ParseFloat(v, 64); err != nil {
+			return nil, nil, fmt.Errorf("invalid tolerance: %v", err)
+		}
+	}
+	if v := value("mindist"); v != "" {
+		if opts.MinDist, err = strconv.Atoi(v); err != nil {
+			return nil, nil, fmt.Errorf("invalid mindist: %v", err)
+		}
+	}
+	if err := parseModes(&opts, value("color"), value("combine")); err != nil {
+		return nil, nil, err
+	}
+	if len(form.File["field"]) != 1 {
+		return nil, nil, fmt.Errorf("expected one field image")
+	}
+	f, err := form.File["field"][0].Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	field, err := objsearch.LoadField(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("field: %v", err)
+	}
+	set := objsearch.NewTemplateSet(opts)
+	for _, h := range form.File["template"] {
+		if set.Searcher(h.Filename) != nil {
+			return nil, nil, ErrDuplicateLabel
+		}
+		f, err := h.Open()
+		if err != nil {
+			return nil, nil, err
+		}
+		object, err := objsearch.LoadObject(f)
+		f.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("template %s: %v", h.Filename, err)
+		}
+		set.Add(h.Filename, object)
+	}
+	if len(set.Labels()) == 0 {
+		return nil, nil, fmt.Errorf("no template images")
+	}
+	return field, set, nil
+}
+
+// set the color and combine modes of opts from their names. Empty names
+// select the defaults.
+func parseModes(opts *objsearch.Options, color, combine string) error {
+	switch color {
+	case "", "gray":
+		opts.ColorMode = objsearch.COLORMODE_GRAY
+	case "rgb":
+		opts.ColorMode = objsearch.COLORMODE_RGB
+	default:
+		return fmt.Errorf("unknown color mode %q", color)
+	}
+	switch combine {
+	case "", "max":
+		opts.CombineMode = objsearch.COMBINEMODE_MAX
+	case "mean":
+		opts.CombineMode = objsearch.COMBINEMODE_MEAN
+	default:
+		return fmt.Errorf("unknown combine mode %q", combine)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hypoactiv/objsearch"
+	"github.com/hypoactiv/objsearch/objsearchpb"
+)
+
+// return a multipart search form for the field and templates of req, with
+// the given extra values
+func testForm(t *testing.T, req *objsearchpb.SearchRequest, values map[string]string) (*bytes.Buffer, string) {
+	b := &bytes.Buffer{}
+	m := multipart.NewWriter(b)
+	w, _ := m.CreateFormFile("field", "field.png")
+	w.Write(req.Field)
+	for _, tm := range req.Templates {
+		w, _ := m.CreateFormFile("template", tm.Label+".png")
+		w.Write(tm.Image)
+	}
+	for k, v := range values {
+		m.WriteField(k, v)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b, m.FormDataContentType()
+}
+
+func TestHTTPHandler(t *testing.T) {
+	srv := httptest.NewServer(NewServer(2).HTTPHandler())
+	defer srv.Close()
+	body, ctype := testForm(t, testRequest(), map[string]string{"tolerance": "0.01", "mindist": "5", "color": "rgb"})
+	resp, err := http.Post(srv.URL, ctype, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hits []objsearch.LabeledHit
+	err = json.NewDecoder(resp.Body).Decode(&hits)
+	resp.Body.Close()
+	if err != nil || resp.StatusCode != http.StatusOK || len(hits) != 2 {
+		t.Fatal("JSON response error", resp.Status, hits, err)
+	}
+	if hits[0].Label != "a.png" && hits[1].Label != "a.png" {
+		t.Fatal("hits not labeled with file names", hits)
+	}
+	body, ctype = testForm(t, testRequest(), map[string]string{"format": "png"})
+	resp, err = http.Post(srv.URL, ctype, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = png.Decode(resp.Body)
+	resp.Body.Close()
+	if err != nil || resp.Header.Get("Content-Type") != "image/png" {
+		t.Fatal("PNG response error", err)
+	}
+	body, ctype = testForm(t, testRequest(), map[string]string{"color": "cmyk"})
+	if resp, err := http.Post(srv.URL, ctype, body); err != nil || resp.StatusCode != http.StatusBadRequest {
+		t.Fatal("expected bad request", err)
+	}
+	if resp, err := http.Get(srv.URL); err != nil || resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatal("expected method not allowed", err)
+	}
+}
+
+func TestHTTPHandlerPanic(t *testing.T) {
+	// a single slot, which a panicking search must not leak
+	srv := httptest.NewServer(NewServer(1).HTTPHandler())
+	defer srv.Close()
+	body, ctype := testForm(t, degenerateRequest(), nil)
+	resp, err := http.Post(srv.URL, ctype, body)
+	if err != nil || resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatal("expected unprocessable entity", resp, err)
+	}
+	resp.Body.Close()
+	body, ctype = testForm(t, testRequest(), nil)
+	resp, err = http.Post(srv.URL, ctype, body)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatal("search after panic failed", resp, err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,135 @@
+// Package server serves searches to remote clients over gRPC, HTTP or
+// WebSockets, so that matching can be centralized on one machine and
+// requested by lightweight agents, web UIs, or clients not written in Go.
+//
+// gRPC requests and results are the messages of package objsearchpb. The
+// gRPC binding, which depends on google.golang.org/grpc, is built only with
+// the grpc build tag.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hypoactiv/objsearch"
+	"github.com/hypoactiv/objsearch/objsearchpb"
+)
+
+// Returned when a request's template labels are not unique
+var ErrDuplicateLabel = errors.New("server: duplicate template label")
+
+// Returned when a request's field or templates cannot be decoded
+var ErrInvalidRequest = errors.New("server: invalid request")
+
+// Returned when a search panics, as it does for some degenerate fields and
+// templates, such as a uniform template on a uniform field
+var ErrSearchFailed = errors.New("server: search failed")
+
+// Performs searches, limiting how many run at once
+type Server struct {
+	// slots for running searches
+	sem chan struct{}
+}
+
+// Returns a Server that runs at most maxConcurrent searches at once. Further
+// requests wait for a search to finish.
+func NewServer(maxConcurrent int) *Server {
+	if maxConcurrent <= 0 {
+		panic("maxConcurrent <= 0")
+	}
+	return &Server{sem: make(chan struct{}, maxConcurrent)}
+}
+
+// Performs the search described by req, calling send with updates as it
+// proceeds. Updates carrying only progress are sent while each template is
+// searched, and an update carrying its hits when its search completes, in
+// the order of req.Templates. The final update has progress 1. Returns ctx's
+// error if ctx is done before the search completes, or the first error
+// returned by send. Returns ErrInvalidRequest if req cannot be decoded, and
+// ErrSearchFailed if the search panics.
+func (s *Server) Search(ctx context.Context, req *objsearchpb.SearchRequest, send func(*objsearchpb.SearchUpdate) error) (err error) {
+	if err := s.acquire(ctx); err != nil {
+		return err
+	}
+	defer s.release()
+	defer recoverSearch(&err)
+	field, set, err := req.Decode()
+	if errors.Is(err, objsearchpb.ErrDuplicateLabel) {
+		return ErrDuplicateLabel
+	} else if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	return search(ctx, field, set, func(progress float64, hits []objsearch.LabeledHit) error {
+		u := &objsearchpb.SearchUpdate{Progress: progress}
+		for _, h := range hits {
+			u.Hits = append(u.Hits, objsearchpb.FromLabeledHit(h))
+		}
+		return send(u)
+	})
+}
+
+// run search holding a search slot, returning ErrSearchFailed if it panics
+func (s *Server) search(ctx context.Context, field *objsearch.Field, set *objsearch.TemplateSet, send func(float64, []objsearch.LabeledHit) error) (err error) {
+	if err := s.acquire(ctx); err != nil {
+		return err
+	}
+	defer s.release()
+	defer recoverSearch(&err)
+	return search(ctx, field, set, send)
+}
+
+// search field for the objects of set, in registration order, calling send
+// with the overall progress as each band of the field is searched, and with
+// the hits of each object when its search completes
+func search(ctx context.Context, field *objsearch.Field, set *objsearch.TemplateSet, send func(float64, []objsearch.LabeledHit) error) error {
+	labels := set.Labels()
+	if len(labels) == 0 {
+		return send(1, nil)
+	}
+	for i, label := range labels {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var sendErr error
+		progress := func(f float64) {
+			if sendErr == nil && f < 1 {
+				sendErr = send((float64(i)+f)/float64(len(labels)), nil)
+			}
+		}
+		var hits []objsearch.LabeledHit
+		for _, h := range set.Searcher(label).SearchWithProgress(field, progress) {
+			hits = append(hits, objsearch.LabeledHit{Hit: h, Label: label})
+		}
+		if sendErr != nil {
+			return sendErr
+		}
+		if err := send(float64(i+1)/float64(len(labels)), hits); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wait for a search slot, or until ctx is done
+func (s *Server) acquire(ctx context.Context) error {
+	select {
+	case s.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// free a search slot taken by acquire
+func (s *Server) release() {
+	<-s.sem
+}
+
+// recover a panicking search into *err, wrapping ErrSearchFailed. Must be
+// deferred.
+func recoverSearch(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("%w: %v", ErrSearchFailed, r)
+	}
+}
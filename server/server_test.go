@@ -0,0 +1,130 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/draw"
+	"image/png"
+	"math/rand"
+	"runtime"
+	"testing"
+
+	"github.com/hypoactiv/objsearch"
+	"github.com/hypoactiv/objsearch/objsearchpb"
+)
+
+func encodePNG(img image.Image) []byte {
+	b := &bytes.Buffer{}
+	png.Encode(b, img)
+	return b.Bytes()
+}
+
+func testRequest() *objsearchpb.SearchRequest {
+	field := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	for i := range field.Pix {
+		field.Pix[i] = uint8(rand.Intn(256))
+		if i%4 == 3 {
+			field.Pix[i] = 255
+		}
+	}
+	crop := func(p image.Point) []byte {
+		c := image.NewRGBA(image.Rect(0, 0, 6, 6))
+		draw.Draw(c, c.Rect, field, p, draw.Src)
+		return encodePNG(c)
+	}
+	return &objsearchpb.SearchRequest{
+		Field: encodePNG(field),
+		Templates: []*objsearchpb.Template{
+			{Label: "a", Image: crop(image.Point{3, 4})},
+			{Label: "b", Image: crop(image.Point{20, 25})},
+		},
+		Options: objsearchpb.FromOptions(objsearch.Options{Tolerance: 0.01, MinDist: 5}),
+	}
+}
+
+// return a request whose search panics: a uniform template on a uniform
+// field
+func degenerateRequest() *objsearchpb.SearchRequest {
+	uniform := func(size int) []byte {
+		img := image.NewRGBA(image.Rect(0, 0, size, size))
+		for i := range img.Pix {
+			img.Pix[i] = 255
+		}
+		return encodePNG(img)
+	}
+	return &objsearchpb.SearchRequest{
+		Field:     uniform(20),
+		Templates: []*objsearchpb.Template{{Label: "a", Image: uniform(4)}},
+	}
+}
+
+func TestSearch(t *testing.T) {
+	s := NewServer(1)
+	var updates []*objsearchpb.SearchUpdate
+	err := s.Search(context.Background(), testRequest(), func(u *objsearchpb.SearchUpdate) error {
+		updates = append(updates, u)
+		return nil
+	})
+	if err != nil || len(updates) < 3 {
+		t.Fatal("search error", updates, err)
+	}
+	var hits []*objsearchpb.Hit
+	for i, u := range updates {
+		if i > 0 && u.Progress <= updates[i-1].Progress {
+			t.Fatal("progress not increasing")
+		}
+		if len(u.Hits) != 0 && u.Progress != 0.5 && u.Progress != 1 {
+			t.Fatal("hits sent before their search completed", u)
+		}
+		hits = append(hits, u.Hits...)
+	}
+	if updates[len(updates)-1].Progress != 1 || len(hits) != 2 {
+		t.Fatal("updates error", hits)
+	}
+	if hits[0].Label != "a" || hits[0].X != 3 || hits[1].Label != "b" || hits[1].Y != 25 {
+		t.Fatal("hits error", hits)
+	}
+}
+
+func TestSearchPanic(t *testing.T) {
+	s := NewServer(1)
+	nop := func(*objsearchpb.SearchUpdate) error { return nil }
+	if err := s.Search(context.Background(), degenerateRequest(), nop); !errors.Is(err, ErrSearchFailed) {
+		t.Fatal("expected ErrSearchFailed", err)
+	}
+	req := testRequest()
+	req.Field = []byte("not an image")
+	if err := s.Search(context.Background(), req, nop); !errors.Is(err, ErrInvalidRequest) {
+		t.Fatal("expected ErrInvalidRequest", err)
+	}
+	if err := s.Search(context.Background(), testRequest(), nop); err != nil {
+		t.Fatal("search after panic failed", err)
+	}
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	s := NewServer(1)
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- s.Search(context.Background(), testRequest(), func(*objsearchpb.SearchUpdate) error {
+			<-release
+			return nil
+		})
+	}()
+	// wait for the first search to take the only slot
+	for len(s.sem) == 0 {
+		runtime.Gosched()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.Search(ctx, testRequest(), nil); err != context.Canceled {
+		t.Fatal("expected second search to wait", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
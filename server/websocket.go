@@ -0,0 +1,287 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/hypoactiv/objsearch"
+)
+
+// A search request sent over a WebSocket
+type wsRequest struct {
+	// PNG, JPEG or GIF encoded images, as base64 strings
+	Field     []byte `json:"field"`
+	Templates []struct {
+		Label string `json:"label"`
+		Image []byte `json:"image"`
+	} `json:"templates"`
+	Options struct {
+		Tolerance float64 `json:"tolerance"`
+		MinDist   int     `json:"mindist"`
+		Color     string  `json:"color"`
+		Combine   string  `json:"combine"`
+	} `json:"options"`
+}
+
+// A message sent over a WebSocket
+type wsUpdate struct {
+	Progress float64                `json:"progress"`
+	Hits     []objsearch.LabeledHit `json:"hits,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// Returns an http.Handler that performs searches over WebSockets, so that
+// web UIs can show the progress of long searches.
+//
+// After connecting, the client sends one text message, a JSON object:
+//
+//	{
+//	  "field": "<base64 PNG, JPEG or GIF>",
+//	  "templates": [{"label": "button", "image": "<base64 image>"}, ...],
+//	  "options": {"tolerance": 0.1, "mindist": 10, "color": "rgb", "combine": "max"}
+//	}
+//
+// The server replies with text messages {"progress": 0.25, "hits": [...]},
+// where progress is the fraction of the search completed and hits, if any,
+// are those found for a template since the previous message. The last
+// message has progress 1, or is {"error": "..."} if the search failed, and
+// the server then closes the connection.
+//
+// Searches count against s's concurrency limit.
+func (s *Server) WebSocketHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := wsUpgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.close()
+		send := func(u wsUpdate) error {
+			b, _ := json.Marshal(u)
+			return conn.write(wsText, b)
+		}
+		msg, err := conn.read()
+		if err != nil {
+			return
+		}
+		field, set, err := parseWSRequest(msg)
+		if err == nil {
+			err = s.search(r.Context(), field, set, func(progress float64, hits []objsearch.LabeledHit) error {
+				return send(wsUpdate{Progress: progress, Hits: hits})
+			})
+		}
+		if err != nil {
+			send(wsUpdate{Error: err.Error()})
+		}
+	})
+}
+
+// return the field and templates of a JSON search request
+func parseWSRequest(msg []byte) (*objsearch.Field, *objsearch.TemplateSet, error) {
+	req := wsRequest{}
+	if err := json.Unmarshal(msg, &req); err != nil {
+		return nil, nil, err
+	}
+	opts := objsearch.Options{Tolerance: req.Options.Tolerance, MinDist: req.Options.MinDist}
+	if err := parseModes(&opts, req.Options.Color, req.Options.Combine); err != nil {
+		return nil, nil, err
+	}
+	field, err := objsearch.LoadField(bytes.NewReader(req.Field))
+	if err != nil {
+		return nil, nil, err
+	}
+	set := objsearch.NewTemplateSet(opts)
+	for _, t := range req.Templates {
+		if set.Searcher(t.Label) != nil {
+			return nil, nil, ErrDuplicateLabel
+		}
+		object, err := objsearch.LoadObject(bytes.NewReader(t.Image))
+		if err != nil {
+			return nil, nil, err
+		}
+		set.Add(t.Label, object)
+	}
+	return field, set, nil
+}
+
+////
+// A minimal server side of the WebSocket protocol (RFC 6455)
+
+// WebSocket opcodes
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xa
+)
+
+// appended to the client's key to compute Sec-WebSocket-Accept
+const wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+var errWSTooLarge = errors.New("server: WebSocket message too large")
+
+// a WebSocket connection
+type wsConn struct {
+	rw     *bufio.ReadWriter
+	closer io.Closer
+}
+
+// return the Sec-WebSocket-Accept value for key
+func wsAccept(key string) string {
+	h := sha1.Sum([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// perform the server's opening handshake, and return the connection. If the
+// request is not a valid WebSocket handshake, an error response is written.
+func wsUpgrade(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if r.Method != http.MethodGet ||
+		!strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		!strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade") ||
+		r.Header.Get("Sec-WebSocket-Version") != "13" || key == "" {
+		http.Error(w, "expected WebSocket handshake", http.StatusBadRequest)
+		return nil, errors.New("server: invalid WebSocket handshake")
+	}
+	h, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "WebSockets not supported", http.StatusInternalServerError)
+		return nil, errors.New("server: connection cannot be hijacked")
+	}
+	nc, rw, err := h.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + wsAccept(key) + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		nc.Close()
+		return nil, err
+	}
+	return &wsConn{rw, nc}, nil
+}
+
+// write an unmasked frame
+func (c *wsConn) write(opcode byte, payload []byte) error {
+	if err := writeFrame(c.rw, opcode, payload, nil); err != nil {
+		return err
+	}
+	return c.rw.Flush()
+}
+
+// read a text or binary message, answering pings. Returns io.EOF if the
+// client closes the connection.
+func (c *wsConn) read() ([]byte, error) {
+	var msg []byte
+	for {
+		fin, opcode, payload, err := readFrame(c.rw, maxMemory-len(msg))
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case wsPing:
+			if err := c.write(wsPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case wsPong:
+			continue
+		case wsClose:
+			return nil, io.EOF
+		}
+		msg = append(msg, payload...)
+		if fin {
+			return msg, nil
+		}
+	}
+}
+
+// send a close frame and close the connection
+func (c *wsConn) close() {
+	c.write(wsClose, nil)
+	c.closer.Close()
+}
+
+// write a single frame with the FIN bit set. If mask is not nil, the payload
+// is masked with it, as clients must do.
+func writeFrame(w io.Writer, opcode byte, payload []byte, mask []byte) error {
+	h := []byte{0x80 | opcode, 0}
+	switch n := len(payload); {
+	case n < 126:
+		h[1] = byte(n)
+	case n <= 0xffff:
+		h[1] = 126
+		h = binary.BigEndian.AppendUint16(h, uint16(n))
+	default:
+		h[1] = 127
+		h = binary.BigEndian.AppendUint64(h, uint64(n))
+	}
+	if mask != nil {
+		h[1] |= 0x80
+		h = append(h, mask...)
+		masked := make([]byte, len(payload))
+		for i := range payload {
+			masked[i] = payload[i] ^ mask[i%4]
+		}
+		payload = masked
+	}
+	if _, err := w.Write(h); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// read a single frame of at most max payload bytes, unmasking its payload
+func readFrame(r io.Reader, max int) (fin bool, opcode byte, payload []byte, err error) {
+	h := make([]byte, 8)
+	if _, err = io.ReadFull(r, h[:2]); err != nil {
+		return
+	}
+	fin, opcode = h[0]&0x80 != 0, h[0]&0x0f
+	masked := h[1]&0x80 != 0
+	n := uint64(h[1] & 0x7f)
+	switch n {
+	case 126:
+		if _, err = io.ReadFull(r, h[:2]); err != nil {
+			return
+		}
+		n = uint64(binary.BigEndian.Uint16(h))
+	case 127:
+		if _, err = io.ReadFull(r, h); err != nil {
+			return
+		}
+		n = binary.BigEndian.Uint64(h)
+	}
+	if n > uint64(max) {
+		err = errWSTooLarge
+		return
+	}
+	mask := h[:4]
+	if masked {
+		if _, err = io.ReadFull(r, mask); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, n)
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hypoactiv/objsearch/objsearchpb"
+)
+
+func TestWSAccept(t *testing.T) {
+	// the example from RFC 6455
+	if a := wsAccept("dGhlIHNhbXBsZSBub25jZQ=="); a != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatal("accept key error", a)
+	}
+}
+
+// perform the search of req over a WebSocket connection to url, returning
+// the updates received
+func wsSearch(t *testing.T, url string, req *objsearchpb.SearchRequest) []wsUpdate {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("GET / HTTP/1.1\r\nHost: test\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n"))
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil || resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatal("handshake error", resp, err)
+	}
+	var templates []map[string]interface{}
+	for _, tm := range req.Templates {
+		templates = append(templates, map[string]interface{}{"label": tm.Label, "image": tm.Image})
+	}
+	msg, _ := json.Marshal(map[string]interface{}{
+		"field":     req.Field,
+		"templates": templates,
+		"options":   map[string]interface{}{"tolerance": 0.01, "mindist": 5, "color": "rgb"},
+	})
+	if err := writeFrame(conn, wsText, msg, []byte{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	var updates []wsUpdate
+	for {
+		_, opcode, payload, err := readFrame(r, maxMemory)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if opcode == wsClose {
+			return updates
+		}
+		u := wsUpdate{}
+		if err := json.Unmarshal(payload, &u); err != nil {
+			t.Fatal(err)
+		}
+		updates = append(updates, u)
+	}
+}
+
+func TestWebSocketHandler(t *testing.T) {
+	srv := httptest.NewServer(NewServer(1).WebSocketHandler())
+	defer srv.Close()
+	updates := wsSearch(t, srv.URL, testRequest())
+	hits := 0
+	for _, u := range updates {
+		hits += len(u.Hits)
+		if u.Error != "" {
+			t.Fatal(u.Error)
+		}
+	}
+	if len(updates) < 3 || updates[len(updates)-1].Progress != 1 || hits != 2 {
+		t.Fatal("updates error", updates)
+	}
+}
+
+func TestWebSocketHandlerPanic(t *testing.T) {
+	// a single slot, which a panicking search must not leak
+	srv := httptest.NewServer(NewServer(1).WebSocketHandler())
+	defer srv.Close()
+	updates := wsSearch(t, srv.URL, degenerateRequest())
+	if len(updates) == 0 || !strings.HasPrefix(updates[len(updates)-1].Error, ErrSearchFailed.Error()) {
+		t.Fatal("expected search error", updates)
+	}
+	updates = wsSearch(t, srv.URL, testRequest())
+	if len(updates) == 0 || updates[len(updates)-1].Progress != 1 {
+		t.Fatal("search after panic failed", updates)
+	}
+}
+
+func TestWebSocketHandshakeRequired(t *testing.T) {
+	srv := httptest.NewServer(NewServer(1).WebSocketHandler())
+	defer srv.Close()
+	if resp, err := http.Get(srv.URL); err != nil || resp.StatusCode != http.StatusBadRequest {
+		t.Fatal("expected bad request", err)
+	}
+}
@@ -0,0 +1,112 @@
+package objsearch
+
+import (
+	"image"
+	"sort"
+)
+
+// One part of a search divided by SplitSearch, to be searched
+// independently, e.g. on another machine, with SearchShard
+type Shard struct {
+	// Index of the shard, counting from 0, and the number of shards
+	Index, Count int
+	// Top-left corners whose hits the shard reports, a band of rows of
+	// Whole
+	Rect image.Rectangle
+	// The rectangle divided
+	Whole image.Rectangle
+}
+
+// Returns the rectangle of top-left corners scored for s, Rect and a margin
+// of twice minDist within Whole, so that hits near the edges of Rect are
+// suppressed as they would be if Whole were searched at once
+func (s Shard) SearchRect(minDist int) image.Rectangle {
+	m := 2 * minDist
+	return image.Rect(s.Rect.Min.X, s.Rect.Min.Y-m, s.Rect.Max.X, s.Rect.Max.Y+m).Intersect(s.Whole)
+}
+
+// Returns the region of the field that SearchShard reads to search s for
+// an object with bounds object, with hits at least minDist apart
+func (s Shard) FieldRect(object image.Rectangle, minDist int) image.Rectangle {
+	r := s.SearchRect(minDist)
+	return image.Rectangle{r.Min.Add(object.Min), r.Max.Add(object.Max).Sub(image.Point{1, 1})}
+}
+
+// Divides rect into n shards of nearly equal bands of rows, or fewer if
+// rect has fewer rows, that together search it as SearchImage would
+func SplitSearch(rect image.Rectangle, n int) []Shard {
+	if n > rect.Dy() {
+		n = rect.Dy()
+	}
+	shards := make([]Shard, n)
+	for i := range shards {
+		shards[i] = Shard{
+			Index: i,
+			Count: n,
+			Rect:  image.Rect(rect.Min.X, rect.Min.Y+i*rect.Dy()/n, rect.Max.X, rect.Min.Y+(i+1)*rect.Dy()/n),
+			Whole: rect,
+		}
+	}
+	return shards
+}
+
+// The hits of a Shard, as returned by SearchShard, with the options
+// needed to merge them with MergeHits
+type ShardHits struct {
+	Shard     Shard
+	Hits      []Hit
+	ScoreMode ScoreMode
+	HitMode   HitMode
+	MinDist   int
+	K         int
+}
+
+// Searches field for object at the positions of s, and returns the hits in
+// s.Rect. field need only hold s.FieldRect(object.Bounds(), opts.MinDist).
+//
+// Panics with a relative opts.ScoreMode, whose scores MergeHits could not
+// compare across shards. AdaptiveTolerance compares local contrast with its
+// mean over each shard, and HITMODE_LOCALMINIMA measures prominence within
+// each shard.
+func SearchShard(field, object image.Image, s Shard, opts Options) ShardHits {
+	if newContext(s.Rect, opts).relativeScores() {
+		panic("sharded search requires an absolute score mode")
+	}
+	res := ShardHits{Shard: s, ScoreMode: opts.ScoreMode, HitMode: opts.HitMode, MinDist: opts.MinDist, K: opts.K}
+	for _, h := range SearchImage(field, object, s.SearchRect(opts.MinDist), opts) {
+		if h.P.In(s.Rect) {
+			res.Hits = append(res.Hits, h)
+		}
+	}
+	return res
+}
+
+// Returns the hits of a search divided by SplitSearch, merged from the
+// hits of its shards in any order, at least MinDist apart across shards,
+// best first. The result does not depend on the order of shards. Hits are
+// as SearchImage would find them, unless chains of positions closer than
+// MinDist that score better than the tolerance span more than the margin of
+// Shard.SearchRect.
+func MergeHits(shards ...ShardHits) []Hit {
+	if len(shards) == 0 {
+		return nil
+	}
+	first := shards[0]
+	shards = append([]ShardHits(nil), shards...)
+	sort.Slice(shards, func(i, j int) bool {
+		return shards[i].Shard.Index < shards[j].Shard.Index
+	})
+	var hits []Hit
+	for _, s := range shards {
+		if s.ScoreMode != first.ScoreMode || s.HitMode != first.HitMode || s.MinDist != first.MinDist || s.K != first.K {
+			panic("shards searched with different options")
+		}
+		hits = append(hits, s.Hits...)
+	}
+	ctx := newContext(first.Shard.Whole, Options{ScoreMode: first.ScoreMode, MinDist: first.MinDist})
+	hits = ctx.suppress(hits)
+	if first.HitMode == HITMODE_BESTK && len(hits) > first.K {
+		hits = hits[:first.K]
+	}
+	return hits
+}
@@ -0,0 +1,52 @@
+package objsearch
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+)
+
+func TestShardedSearch(t *testing.T) {
+	object := randomRGBImage(10, 10)
+	field := randomRGBImage(100, 90)
+	// objects on and near the shard boundaries
+	for _, p := range []image.Point{{5, 5}, {40, 19}, {60, 22}, {30, 60}, {80, 75}} {
+		field = frameWithObject(field, object, p)
+	}
+	rect := validRect(field.Rect, object.Rect)
+	for _, opts := range []Options{
+		{Tolerance: 0.05, MinDist: 5, ScoreMode: SCOREMODE_SQDIFF_NORMED},
+		{Tolerance: 0.9, MinDist: 5, ScoreMode: SCOREMODE_CCOEFF_NORMED},
+		{Tolerance: 0.1, MinDist: 8, ScoreMode: SCOREMODE_L1_ABSOLUTE},
+		{MinDist: 5, ScoreMode: SCOREMODE_SQDIFF_NORMED, HitMode: HITMODE_BESTK, K: 5},
+	} {
+		want := SearchImage(field, object, rect, opts)
+		shards := SplitSearch(rect, 4)
+		if len(shards) != 4 || shards[0].Rect.Min != rect.Min || shards[3].Rect.Max != rect.Max {
+			t.Fatal("split error", shards)
+		}
+		var results []ShardHits
+		for _, s := range shards {
+			// each shard reads only its part of the field
+			part := field.SubImage(s.FieldRect(object.Rect, opts.MinDist))
+			results = append(results, SearchShard(part, object, s, opts))
+		}
+		rand.Shuffle(len(results), func(i, j int) {
+			results[i], results[j] = results[j], results[i]
+		})
+		hits := MergeHits(results...)
+		SortRaster(hits)
+		SortRaster(want)
+		if len(hits) != len(want) {
+			t.Fatal("sharded search error", opts.ScoreMode, hits, want)
+		}
+		for i := range hits {
+			if hits[i] != want[i] {
+				t.Fatal("sharded search error", opts.ScoreMode, hits, want)
+			}
+		}
+	}
+	if s := SplitSearch(image.Rect(0, 0, 10, 2), 5); len(s) != 2 {
+		t.Fatal("split error", s)
+	}
+}
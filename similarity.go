@@ -0,0 +1,68 @@
+package objsearch
+
+import "image"
+
+// Returns the matrix of distances between every pair of templates, where
+// the distance between two templates is the mean absolute per-pixel
+// difference at the position where the smaller best matches the larger, as
+// found with opts. Templates of which neither fits within the other have
+// distance 1. The matrix is symmetric with zeros on the diagonal.
+func TemplateDistances(templates []image.Image, opts Options) [][]float64 {
+	n := len(templates)
+	prepared := make([]*Object, n)
+	for i := range templates {
+		prepared[i] = NewObject(templates[i])
+	}
+	dist := make([][]float64, n)
+	for i := range dist {
+		dist[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			d := templateDistance(prepared[i], prepared[j], opts)
+			dist[i][j], dist[j][i] = d, d
+		}
+	}
+	return dist
+}
+
+// return the distance between a and b, as described in TemplateDistances
+func templateDistance(a, b *Object, opts Options) float64 {
+	if r := validRect(b.Bounds(), a.Bounds()); !r.Empty() {
+		_, d, _ := bestMatch(NewField(b), a, r, opts)
+		return d
+	}
+	if r := validRect(a.Bounds(), b.Bounds()); !r.Empty() {
+		_, d, _ := bestMatch(NewField(a), b, r, opts)
+		return d
+	}
+	return 1
+}
+
+// Returns the index pairs {i, j}, i < j, of templates whose distance in dist
+// is below threshold, in row-major order. dist is a matrix as returned by
+// TemplateDistances.
+func Duplicates(dist [][]float64, threshold float64) (pairs [][2]int) {
+	for i := range dist {
+		for j := i + 1; j < len(dist[i]); j++ {
+			if dist[i][j] < threshold {
+				pairs = append(pairs, [2]int{i, j})
+			}
+		}
+	}
+	return
+}
+
+// Returns the label pairs of registered objects whose distance, as computed
+// by TemplateDistances with t.Options, is below threshold. Labels in each
+// pair, and the pairs themselves, are in registration order.
+func (t *TemplateSet) Duplicates(threshold float64) (pairs [][2]string) {
+	templates := make([]image.Image, len(t.labels))
+	for i, label := range t.labels {
+		templates[i] = t.searchers[label].Object
+	}
+	for _, p := range Duplicates(TemplateDistances(templates, t.Options), threshold) {
+		pairs = append(pairs, [2]string{t.labels[p[0]], t.labels[p[1]]})
+	}
+	return
+}
@@ -0,0 +1,39 @@
+package objsearch
+
+import (
+	"image"
+	"testing"
+)
+
+func TestTemplateDistances(t *testing.T) {
+	a := randomRGBImage(10, 10)
+	b := randomRGBImage(10, 10)
+	// c contains a
+	c := frameWithObject(randomRGBImage(16, 16), a, image.Point{3, 4})
+	// d does not fit within or around a
+	d := randomRGBImage(20, 5)
+	dist := TemplateDistances([]image.Image{a, b, c, d}, Options{})
+	for i := range dist {
+		if dist[i][i] != 0 {
+			t.Fatal("nonzero diagonal", dist)
+		}
+		for j := range dist {
+			if dist[i][j] != dist[j][i] {
+				t.Fatal("matrix not symmetric", dist)
+			}
+		}
+	}
+	if dist[0][2] != 0 || dist[0][1] == 0 || dist[0][3] != 1 {
+		t.Fatal("template distance error", dist)
+	}
+	if p := Duplicates(dist, 0.01); len(p) != 1 || p[0] != [2]int{0, 2} {
+		t.Fatal("duplicates error", p)
+	}
+	set := NewTemplateSet(Options{})
+	set.Add("a", a)
+	set.Add("b", b)
+	set.Add("c", c)
+	if p := set.Duplicates(0.01); len(p) != 1 || p[0] != [2]string{"a", "c"} {
+		t.Fatal("template set duplicates error", p)
+	}
+}
@@ -0,0 +1,129 @@
+package objsearch
+
+import (
+	"context"
+	"errors"
+	"image"
+	"io"
+	"time"
+)
+
+// A source of video frames, such as a camera, screen capture, or video file
+type FrameSource interface {
+	// Returns the next frame and its capture time. Returns io.EOF when there
+	// are no more frames.
+	Next() (image.Image, time.Time, error)
+}
+
+// The search result for one frame of a FrameSource
+type FrameResult struct {
+	// Index of the frame, counting from 0
+	Index int
+	Time  time.Time
+	Frame image.Image
+	Hits  []Hit
+}
+
+// Searches each frame from src with s and calls fn with the result, until
+// src is exhausted, fn or src return an error, or ctx is done. Returns nil if
+// src was exhausted, and otherwise the error that stopped the loop.
+func Run(ctx context.Context, src FrameSource, s *Searcher, fn func(FrameResult) error) error {
+	for i := 0; ; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		frame, t, err := src.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := fn(FrameResult{i, t, frame, s.Search(frame)}); err != nil {
+			return err
+		}
+	}
+}
+
+// Like Run, delivering results on a channel. The result channel is closed
+// when the loop stops, after which the error channel delivers Run's result.
+func Stream(ctx context.Context, src FrameSource, s *Searcher) (<-chan FrameResult, <-chan error) {
+	results := make(chan FrameResult)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer close(results)
+		errc <- Run(ctx, src, s, func(r FrameResult) error {
+			select {
+			case results <- r:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return results, errc
+}
+
+// A FrameSource serving a fixed sequence of frames, spaced Interval apart
+// starting at Start
+type SliceSource struct {
+	Frames   []image.Image
+	Start    time.Time
+	Interval time.Duration
+	next     int
+}
+
+func (s *SliceSource) Next() (image.Image, time.Time, error) {
+	if s.next >= len(s.Frames) {
+		return nil, time.Time{}, io.EOF
+	}
+	i := s.next
+	s.next++
+	return s.Frames[i], s.Start.Add(time.Duration(i) * s.Interval), nil
+}
+
+// Returned by WaitFor when the object does not appear in time
+var ErrTimeout = errors.New("objsearch: timed out waiting for object")
+
+// Minimum time between the frames WaitFor searches, so that a source
+// returning frames as fast as they are asked for is not polled continuously
+const waitForInterval = 10 * time.Millisecond
+
+// Searches frames from src until object appears, and returns the best match
+// and the frame it appeared in. Returns ErrTimeout if the object has not
+// appeared after timeout, ctx's error if ctx is done first, or the error
+// returned by src. A timeout of zero waits until ctx is done.
+//
+// The object appears when its mean absolute per-pixel difference from some
+// window of a frame is at most opts.Tolerance, which should be in [0,1]. The
+// Hit returned has this difference as its score, whatever opts.ScoreMode is.
+// Scores normalized over each frame, as with SCOREMODE_L1 and
+// SCOREMODE_ZSCORE, are not used, since they rate a frame's best window well
+// whether or not it contains the object.
+//
+// Frames are searched at most once every 10ms. The timeout and ctx are
+// checked between frames, so a source that blocks in Next can delay
+// WaitFor's return.
+func WaitFor(ctx context.Context, src FrameSource, object image.Image, timeout time.Duration, opts Options) (Hit, image.Image, error) {
+	o := NewObject(object)
+	start := time.Now()
+	for {
+		polled := time.Now()
+		frame, _, err := src.Next()
+		if err != nil {
+			return Hit{}, nil, err
+		}
+		rect := validRect(frame.Bounds(), o.Bounds())
+		if p, d, ok := bestMatch(frame, o, rect, opts); ok && d <= opts.Tolerance {
+			return Hit{p, d}, frame, nil
+		}
+		if timeout != 0 && time.Since(start) >= timeout {
+			return Hit{}, nil, ErrTimeout
+		}
+		select {
+		case <-ctx.Done():
+			return Hit{}, nil, ctx.Err()
+		case <-time.After(time.Until(polled.Add(waitForInterval))):
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package objsearch
+
+import (
+	"context"
+	"image"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	bg := randomRGBImage(50, 50)
+	object := randomRGBImage(8, 8)
+	src := &SliceSource{}
+	for i := 0; i < 5; i++ {
+		src.Frames = append(src.Frames, frameWithObject(bg, object, image.Point{i * 5, 10}))
+	}
+	s := NewSearcher(object, Options{Tolerance: 0.1, MinDist: 8})
+	results, errc := Stream(context.Background(), src, s)
+	n := 0
+	for r := range results {
+		if r.Index != n || len(r.Hits) == 0 || r.Hits[0].P != (image.Point{n * 5, 10}) {
+			t.Fatal("Stream result error", r.Index, r.Hits)
+		}
+		n++
+	}
+	if err := <-errc; err != nil || n != 5 {
+		t.Fatal("Stream error", err, n)
+	}
+}
+
+// a FrameSource repeating one frame forever
+type constSource struct {
+	frame image.Image
+}
+
+func (s constSource) Next() (image.Image, time.Time, error) {
+	return s.frame, time.Now(), nil
+}
+
+func TestWaitFor(t *testing.T) {
+	bg := randomRGBImage(40, 40)
+	object := randomRGBImage(8, 8)
+	src := &SliceSource{Frames: []image.Image{bg, bg, frameWithObject(bg, object, image.Point{20, 3}), bg}}
+	h, frame, err := WaitFor(context.Background(), src, object, 0, Options{Tolerance: 0.01})
+	if err != nil || h != (Hit{image.Point{20, 3}, 0}) || frame != src.Frames[2] {
+		t.Fatal("WaitFor error", h, err)
+	}
+	if _, _, err := WaitFor(context.Background(), src, object, 0, Options{Tolerance: 0.01}); err != io.EOF {
+		t.Fatal("expected EOF", err)
+	}
+	if _, _, err := WaitFor(context.Background(), constSource{bg}, object, 20*time.Millisecond, Options{Tolerance: 0.01}); err != ErrTimeout {
+		t.Fatal("expected timeout", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := WaitFor(ctx, constSource{bg}, object, 0, Options{Tolerance: 0.01}); err != context.DeadlineExceeded {
+		t.Fatal("expected cancellation", err)
+	}
+}
@@ -0,0 +1,105 @@
+package objsearch
+
+import "image"
+
+// Searches field for object at the top-left corners in rect in order of
+// distance from seed, ring by ring, so that an object that has barely moved
+// since it was last seen at seed is found after scoring only a few
+// positions. An empty rect searches every position at which object lies
+// within field.
+//
+// When a position scores better than opts.Tolerance, the search climbs to
+// the best-scoring position nearby, which is reported as a hit: found, if
+// not nil, is called with it, and the search stops if found returns false.
+// Positions less than opts.MinDist from a hit are skipped. Returns the hits
+// found, in the order found was called with them. opts.HitMode is ignored.
+//
+// A position's score must not depend on the positions not yet visited, so
+// SearchSpiral panics unless opts.ScoreMode is SCOREMODE_SQDIFF_NORMED,
+// SCOREMODE_CCOEFF_NORMED or SCOREMODE_L1_ABSOLUTE.
+func SearchSpiral(field, object image.Image, rect image.Rectangle, seed image.Point, opts Options, found func(Hit) bool) []Hit {
+	if newContext(image.Rectangle{}, opts).relativeScores() {
+		panic("spiral search requires an absolute score mode")
+	}
+	f, o := NewField(field), NewObject(object)
+	if rect.Empty() {
+		rect = searchableRect(f.Bounds(), o.Bounds(), opts.Border)
+	}
+	rect, err := CheckRect(f.Bounds(), o.Bounds(), rect, opts)
+	if err != nil {
+		panic(err)
+	}
+	if rect.Empty() {
+		return nil
+	}
+	ctx, fieldPlanes, objectPlanes := newImageContext(f, o, rect, opts)
+	// the scores of the positions in r, in raster order
+	scores := func(r image.Rectangle) []float64 {
+		c := ctx
+		c.SearchRect = r
+		return c.scores(fieldPlanes, objectPlanes)
+	}
+	// the best position reached by climbing from h, within rect
+	climb := func(h Hit) Hit {
+		for {
+			n := image.Rect(h.P.X-1, h.P.Y-1, h.P.X+2, h.P.Y+2).Intersect(rect)
+			best := h
+			for i, s := range scores(n) {
+				if ctx.better(s, best.S) {
+					best = Hit{image.Point{n.Min.X + i%n.Dx(), n.Min.Y + i/n.Dx()}, s}
+				}
+			}
+			if best == h {
+				return h
+			}
+			h = best
+		}
+	}
+	var hits []Hit
+	near := func(p image.Point) bool {
+		for _, h := range hits {
+			if h.P == p || h.Distance(Hit{P: p}) < opts.MinDist {
+				return true
+			}
+		}
+		return false
+	}
+	for d := 0; ; d++ {
+		// the positions at distance d from seed
+		ring := image.Rect(seed.X-d, seed.Y-d, seed.X+d+1, seed.Y+d+1)
+		for _, side := range subtractRects([]image.Rectangle{ring}, ring.Inset(1)) {
+			side = side.Intersect(rect)
+			if side.Empty() {
+				continue
+			}
+			for i, s := range scores(side) {
+				p := image.Point{side.Min.X + i%side.Dx(), side.Min.Y + i/side.Dx()}
+				if !ctx.better(s, ctx.Tolerance) || near(p) {
+					continue
+				}
+				h := climb(Hit{p, s})
+				if near(h.P) {
+					continue
+				}
+				hits = append(hits, h)
+				if found != nil && !found(h) {
+					return hits
+				}
+			}
+		}
+		if rect.In(ring) {
+			// every position has been visited
+			return hits
+		}
+	}
+}
+
+// Returns the hit nearest seed found by SearchSpiral, stopping the search
+// as soon as it is found. Returns false if there is none.
+func FindNearest(field, object image.Image, rect image.Rectangle, seed image.Point, opts Options) (Hit, bool) {
+	hits := SearchSpiral(field, object, rect, seed, opts, func(Hit) bool { return false })
+	if len(hits) == 0 {
+		return Hit{}, false
+	}
+	return hits[0], true
+}
@@ -0,0 +1,54 @@
+package objsearch
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestSearchSpiral(t *testing.T) {
+	object := randomRGBImage(10, 10)
+	field := frameWithObject(frameWithObject(randomRGBImage(100, 80), object, image.Point{12, 8}), object, image.Point{70, 50})
+	opts := Options{Tolerance: 0.05, MinDist: 5, ScoreMode: SCOREMODE_SQDIFF_NORMED}
+	// nearest the seed first
+	hits := SearchSpiral(field, object, image.Rectangle{}, image.Point{65, 52}, opts, nil)
+	if len(hits) != 2 || hits[0] != (Hit{image.Point{70, 50}, 0}) || hits[1] != (Hit{image.Point{12, 8}, 0}) {
+		t.Fatal("spiral search error", hits)
+	}
+	// with the seed outside the field
+	hits = SearchSpiral(field, object, image.Rectangle{}, image.Point{-20, -20}, opts, nil)
+	if len(hits) != 2 || hits[0].P != (image.Point{12, 8}) {
+		t.Fatal("spiral search error", hits)
+	}
+	// climbing to the match from a position that only just passes
+	blob := image.NewGray(image.Rect(0, 0, 21, 21))
+	for y := 0; y < 21; y++ {
+		for x := 0; x < 21; x++ {
+			blob.SetGray(x, y, color.Gray{uint8(255 - 2*((x-10)*(x-10)+(y-10)*(y-10)))})
+		}
+	}
+	flat := image.NewGray(image.Rect(0, 0, 80, 60))
+	draw.Draw(flat, flat.Rect, image.Black, image.Point{}, draw.Src)
+	draw.Draw(flat, blob.Rect.Add(image.Point{40, 30}), blob, image.Point{}, draw.Src)
+	if h, ok := FindNearest(flat, blob, image.Rectangle{}, image.Point{30, 28}, Options{Tolerance: 0.9, ScoreMode: SCOREMODE_SQDIFF_NORMED}); !ok || h.P != (image.Point{40, 30}) {
+		t.Fatal("nearest hit error", h, ok)
+	}
+	opts.Tolerance = 0
+	if _, ok := FindNearest(field, object, image.Rectangle{}, image.Point{30, 30}, opts); ok {
+		t.Fatal("hit found with zero tolerance")
+	}
+	// unnormalized L1 distances score positions independently too
+	abs := Options{Tolerance: 0.05, MinDist: 5, ScoreMode: SCOREMODE_L1_ABSOLUTE}
+	hits = SearchSpiral(field, object, image.Rectangle{}, image.Point{65, 52}, abs, nil)
+	if len(hits) != 2 || hits[0] != (Hit{image.Point{70, 50}, 0}) || hits[1] != (Hit{image.Point{12, 8}, 0}) {
+		t.Fatal("L1 absolute spiral search error", hits)
+	}
+	// relative score modes, including the default, panic
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for a relative score mode")
+		}
+	}()
+	SearchSpiral(field, object, image.Rectangle{}, image.Point{}, Options{Tolerance: 0.05}, nil)
+}
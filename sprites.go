@@ -0,0 +1,73 @@
+package objsearch
+
+import (
+	"image"
+	"image/draw"
+	"strconv"
+)
+
+// Layout of a sprite sheet as a grid of equally sized cells
+type SpriteGrid struct {
+	// Size of each cell
+	Size image.Point
+	// Offset of the first cell from the top-left corner of the sheet
+	Margin image.Point
+	// Gap between adjacent cells
+	Spacing image.Point
+	// Number of columns and rows. Zero fits as many as the sheet allows.
+	Cols, Rows int
+}
+
+// Returns the rectangles of the cells of g within a sheet with bounds b, in
+// raster order
+func (g SpriteGrid) Rects(b image.Rectangle) (rects []image.Rectangle) {
+	if g.Size.X <= 0 || g.Size.Y <= 0 {
+		panic("invalid sprite size")
+	}
+	step := g.Size.Add(g.Spacing)
+	cols, rows := g.Cols, g.Rows
+	if cols == 0 {
+		cols = (b.Dx() - g.Margin.X + g.Spacing.X) / step.X
+	}
+	if rows == 0 {
+		rows = (b.Dy() - g.Margin.Y + g.Spacing.Y) / step.Y
+	}
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			min := b.Min.Add(g.Margin).Add(image.Point{c * step.X, r * step.Y})
+			rects = append(rects, image.Rectangle{min, min.Add(g.Size)})
+		}
+	}
+	return
+}
+
+// Returns copies of the rects portions of sheet, each with bounds at the
+// origin
+func SplitSprites(sheet image.Image, rects []image.Rectangle) []*image.RGBA {
+	sprites := make([]*image.RGBA, len(rects))
+	for i, r := range rects {
+		sprites[i] = image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+		draw.Draw(sprites[i], sprites[i].Rect, sheet, r.Min, draw.Src)
+	}
+	return sprites
+}
+
+// Returns a TemplateSet holding the rects portions of sheet. Sprite i is
+// labeled names[i], or its index if names is shorter than rects.
+func SpriteSet(sheet image.Image, rects []image.Rectangle, names []string, opts Options) *TemplateSet {
+	t := NewTemplateSet(opts)
+	for i, s := range SplitSprites(sheet, rects) {
+		label := strconv.Itoa(i)
+		if i < len(names) {
+			label = names[i]
+		}
+		t.Add(label, s)
+	}
+	return t
+}
+
+// Searches field for every sprite of sheet, as SpriteSet and
+// TemplateSet.SearchAll do
+func SearchSprites(field, sheet image.Image, rects []image.Rectangle, names []string, opts Options) []LabeledHit {
+	return SpriteSet(sheet, rects, names, opts).SearchAll(field)
+}
@@ -0,0 +1,29 @@
+package objsearch
+
+import (
+	"image"
+	"image/draw"
+	"testing"
+)
+
+func TestSprites(t *testing.T) {
+	g := SpriteGrid{Size: image.Point{8, 6}, Margin: image.Point{1, 2}, Spacing: image.Point{2, 2}}
+	sheet := randomRGBImage(1+3*10, 2+2*8)
+	rects := g.Rects(sheet.Bounds())
+	if len(rects) != 6 || rects[4] != image.Rect(11, 10, 19, 16) {
+		t.Fatal("SpriteGrid error", rects)
+	}
+	sprites := SplitSprites(sheet, rects)
+	field := randomRGBImage(60, 60)
+	draw.Draw(field, sprites[4].Rect.Add(image.Point{30, 7}), sprites[4], image.ZP, draw.Src)
+	draw.Draw(field, sprites[1].Rect.Add(image.Point{3, 40}), sprites[1], image.ZP, draw.Src)
+	hits := SearchSprites(field, sheet, rects, []string{"a", "b"}, Options{Tolerance: 0.01, MinDist: 6})
+	if len(hits) != 2 {
+		t.Fatal("SearchSprites error", hits)
+	}
+	for _, h := range hits {
+		if !(h.Label == "4" && h.P == image.Point{30, 7}) && !(h.Label == "b" && h.P == image.Point{3, 40}) {
+			t.Fatal("SearchSprites error", hits)
+		}
+	}
+}
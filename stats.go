@@ -0,0 +1,83 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+	"sort"
+)
+
+// Summary statistics of a distance map or other FloatImage, for building
+// adaptive decision logic and diagnostics on top of a single search
+type MapStats struct {
+	// Number of pixels
+	N int
+	// Smallest and largest values, and the first pixel at which each occurs
+	// in raster order
+	Min, Max     float64
+	MinAt, MaxAt image.Point
+	Mean, StdDev float64
+	// Histogram[i] is the number of values in [Min+i*BinWidth,
+	// Min+(i+1)*BinWidth). The last bin also counts values equal to Max.
+	Histogram []int
+	BinWidth  float64
+	// the values, sorted, for Percentile
+	sorted []float64
+}
+
+// Returns the statistics of d, with a histogram of bins bins. d must not be
+// empty.
+func NewMapStats(d *FloatImage, bins int) *MapStats {
+	r := d.Rect
+	if r.Empty() {
+		panic("empty map")
+	}
+	if bins < 1 {
+		bins = 1
+	}
+	s := &MapStats{N: r.Dx() * r.Dy(), Histogram: make([]int, bins)}
+	s.sorted = make([]float64, 0, s.N)
+	s.Min, s.Max = math.Inf(1), math.Inf(-1)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			v := d.Pix[d.PixOffset(x, y)]
+			if v < s.Min {
+				s.Min, s.MinAt = v, image.Point{x, y}
+			}
+			if v > s.Max {
+				s.Max, s.MaxAt = v, image.Point{x, y}
+			}
+			s.sorted = append(s.sorted, v)
+		}
+	}
+	s.Mean, s.StdDev = meanStdDev(s.sorted)
+	s.BinWidth = (s.Max - s.Min) / float64(bins)
+	for _, v := range s.sorted {
+		i := bins - 1
+		if s.BinWidth > 0 {
+			i = int((v - s.Min) / s.BinWidth)
+		}
+		if i >= bins {
+			i = bins - 1
+		}
+		s.Histogram[i]++
+	}
+	sort.Float64s(s.sorted)
+	return s
+}
+
+// Returns the p-th percentile of the values, for p in [0,100], interpolating
+// linearly between the nearest values
+func (s *MapStats) Percentile(p float64) float64 {
+	p = math.Max(0, math.Min(100, p))
+	k := p / 100 * float64(s.N-1)
+	i := int(k)
+	if i >= s.N-1 {
+		return s.sorted[s.N-1]
+	}
+	return s.sorted[i] + (k-float64(i))*(s.sorted[i+1]-s.sorted[i])
+}
+
+// Returns the statistics of r.Scores, with a histogram of bins bins
+func (r *Result) Stats(bins int) *MapStats {
+	return NewMapStats(r.Scores, bins)
+}
@@ -0,0 +1,36 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestMapStats(t *testing.T) {
+	d := FloatImageFromRows([][]float64{{4, 1, 2}, {3, 0, 5}})
+	d.Rect = d.Rect.Add(image.Point{10, 20})
+	s := NewMapStats(d, 5)
+	if s.N != 6 || s.Min != 0 || s.Max != 5 || s.MinAt != (image.Point{11, 21}) || s.MaxAt != (image.Point{12, 21}) {
+		t.Fatal("extrema error", s)
+	}
+	if s.Mean != 2.5 || math.Abs(s.StdDev-math.Sqrt(17.5/6)) > 1e-12 {
+		t.Fatal("moment error", s.Mean, s.StdDev)
+	}
+	if s.BinWidth != 1 || len(s.Histogram) != 5 || s.Histogram[0] != 1 || s.Histogram[4] != 2 {
+		t.Fatal("histogram error", s.Histogram)
+	}
+	for p, want := range map[float64]float64{0: 0, 50: 2.5, 100: 5, 10: 0.5} {
+		if got := s.Percentile(p); math.Abs(got-want) > 1e-12 {
+			t.Fatal("percentile error", p, got)
+		}
+	}
+	flat := NewMapStats(NewFloatImage(image.Rect(0, 0, 3, 3)), 4)
+	if flat.Histogram[3] != 9 || flat.StdDev != 0 {
+		t.Fatal("flat map error", flat.Histogram)
+	}
+	object := randomRGBImage(8, 8)
+	field := frameWithObject(randomRGBImage(40, 30), object, image.Point{7, 9})
+	if rs := SearchResult(field, object, image.Rectangle{}, Options{}).Stats(10); rs.MinAt != (image.Point{7, 9}) || rs.Min != 0 {
+		t.Fatal("result stats error", rs.MinAt)
+	}
+}
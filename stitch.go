@@ -0,0 +1,66 @@
+package objsearch
+
+import "image"
+
+// Direction in which the second of two overlapping captures continues the
+// first
+type StitchDirection int
+
+const (
+	// The second capture continues the first downward, as when scrolling
+	STITCH_VERTICAL StitchDirection = iota
+	// The second capture continues the first to the right, as when panning
+	STITCH_HORIZONTAL
+)
+
+// The placement of one capture relative to another, as found by FindStitch
+type Stitch struct {
+	// The pixel of b at p lies over the pixel of a at p.Add(Offset)
+	Offset image.Point
+	// The overlap of the captures, in a's coordinates
+	Overlap image.Rectangle
+	// The quality of the seam, as the mean absolute per-pixel difference
+	// between the captures over Overlap, combined according to
+	// Options.CombineMode. Zero for captures that agree exactly.
+	Score float64
+}
+
+// Finds the placement of capture b continuing capture a in direction dir,
+// such as two screenshots of a page scrolled between them, so that they can
+// be stitched together. The captures overlap by at least minOverlap pixels,
+// and are offset across dir by at most maxDrift pixels either way.
+//
+// The band of b's leading edge minOverlap pixels deep, less maxDrift pixels
+// at either end, is searched for in the band of a where overlaps are
+// plausible. opts.Tolerance, MinDist, ScoreMode and HitMode are ignored.
+// Returns false if no placement is possible.
+func FindStitch(a, b image.Image, dir StitchDirection, minOverlap, maxDrift int, opts Options) (Stitch, bool) {
+	ab, bb := a.Bounds(), b.Bounds()
+	var band, shifts image.Rectangle
+	// the shift moving b's top-left corner to a's
+	o := ab.Min.Sub(bb.Min)
+	switch dir {
+	case STITCH_VERTICAL:
+		band = image.Rect(bb.Min.X+maxDrift, bb.Min.Y, bb.Max.X-maxDrift, bb.Min.Y+minOverlap)
+		shifts = image.Rect(o.X-maxDrift, o.Y, o.X+maxDrift+1, o.Y+ab.Dy()-minOverlap+1)
+	case STITCH_HORIZONTAL:
+		band = image.Rect(bb.Min.X, bb.Min.Y+maxDrift, bb.Min.X+minOverlap, bb.Max.Y-maxDrift)
+		shifts = image.Rect(o.X, o.Y-maxDrift, o.X+ab.Dx()-minOverlap+1, o.Y+maxDrift+1)
+	default:
+		panic("invalid stitch direction")
+	}
+	if minOverlap <= 0 || band.Empty() || !band.In(bb) {
+		return Stitch{}, false
+	}
+	src := toRGBA(b)
+	p, _, ok := bestMatch(a, src.SubImage(band), shifts.Intersect(validRect(ab, band)), opts)
+	if !ok {
+		return Stitch{}, false
+	}
+	overlap := ab.Intersect(bb.Add(p))
+	return Stitch{
+		Offset:  p,
+		Overlap: overlap,
+		Score:   distanceAt(a, src.SubImage(overlap.Sub(p)), p, opts),
+	}, true
+}
@@ -0,0 +1,27 @@
+package objsearch
+
+import (
+	"image"
+	"testing"
+)
+
+func TestFindStitch(t *testing.T) {
+	page := randomRGBImage(70, 200)
+	// two screenshots of page, scrolled by 90 rows and drifted by 2 columns
+	a := crop(page, image.Rect(2, 0, 62, 120))
+	b := crop(page, image.Rect(0, 90, 60, 210))
+	s, ok := FindStitch(a, b, STITCH_VERTICAL, 20, 4, Options{})
+	if !ok || s.Offset != (image.Point{-2, 90}) || s.Overlap != image.Rect(0, 90, 58, 120) || s.Score != 0 {
+		t.Fatal("vertical stitch error", s, ok)
+	}
+	// across
+	a = crop(page, image.Rect(0, 0, 50, 60))
+	b = crop(page, image.Rect(35, 3, 70, 63))
+	s, ok = FindStitch(a, b, STITCH_HORIZONTAL, 10, 5, Options{})
+	if !ok || s.Offset != (image.Point{35, 3}) || s.Overlap != image.Rect(35, 3, 50, 60) || s.Score != 0 {
+		t.Fatal("horizontal stitch error", s, ok)
+	}
+	if _, ok := FindStitch(a, b, STITCH_HORIZONTAL, 100, 5, Options{}); ok {
+		t.Fatal("stitched with an overlap larger than the captures")
+	}
+}
@@ -0,0 +1,96 @@
+package objsearch
+
+import (
+	"image"
+	"sort"
+)
+
+// A Hit tagged with the label of the object found
+type LabeledHit struct {
+	Hit
+	Label string
+}
+
+// A collection of objects registered under string labels, which are searched
+// for together
+type TemplateSet struct {
+	// Options for objects added with Add
+	Options   Options
+	searchers map[string]*Searcher
+	// labels in registration order
+	labels []string
+}
+
+// Returns an empty TemplateSet whose objects are searched for with opts
+func NewTemplateSet(opts Options) *TemplateSet {
+	return &TemplateSet{
+		Options:   opts,
+		searchers: make(map[string]*Searcher),
+	}
+}
+
+// Registers object under label, replacing any object already registered
+// under label
+func (t *TemplateSet) Add(label string, object image.Image) {
+	t.AddWithOptions(label, object, t.Options)
+}
+
+// Like Add, searching for object with opts instead of t.Options
+func (t *TemplateSet) AddWithOptions(label string, object image.Image, opts Options) {
+	if _, ok := t.searchers[label]; !ok {
+		t.labels = append(t.labels, label)
+	}
+	t.searchers[label] = NewSearcher(object, opts)
+}
+
+// Adds a negative object to the object registered under label, so that hits
+// on the object that match negative better are dropped. See
+// Searcher.Negatives.
+func (t *TemplateSet) AddNegative(label string, negative image.Image) {
+	s, ok := t.searchers[label]
+	if !ok {
+		panic("no object registered under " + label)
+	}
+	s.AddNegative(negative)
+}
+
+// Removes the object registered under label, if any
+func (t *TemplateSet) Remove(label string) {
+	if _, ok := t.searchers[label]; !ok {
+		return
+	}
+	delete(t.searchers, label)
+	for i := range t.labels {
+		if t.labels[i] == label {
+			t.labels = append(t.labels[:i], t.labels[i+1:]...)
+			break
+		}
+	}
+}
+
+// Returns the registered labels, in registration order
+func (t *TemplateSet) Labels() []string {
+	return append([]string{}, t.labels...)
+}
+
+// Returns the Searcher for the object registered under label, or nil
+func (t *TemplateSet) Searcher(label string) *Searcher {
+	return t.searchers[label]
+}
+
+// Searches field for every registered object, and returns all hits found
+// sorted by score. Hits with equal scores are in registration order of their
+// objects.
+func (t *TemplateSet) SearchAll(field image.Image) (hits []LabeledHit) {
+	// convert field only once
+	f := NewField(field)
+	for _, label := range t.labels {
+		for _, h := range t.searchers[label].Search(f) {
+			hits = append(hits, LabeledHit{h, label})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].S < hits[j].S
+	})
+	return
+}
@@ -0,0 +1,25 @@
+package objsearch
+
+import (
+	"image"
+	"testing"
+)
+
+func TestTemplateSet(t *testing.T) {
+	field := randomRGBImage(60, 60)
+	ok := crop(field, image.Rect(5, 5, 15, 13))
+	cancel := crop(field, image.Rect(40, 30, 50, 38))
+	ts := NewTemplateSet(Options{Tolerance: 0.01, MinDist: 8})
+	ts.Add("ok", ok)
+	ts.Add("cancel", cancel)
+	ts.Add("missing", randomRGBImage(10, 8))
+	ts.Remove("missing")
+	if l := ts.Labels(); len(l) != 2 || l[0] != "ok" || l[1] != "cancel" {
+		t.Fatal("Labels error", l)
+	}
+	hits := ts.SearchAll(field)
+	if len(hits) != 2 || hits[0].Label != "ok" || hits[0].P != (image.Point{5, 5}) ||
+		hits[1].Label != "cancel" || hits[1].P != (image.Point{40, 30}) {
+		t.Fatal("SearchAll error", hits)
+	}
+}
@@ -0,0 +1,49 @@
+package objsearch
+
+import "image"
+
+// The repetition of a tiled pattern, as found by FindTiling
+type Tiling struct {
+	// Horizontal and vertical repetition periods, in pixels, or 0 if the
+	// pattern does not repeat in that direction
+	Period image.Point
+	// Distances between the region and itself shifted by Period.X and
+	// Period.Y, as mean absolute per-pixel differences
+	ScoreX, ScoreY float64
+}
+
+// Finds the horizontal and vertical periods, of at most maxPeriod pixels,
+// with which the pattern in region of field repeats, such as a tiled
+// background, so that it can be subtracted before searching. The region is
+// compared with itself shifted by each lag up to maxPeriod, and the period
+// is the smallest lag at which the distance is a local minimum of at most
+// opts.Tolerance, as a mean absolute per-pixel difference in [0,1]. Other
+// fields of opts apply as for SearchImage.
+//
+// region must be more than maxPeriod pixels wide and high.
+func FindTiling(field image.Image, region image.Rectangle, maxPeriod int, opts Options) Tiling {
+	if region.Dx() <= maxPeriod || region.Dy() <= maxPeriod || !region.In(field.Bounds()) {
+		panic("region too small for maxPeriod, or outside field")
+	}
+	f := NewField(field)
+	var t Tiling
+	t.Period.X, t.ScoreX = period(f, image.Rectangle{region.Min, region.Max.Sub(image.Point{maxPeriod, 0})}, image.Point{1, 0}, maxPeriod, opts)
+	t.Period.Y, t.ScoreY = period(f, image.Rectangle{region.Min, region.Max.Sub(image.Point{0, maxPeriod})}, image.Point{0, 1}, maxPeriod, opts)
+	return t
+}
+
+// return the smallest lag, of at most maxLag steps in direction dir, at
+// which the distance between the part of field in r and field shifted by
+// the lag is a local minimum of at most opts.Tolerance, and the distance,
+// or 0 if there is no such lag
+func period(field *Field, r image.Rectangle, dir image.Point, maxLag int, opts Options) (int, float64) {
+	// hits are at the lag, the distance at lag 0 being 0
+	_, d := searchDistances(field, field.SubImage(r), image.Rectangle{image.Point{}, dir.Mul(maxLag).Add(image.Point{1, 1})}, opts)
+	for lag := 1; lag <= maxLag; lag++ {
+		if d[lag] > opts.Tolerance || d[lag] > d[lag-1] || lag < maxLag && d[lag] > d[lag+1] {
+			continue
+		}
+		return lag, d[lag]
+	}
+	return 0, 0
+}
@@ -0,0 +1,27 @@
+package objsearch
+
+import (
+	"image"
+	"image/draw"
+	"testing"
+)
+
+func TestFindTiling(t *testing.T) {
+	tile := randomRGBImage(7, 5)
+	field := randomRGBImage(100, 80)
+	// a tiled background in the region
+	region := image.Rect(10, 10, 90, 70)
+	for y := region.Min.Y; y < region.Max.Y; y += 5 {
+		for x := region.Min.X; x < region.Max.X; x += 7 {
+			draw.Draw(field, image.Rect(x, y, x+7, y+5).Intersect(region), tile, image.Point{}, draw.Src)
+		}
+	}
+	tl := FindTiling(field, region, 20, Options{Tolerance: 0.01})
+	if tl != (Tiling{Period: image.Point{7, 5}}) {
+		t.Fatal("tiling error", tl)
+	}
+	// random noise does not repeat
+	if tl = FindTiling(field, image.Rect(0, 0, 100, 10), 8, Options{Tolerance: 0.01}); tl.Period != (image.Point{}) {
+		t.Fatal("noise repeats", tl)
+	}
+}
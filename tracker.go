@@ -0,0 +1,240 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+)
+
+// Follows a single object across the frames of a video.
+//
+// Rather than searching each whole frame, a Tracker searches only a window
+// around the position predicted from the object's last position and
+// velocity.
+type Tracker struct {
+	// The object being tracked
+	Object *Object
+	// Search options. The object is matched where its mean absolute
+	// per-pixel difference from the frame is least, so Tolerance, MinDist,
+	// ScoreMode and HitMode, with the options of the hit modes, are unused.
+	Options Options
+	// Pixels around the predicted position to search in each frame
+	Margin int
+	// If not zero, matches whose mean absolute per-pixel difference from the
+	// object exceeds MaxDistance are treated as misses
+	MaxDistance float64
+	// If true, positions are predicted and smoothed by a constant-velocity
+	// Kalman filter, and the search window grows with the uncertainty of the
+	// prediction. This keeps the track alive through brief occlusions and
+	// reduces jitter.
+	Kalman bool
+	// Variance of the object's acceleration, in pixels per frame squared, and
+	// of the measured positions, in pixels squared. Used only with Kalman.
+	// With both zero, the filter follows the measured positions.
+	ProcessNoise, MeasurementNoise float64
+	// If not zero, each match is blended into Object with this weight, so
+	// that gradual changes in the object's appearance, such as lighting or
+	// slow rotation, don't lose the track
+	LearningRate float64
+	// If not zero, a match is not blended into Object if doing so would make
+	// the mean absolute per-pixel difference between Object and the original
+	// object exceed MaxDrift. This keeps the template from drifting onto the
+	// background.
+	MaxDrift float64
+	// Current state of the track
+	Track Track
+	// If true, each match is appended to Trajectory
+	Record     bool
+	Trajectory Trajectory
+	// true once the object's position is known
+	started bool
+	// Kalman filters for the X and Y coordinates
+	kx, ky kalman1
+	// the object before adaptation, and the adapted object's opaque colors
+	original *Object
+	template []float64
+}
+
+// The state of a tracked object
+type Track struct {
+	// Position of the top-left corner of the object. If the Tracker uses a
+	// Kalman filter, this is the filtered position.
+	P image.Point
+	// Position at which the object was last matched
+	M image.Point
+	// Velocity in pixels per frame
+	Vx, Vy float64
+	// Mean absolute per-pixel difference between the object and the field at
+	// P, as measured at the last match
+	S float64
+	// Number of frames processed
+	Frame int
+	// Number of consecutive frames in which the object was not found
+	Misses int
+}
+
+// Returns a Tracker for object, searching margin pixels around the predicted
+// position in each frame. The object's initial position is unknown, so the
+// first frame is searched entirely; see also Start.
+func NewTracker(object image.Image, margin int, opts Options) *Tracker {
+	return &Tracker{
+		Object:  NewObject(object),
+		Options: opts,
+		Margin:  margin,
+	}
+}
+
+// Sets the object's current position, e.g. from a Hit found by Search, and
+// resets its velocity
+func (t *Tracker) Start(p image.Point) {
+	t.Track = Track{P: p, M: p}
+	t.startKalman(p)
+	t.started = true
+}
+
+// Returns the predicted position of the object in the next frame
+func (t *Tracker) Predict() image.Point {
+	if t.Kalman && t.started {
+		return roundPoint(t.kx.x+t.kx.v, t.ky.x+t.ky.v)
+	}
+	return roundPoint(float64(t.Track.P.X)+t.Track.Vx, float64(t.Track.P.Y)+t.Track.Vy)
+}
+
+// Searches frame for the object and updates the track. The match is the
+// position of least mean absolute per-pixel difference in the search
+// window, whatever t.Options.ScoreMode and HitMode. Returns the updated
+// track, and whether the object was found. If it was not, the track's
+// position continues along its last velocity.
+func (t *Tracker) Update(frame image.Image) (Track, bool) {
+	t.Track.Frame++
+	valid := validRect(frame.Bounds(), t.Object.Bounds())
+	rect := valid
+	prev := t.Track.P
+	if t.started {
+		pred := t.Predict()
+		mx, my := t.Margin, t.Margin
+		if t.Kalman {
+			t.kx.predict(t.ProcessNoise)
+			t.ky.predict(t.ProcessNoise)
+			// search two standard deviations beyond the margin
+			mx += int(math.Ceil(2 * math.Sqrt(t.kx.p[0][0])))
+			my += int(math.Ceil(2 * math.Sqrt(t.ky.p[0][0])))
+		}
+		rect = image.Rect(pred.X-mx, pred.Y-my, pred.X+mx+1, pred.Y+my+1).Intersect(valid)
+		t.Track.P = pred
+	}
+	// convert frame only once
+	field := NewField(frame)
+	p, d, ok := bestMatch(field, t.Object, rect, t.Options)
+	if !ok || (t.MaxDistance != 0 && d > t.MaxDistance) {
+		// object not found, continue along predicted path
+		t.Track.Misses++
+		return t.Track, false
+	}
+	switch {
+	case t.Kalman && t.started:
+		t.kx.update(float64(p.X), t.MeasurementNoise)
+		t.ky.update(float64(p.Y), t.MeasurementNoise)
+		t.Track.P = roundPoint(t.kx.x, t.ky.x)
+		t.Track.Vx, t.Track.Vy = t.kx.v, t.ky.v
+	case t.Kalman:
+		t.startKalman(p)
+		t.Track.P = p
+	case t.started:
+		t.Track.Vx = float64(p.X - prev.X)
+		t.Track.Vy = float64(p.Y - prev.Y)
+		t.Track.P = p
+	default:
+		t.Track.P = p
+	}
+	t.Track.M, t.Track.S, t.Track.Misses = p, d, 0
+	t.started = true
+	if t.LearningRate != 0 {
+		t.adapt(field, p)
+	}
+	if t.Record {
+		t.Trajectory = append(t.Trajectory, trajectoryPoint(t.Track.Frame-1, t.Track))
+	}
+	return t.Track, true
+}
+
+// blend the window of field at p into t.Object, unless doing so exceeds
+// t.MaxDrift
+func (t *Tracker) adapt(field *Field, p image.Point) {
+	if t.original == nil {
+		t.original = t.Object
+		t.template = make([]float64, len(t.Object.opaque.Pix))
+		for i, v := range t.Object.opaque.Pix {
+			t.template[i] = float64(v)
+		}
+	}
+	o := t.original.opaque
+	template := make([]float64, len(t.template))
+	// drift is weighted by the object's alpha, as transparent pixels take on
+	// the background
+	drift, weight := 0.0, 0.0
+	for y := o.Rect.Min.Y; y < o.Rect.Max.Y; y++ {
+		for x := o.Rect.Min.X; x < o.Rect.Max.X; x++ {
+			i, j := o.PixOffset(x, y), field.PixOffset(x+p.X, y+p.Y)
+			w := 1.0
+			if a := t.original.alpha; a != nil {
+				w = float64(a.AlphaAt(x, y).A) / 255
+			}
+			for c := 0; c < 3; c++ {
+				template[i+c] = (1-t.LearningRate)*t.template[i+c] + t.LearningRate*float64(field.Pix[j+c])
+				drift += w * math.Abs(template[i+c]-float64(o.Pix[i+c]))
+				weight += w
+			}
+			template[i+3] = 255
+		}
+	}
+	if t.MaxDrift != 0 && weight > 0 && drift/weight/255 > t.MaxDrift {
+		return
+	}
+	t.template = template
+	adapted := image.NewRGBA(o.Rect)
+	for i, v := range template {
+		adapted.Pix[i] = uint8(math.Round(v))
+	}
+	t.Object = &Object{Image: adapted, opaque: adapted, alpha: t.original.alpha}
+}
+
+// reset the Kalman filters to position p. The initial velocity is
+// uncertain, by about Margin pixels per frame.
+func (t *Tracker) startKalman(p image.Point) {
+	vv := float64(t.Margin * t.Margin)
+	t.kx = newKalman1(float64(p.X), t.MeasurementNoise, vv)
+	t.ky = newKalman1(float64(p.Y), t.MeasurementNoise, vv)
+}
+
+// return (x,y) rounded to the nearest integer point
+func roundPoint(x, y float64) image.Point {
+	return image.Point{int(math.Round(x)), int(math.Round(y))}
+}
+
+// return the position in rect where object best matches field, and the mean
+// absolute per-pixel difference there. ok is false if the object cannot be
+// scored at any position in rect.
+func bestMatch(field, object image.Image, rect image.Rectangle, opts Options) (p image.Point, d float64, ok bool) {
+	ctx, dist := searchDistances(field, object, rect, opts)
+	if len(dist) == 0 {
+		return
+	}
+	best := 0
+	for i := range dist {
+		if dist[i] < dist[best] {
+			best = i
+		}
+	}
+	x, y := ctx.coords(best)
+	return image.Point{x, y}, dist[best], true
+}
+
+// return a search context for field and object, and the combined distances
+// between them at each point of rect
+func searchDistances(field, object image.Image, rect image.Rectangle, opts Options) (objSearchContext, []float64) {
+	ctx, fieldPlanes, objectPlanes := newImageContext(field, object, rect, opts)
+	if ctx.SearchRect.Empty() {
+		return ctx, nil
+	}
+	return ctx, ctx.distances(fieldPlanes, objectPlanes)
+}
@@ -0,0 +1,136 @@
+package objsearch
+
+import (
+	"image"
+	"image/draw"
+	"testing"
+)
+
+// return a random background with object drawn at p
+func frameWithObject(bg, object *image.RGBA, p image.Point) *image.RGBA {
+	f := image.NewRGBA(bg.Rect)
+	copy(f.Pix, bg.Pix)
+	draw.Draw(f, object.Rect.Add(p), object, image.ZP, draw.Src)
+	return f
+}
+
+func TestTracker(t *testing.T) {
+	bg := randomRGBImage(100, 100)
+	object := randomRGBImage(8, 8)
+	tr := NewTracker(object, 6, Options{})
+	tr.MaxDistance = 0.05
+	p := image.Point{10, 20}
+	for i := 0; i < 20; i++ {
+		track, ok := tr.Update(frameWithObject(bg, object, p))
+		if !ok || track.P != p {
+			t.Fatal("tracking error", i, track, p)
+		}
+		if i > 0 && (track.Vx != 3 || track.Vy != 2) {
+			t.Fatal("velocity error", track)
+		}
+		p = p.Add(image.Point{3, 2})
+	}
+	// object disappears
+	track, ok := tr.Update(bg)
+	if ok || track.Misses != 1 || track.P != p {
+		t.Fatal("expected miss", track)
+	}
+}
+
+// test that a Kalman-filtered track survives a brief occlusion
+func TestTrackerKalman(t *testing.T) {
+	bg := randomRGBImage(160, 100)
+	object := randomRGBImage(8, 8)
+	tr := NewTracker(object, 3, Options{})
+	tr.MaxDistance = 0.05
+	tr.Kalman = true
+	tr.ProcessNoise = 0.01
+	tr.MeasurementNoise = 0.25
+	p := image.Point{5, 40}
+	tr.Start(p)
+	for i := 0; i < 30; i++ {
+		p = p.Add(image.Point{4, 0})
+		frame := bg
+		if i < 20 || i > 22 {
+			frame = frameWithObject(bg, object, p)
+		}
+		track, ok := tr.Update(frame)
+		if i >= 20 && i <= 22 {
+			if ok {
+				t.Fatal("expected miss during occlusion", i)
+			}
+			continue
+		}
+		if !ok {
+			t.Fatal("tracking error", i, track, p)
+		}
+		if i > 10 && (track.M != p || track.P.Sub(p).X*track.P.Sub(p).X > 1) {
+			t.Fatal("Kalman tracking error", i, track, p)
+		}
+	}
+}
+
+// test that the Kalman filter works with zero noise variances
+func TestTrackerKalmanDefaults(t *testing.T) {
+	bg := randomRGBImage(100, 100)
+	object := randomRGBImage(8, 8)
+	tr := NewTracker(object, 5, Options{})
+	tr.Kalman = true
+	p := image.Point{30, 40}
+	frame := frameWithObject(bg, object, p)
+	tr.Start(p)
+	for i := 0; i < 5; i++ {
+		track, ok := tr.Update(frame)
+		if !ok || track.P != p || track.M != p || track.Vx != 0 || track.Vy != 0 {
+			t.Fatal("Kalman tracking error", i, track)
+		}
+	}
+}
+
+func TestTrackerAdaptation(t *testing.T) {
+	bg := randomRGBImage(100, 100)
+	object := randomRGBImage(8, 8)
+	run := func(rate float64) (found int) {
+		tr := NewTracker(object, 4, Options{})
+		tr.MaxDistance = 0.05
+		tr.LearningRate = rate
+		tr.MaxDrift = 0.5
+		tr.Start(image.Point{10, 20})
+		o := crop(object, object.Rect)
+		for i := 1; i <= 30; i++ {
+			// the object brightens gradually
+			for j := range o.Pix {
+				if j%4 != 3 && o.Pix[j] < 250 {
+					o.Pix[j] += 5
+				}
+			}
+			if _, ok := tr.Update(frameWithObject(bg, o, image.Point{10 + i, 20})); ok {
+				found++
+			}
+		}
+		return
+	}
+	if n := run(0); n == 30 {
+		t.Fatal("expected fixed template to lose the object")
+	}
+	if n := run(0.5); n != 30 {
+		t.Fatal("adapted template lost the object", n)
+	}
+}
+
+// test that the transparent pixels of the object, which take on the
+// background, do not count towards MaxDrift
+func TestTrackerAdaptationAlpha(t *testing.T) {
+	bg := randomRGBImage(60, 60)
+	inner := randomRGBImage(8, 8)
+	object := image.NewNRGBA(image.Rect(0, 0, 12, 12))
+	draw.Draw(object, image.Rect(2, 2, 10, 10), inner, image.ZP, draw.Src)
+	p := image.Point{20, 30}
+	tr := NewTracker(object, 4, Options{})
+	tr.LearningRate, tr.MaxDrift = 1, 0.05
+	tr.Start(p)
+	original := tr.Object
+	if _, ok := tr.Update(frameWithObject(bg, inner, p.Add(image.Point{2, 2}))); !ok || tr.Object == original {
+		t.Fatal("adaptation refused for a drift in transparent pixels")
+	}
+}
@@ -0,0 +1,94 @@
+package objsearch
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"image"
+	"io"
+	"math"
+	"strconv"
+)
+
+// A position of a tracked object in one frame
+type TrajectoryPoint struct {
+	// Index of the frame, counting from 0
+	Frame int
+	// Matched position of the top-left corner of the object
+	P image.Point
+	// Mean absolute per-pixel difference between the object and the frame
+	// at P
+	S float64
+}
+
+// The recorded positions of a tracked object, in frame order. Frames in which
+// the object was not found are omitted.
+type Trajectory []TrajectoryPoint
+
+// Returns the total Euclidean distance in pixels travelled along t
+func (t Trajectory) PathLength() (l float64) {
+	for i := 1; i < len(t); i++ {
+		d := t[i].P.Sub(t[i-1].P)
+		l += math.Hypot(float64(d.X), float64(d.Y))
+	}
+	return
+}
+
+// Returns the number of recorded frames in which the object was inside r
+func (t Trajectory) Dwell(r image.Rectangle) (n int) {
+	for _, p := range t {
+		if p.P.In(r) {
+			n++
+		}
+	}
+	return
+}
+
+// the JSON representation of a TrajectoryPoint
+type jsonTrajectoryPoint struct {
+	Frame int     `json:"frame"`
+	X     int     `json:"x"`
+	Y     int     `json:"y"`
+	Score float64 `json:"score"`
+}
+
+func (p TrajectoryPoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonTrajectoryPoint{p.Frame, p.P.X, p.P.Y, p.S})
+}
+
+func (p *TrajectoryPoint) UnmarshalJSON(b []byte) error {
+	j := jsonTrajectoryPoint{}
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	*p = TrajectoryPoint{j.Frame, image.Point{j.X, j.Y}, j.Score}
+	return nil
+}
+
+// Writes t to w as a JSON array of {"frame","x","y","score"} objects
+func (t Trajectory) WriteJSON(w io.Writer) error {
+	if t == nil {
+		t = Trajectory{}
+	}
+	return json.NewEncoder(w).Encode(t)
+}
+
+// Writes t to w as CSV, with a header row "frame,x,y,score"
+func (t Trajectory) WriteCSV(w io.Writer) error {
+	c := csv.NewWriter(w)
+	c.Write([]string{"frame", "x", "y", "score"})
+	for _, p := range t {
+		c.Write([]string{
+			strconv.Itoa(p.Frame),
+			strconv.Itoa(p.P.X),
+			strconv.Itoa(p.P.Y),
+			strconv.FormatFloat(p.S, 'g', -1, 64),
+		})
+	}
+	c.Flush()
+	return c.Error()
+}
+
+// return the trajectory point recording track in frame, counting from 0
+func trajectoryPoint(frame int, track Track) TrajectoryPoint {
+	return TrajectoryPoint{frame, track.M, track.S}
+}
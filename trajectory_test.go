@@ -0,0 +1,45 @@
+package objsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"testing"
+)
+
+func TestTrajectory(t *testing.T) {
+	bg := randomRGBImage(60, 60)
+	object := randomRGBImage(8, 8)
+	tr := NewTracker(object, 5, Options{})
+	tr.Record = true
+	p := image.Point{0, 10}
+	for i := 0; i < 10; i++ {
+		tr.Update(frameWithObject(bg, object, p))
+		p = p.Add(image.Point{3, 4})
+	}
+	traj := tr.Trajectory
+	if len(traj) != 10 || traj[9].Frame != 9 || traj[9].P != (image.Point{27, 46}) {
+		t.Fatal("trajectory recording error", traj)
+	}
+	if traj.PathLength() != 45 {
+		t.Fatal("PathLength error", traj.PathLength())
+	}
+	if n := traj.Dwell(image.Rect(0, 0, 10, 30)); n != 4 {
+		t.Fatal("Dwell error", n)
+	}
+	buf := &bytes.Buffer{}
+	if err := traj.WriteJSON(buf); err != nil {
+		t.Fatal(err)
+	}
+	decoded := Trajectory{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded) != 10 || decoded[3] != traj[3] {
+		t.Fatal("JSON round trip error", err, decoded)
+	}
+	buf.Reset()
+	if err := traj[:1].WriteCSV(buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "frame,x,y,score\n0,0,10,0\n" {
+		t.Fatal("CSV error", buf.String())
+	}
+}
@@ -0,0 +1,132 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+	"sync"
+)
+
+// Parameters of SearchTwoStage
+type TwoStageOptions struct {
+	// Options of the verification stage, which scores candidates according
+	// to ScoreMode, and must be SCOREMODE_SQDIFF_NORMED or
+	// SCOREMODE_CCOEFF_NORMED. Hits are found among the verified scores
+	// according to Tolerance and MinDist.
+	Options
+	// The candidate stage compares every SampleStep-th object pixel in each
+	// dimension. Zero is treated as 4.
+	SampleStep int
+	// Number of candidates, the best local minima of the sampled distances,
+	// that are verified. Zero is treated as 64.
+	Candidates int
+}
+
+// Like SearchImage, scanning field with a cheap sampled L1 distance to find
+// candidate positions, then scoring only the windows around the candidates
+// with opts.ScoreMode. This finds the hits a full scan with opts.ScoreMode
+// would, at a fraction of the cost, provided every hit is among the best
+// opts.Candidates local minima of the sampled distances.
+func SearchTwoStage(field, object image.Image, rect image.Rectangle, opts TwoStageOptions) []Hit {
+	if opts.ScoreMode != SCOREMODE_SQDIFF_NORMED && opts.ScoreMode != SCOREMODE_CCOEFF_NORMED {
+		panic("two-stage search requires an absolute score mode")
+	}
+	step, candidates := opts.SampleStep, opts.Candidates
+	if step <= 0 {
+		step = 4
+	}
+	if candidates <= 0 {
+		candidates = 64
+	}
+	f, o := NewField(field), NewObject(object)
+	ctx, fieldPlanes, objectPlanes := newImageContext(f, o, rect, opts.Options)
+	if ctx.SearchRect.Empty() {
+		return nil
+	}
+	// find candidates among the sampled distances
+	sampled := ctx
+	sampled.ScoreMode, sampled.K, sampled.Contrast = SCOREMODE_L1, candidates, nil
+	sampled.MinDist = step
+	cands := sampled.bestKHits(sampled.sampledDistances(fieldPlanes, objectPlanes, step))
+	// verify each candidate's neighborhood, which holds the true minimum if
+	// the sampled distances are smooth on the scale of the sampling
+	var hits []Hit
+	r := step / 2
+	for _, c := range cands {
+		window := image.Rect(c.P.X-r, c.P.Y-r, c.P.X+r+1, c.P.Y+r+1).Intersect(ctx.SearchRect)
+		verify := ctx
+		verify.SearchRect = window
+		scores := verify.cvScores(fieldPlanes, objectPlanes)
+		best := 0
+		for i := range scores {
+			if verify.better(scores[i], scores[best]) {
+				best = i
+			}
+		}
+		if ctx.better(scores[best], ctx.Tolerance) {
+			x, y := verify.coords(best)
+			hits = append(hits, Hit{image.Point{x, y}, scores[best]})
+		}
+	}
+	return ctx.suppress(hits)
+}
+
+// return the L1 distance between field and object at each point of
+// ctx.SearchRect, comparing only every step-th object pixel in each dimension
+// and combining planes according to ctx.CombineMode
+func (ctx objSearchContext) sampledDistances(field, object []*FloatImage, step int) []float64 {
+	type sample struct {
+		x, y int
+		w    float64
+	}
+	r := object[0].Rect
+	var samples []sample
+	totalWeight := 0.0
+	for y := r.Min.Y; y < r.Max.Y; y += step {
+		for x := r.Min.X; x < r.Max.X; x += step {
+			w := 1.0
+			if ctx.Mask != nil {
+				w = float64(ctx.Mask.AlphaAt(x, y).A) / 255
+			}
+			if w != 0 {
+				samples = append(samples, sample{x, y, w})
+				totalWeight += w
+			}
+		}
+	}
+	if totalWeight == 0 {
+		panic("no opaque object pixels sampled")
+	}
+	dist := make([]float64, ctx.SearchRect.Dx()*ctx.SearchRect.Dy())
+	wg := sync.WaitGroup{}
+	for v := ctx.SearchRect.Min.Y; v < ctx.SearchRect.Max.Y; v++ {
+		wg.Add(1)
+		go func(v int) {
+			for u := ctx.SearchRect.Min.X; u < ctx.SearchRect.Max.X; u++ {
+				d := 0.0
+				for c := range field {
+					dc := 0.0
+					for _, s := range samples {
+						fv := field[c].Pix[field[c].PixOffset(u+s.x, v+s.y)]
+						ov := object[c].Pix[object[c].PixOffset(s.x, s.y)]
+						dc += s.w * math.Abs(fv-ov)
+					}
+					if ctx.ChannelWeights != nil {
+						dc *= ctx.ChannelWeights[c]
+					}
+					if ctx.CombineMode == COMBINEMODE_MAX {
+						d = math.Max(d, dc)
+					} else {
+						d += dc
+					}
+				}
+				if ctx.CombineMode == COMBINEMODE_MEAN {
+					d /= ctx.channelWeight(len(field))
+				}
+				dist[ctx.offset(u, v)] = d / totalWeight
+			}
+			wg.Done()
+		}(v)
+	}
+	wg.Wait()
+	return dist
+}
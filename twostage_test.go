@@ -0,0 +1,34 @@
+package objsearch
+
+import (
+	"image"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSearchTwoStage(t *testing.T) {
+	object := randomRGBImage(12, 12)
+	field := randomRGBImage(100, 80)
+	for _, p := range []image.Point{{10, 10}, {60, 50}, {80, 5}} {
+		field = frameWithObject(field, object, p)
+	}
+	rect := validRect(field.Rect, object.Rect)
+	opts := Options{Tolerance: 0.8, MinDist: 10, ScoreMode: SCOREMODE_CCOEFF_NORMED, ColorMode: COLORMODE_RGB}
+	want := SearchImage(field, object, rect, opts)
+	got := SearchTwoStage(field, object, rect, TwoStageOptions{Options: opts})
+	// perfect matches tie, in any order
+	SortRaster(want)
+	SortRaster(got)
+	if len(want) != 3 || !reflect.DeepEqual(got, want) {
+		t.Fatal("two-stage hits differ from full scan", got, want)
+	}
+	// sampled distances are exact with a step of 1
+	ctx, fp, op := newImageContext(field, object, rect, opts)
+	sampled, full := ctx.sampledDistances(fp, op, 1), ctx.distances(fp, op)
+	for i := range full {
+		if math.Abs(sampled[i]-full[i]) > 1e-9 {
+			t.Fatal("sampled distances error", i, sampled[i], full[i])
+		}
+	}
+}
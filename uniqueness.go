@@ -0,0 +1,62 @@
+package objsearch
+
+import "image"
+
+// The result of AnalyzeUniqueness
+type Uniqueness struct {
+	// Position and mean absolute per-pixel difference of the best match
+	Match         image.Point
+	MatchDistance float64
+	// Position and mean absolute per-pixel difference of the best match
+	// whose window does not overlap that of Match
+	Background         image.Point
+	BackgroundDistance float64
+	// BackgroundDistance - MatchDistance. The smaller the gap, the more
+	// likely the object is to produce false positives.
+	Gap float64
+	// True if Gap is below the minGap given to AnalyzeUniqueness
+	Generic bool
+}
+
+// Searches field, which should be a representative field containing object,
+// for object and reports how much better the best (true) match is than the
+// best match elsewhere in the field. If the gap is below minGap, the object
+// is flagged as too generic, and a warning is written to opts.VerboseOut.
+//
+// If object appears nowhere in field except in overlapping windows, the
+// background is taken to be the field itself, and BackgroundDistance is 1.
+func AnalyzeUniqueness(field, object image.Image, minGap float64, opts Options) (u Uniqueness) {
+	rect := validRect(field.Bounds(), object.Bounds())
+	if rect.Empty() {
+		panic("object does not fit within field")
+	}
+	ctx, dist := searchDistances(field, object, rect, opts)
+	best := 0
+	for i := range dist {
+		if dist[i] < dist[best] {
+			best = i
+		}
+	}
+	x, y := ctx.coords(best)
+	u.Match, u.MatchDistance = image.Point{x, y}, dist[best]
+	size := object.Bounds().Size()
+	u.BackgroundDistance = 1
+	for i := range dist {
+		x, y := ctx.coords(i)
+		dx, dy := x-u.Match.X, y-u.Match.Y
+		if dx > -size.X && dx < size.X && dy > -size.Y && dy < size.Y {
+			// overlaps the true match
+			continue
+		}
+		if dist[i] < u.BackgroundDistance {
+			u.Background, u.BackgroundDistance = image.Point{x, y}, dist[i]
+		}
+	}
+	u.Gap = u.BackgroundDistance - u.MatchDistance
+	if u.Gap < minGap {
+		u.Generic = true
+		ctx.verboseOut("object is too generic: match at %v differs by %f, background at %v by %f\n",
+			u.Match, u.MatchDistance, u.Background, u.BackgroundDistance)
+	}
+	return
+}
@@ -0,0 +1,29 @@
+package objsearch
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestAnalyzeUniqueness(t *testing.T) {
+	object := randomRGBImage(8, 8)
+	field := frameWithObject(randomRGBImage(50, 50), object, image.Point{20, 10})
+	u := AnalyzeUniqueness(field, object, 0.1, Options{})
+	if u.Match != (image.Point{20, 10}) || u.MatchDistance != 0 || u.Generic {
+		t.Fatal("unique object analysis error", u)
+	}
+	if u.Gap != u.BackgroundDistance || u.Gap < 0.1 {
+		t.Fatal("gap error", u)
+	}
+	// a flat object matches a flat field anywhere
+	flat := image.NewRGBA(image.Rect(0, 0, 50, 50))
+	draw.Draw(flat, flat.Rect, image.NewUniform(color.RGBA{10, 200, 30, 255}), image.Point{}, draw.Src)
+	out := &bytes.Buffer{}
+	u = AnalyzeUniqueness(flat, crop(flat, image.Rect(0, 0, 8, 8)), 0.1, Options{VerboseOut: out})
+	if !u.Generic || u.Gap != 0 || out.Len() == 0 {
+		t.Fatal("generic object analysis error", u)
+	}
+}
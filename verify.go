@@ -0,0 +1,33 @@
+package objsearch
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Discards the hits of object in field whose correspondence with object is
+// not mutual. source is the image object was cut from, or any image holding
+// object and its surroundings, with object's top-left corner at offset.
+//
+// Each hit's window is copied from field and searched for in source by mean
+// absolute per-pixel difference, with opts' color options. The hit is kept
+// only if the window matches best within tol pixels of offset, as measured by
+// Hit.Distance. Unlike the forward search, the reverse search compares every
+// pixel of the window, including those under transparent or masked object
+// pixels, so a window that matched only because the object ignores part of it
+// is discarded if source holds a better match for it, such as a look-alike
+// elsewhere on the same screen.
+func VerifyReverse(field, object image.Image, hits []Hit, source image.Image, offset image.Point, tol int, opts Options) []Hit {
+	b := object.Bounds()
+	// the window's pixels align with source at this position if it matches
+	// the object
+	want := Hit{P: offset.Sub(b.Min)}
+	rect := validRect(source.Bounds(), b)
+	opts.Mask, opts.ColorKey = nil, nil
+	return FilterHits(hits, func(h Hit) bool {
+		window := image.NewRGBA(b)
+		draw.Draw(window, b, field, b.Min.Add(h.P), draw.Src)
+		p, _, ok := bestMatch(source, window, rect, opts)
+		return ok && (Hit{P: p}).Distance(want) <= tol
+	})
+}
@@ -0,0 +1,36 @@
+package objsearch
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestVerifyReverse(t *testing.T) {
+	// a button, and its disabled twin that differs only in its center
+	button := randomRGBImage(12, 12)
+	twin := crop(button, button.Rect)
+	for y := 4; y < 8; y++ {
+		for x := 4; x < 8; x++ {
+			twin.SetRGBA(x, y, color.RGBA{0, 0, 0, 255})
+		}
+	}
+	source := frameWithObject(frameWithObject(randomRGBImage(60, 40), button, image.Point{5, 5}), twin, image.Point{40, 20})
+	// the object ignores the center, so it matches both
+	object := crop(button, button.Rect)
+	for y := 4; y < 8; y++ {
+		for x := 4; x < 8; x++ {
+			object.SetRGBA(x, y, color.RGBA{})
+		}
+	}
+	field := frameWithObject(frameWithObject(randomRGBImage(80, 60), button, image.Point{10, 30}), twin, image.Point{50, 10})
+	opts := Options{Tolerance: 0.05, MinDist: 10}
+	hits := SearchImage(field, object, validRect(field.Rect, object.Rect), opts)
+	if len(hits) != 2 {
+		t.Fatal("expected both buttons to match", hits)
+	}
+	verified := VerifyReverse(field, object, hits, source, image.Point{5, 5}, 1, opts)
+	if len(verified) != 1 || verified[0].P != (image.Point{10, 30}) {
+		t.Fatal("reverse verification error", verified)
+	}
+}
@@ -0,0 +1,185 @@
+// Package video provides an objsearch.FrameSource decoding video files and
+// streams with ffmpeg, so that recordings can be searched and tracked without
+// first splitting them into images.
+//
+// The ffmpeg and ffprobe executables must be installed and in the PATH.
+package video
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hypoactiv/objsearch"
+)
+
+var _ objsearch.FrameSource = (*Source)(nil)
+
+// Options controlling how a video is decoded
+type Options struct {
+	// Start decoding at this offset into the video
+	Seek time.Duration
+	// Decode only every (Skip+1)-th frame
+	Skip int
+	// Frame times are reported relative to Start. The zero value reports
+	// times as offsets from the zero time.
+	Start time.Time
+}
+
+// A FrameSource decoding a video with ffmpeg. Frames are *image.RGBA.
+type Source struct {
+	// Dimensions of the video
+	Width, Height int
+	// Frame rate of the video, in frames per second
+	FrameRate float64
+	opts      Options
+	cmd       *exec.Cmd
+	out       io.ReadCloser
+	r         *bufio.Reader
+	// ffmpeg's standard error output
+	stderr bytes.Buffer
+	// whether ffmpeg has been waited for, and the error it exited with
+	waited bool
+	err    error
+	// index of the next frame decoded, counting from the seek position
+	index int
+}
+
+// Opens the video file or stream URL at path
+func Open(path string, opts Options) (*Source, error) {
+	s := &Source{opts: opts}
+	if err := s.probe(path); err != nil {
+		return nil, err
+	}
+	args := []string{"-v", "error"}
+	if opts.Seek > 0 {
+		args = append(args, "-ss", strconv.FormatFloat(opts.Seek.Seconds(), 'f', -1, 64))
+	}
+	args = append(args, "-i", path)
+	if opts.Skip > 0 {
+		args = append(args, "-vf", fmt.Sprintf("framestep=%d", opts.Skip+1))
+	}
+	args = append(args, "-f", "rawvideo", "-pix_fmt", "rgba", "-")
+	if err := s.start(exec.Command("ffmpeg", args...)); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// start cmd, which writes raw RGBA frames of s's dimensions to its standard
+// output
+func (s *Source) start(cmd *exec.Cmd) error {
+	s.cmd = cmd
+	s.cmd.Stderr = &s.stderr
+	out, err := s.cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := s.cmd.Start(); err != nil {
+		return err
+	}
+	s.out = out
+	s.r = bufio.NewReaderSize(out, s.Width*s.Height*4)
+	return nil
+}
+
+// wait for ffmpeg to exit, if it has not been waited for, and return the
+// error it exited with, including its standard error output
+func (s *Source) wait() error {
+	if !s.waited {
+		s.waited = true
+		if err := s.cmd.Wait(); err != nil {
+			s.err = fmt.Errorf("ffmpeg: %w: %s", err, strings.TrimSpace(s.stderr.String()))
+		}
+	}
+	return s.err
+}
+
+// determine the dimensions and frame rate of the video at path with ffprobe
+func (s *Source) probe(path string) error {
+	out, err := exec.Command("ffprobe", "-v", "error", "-select_streams", "v:0",
+		"-show_entries", "stream=width,height,r_frame_rate", "-of", "csv=p=0", path).Output()
+	if err != nil {
+		return fmt.Errorf("ffprobe: %v", err)
+	}
+	fields := strings.Split(strings.TrimSpace(string(bytes.SplitN(out, []byte("\n"), 2)[0])), ",")
+	if len(fields) != 3 {
+		return errors.New("ffprobe: no video stream")
+	}
+	if s.Width, err = strconv.Atoi(fields[0]); err != nil {
+		return fmt.Errorf("ffprobe: invalid width: %v", err)
+	}
+	if s.Height, err = strconv.Atoi(fields[1]); err != nil {
+		return fmt.Errorf("ffprobe: invalid height: %v", err)
+	}
+	if s.FrameRate, err = parseRate(fields[2]); err != nil {
+		return fmt.Errorf("ffprobe: invalid frame rate: %v", err)
+	}
+	return nil
+}
+
+// parse a frame rate such as "30000/1001" or "25"
+func parseRate(r string) (float64, error) {
+	num, den := r, "1"
+	if i := strings.IndexByte(r, '/'); i >= 0 {
+		num, den = r[:i], r[i+1:]
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, err
+	}
+	d, err := strconv.ParseFloat(den, 64)
+	if err != nil {
+		return 0, err
+	}
+	if n <= 0 || d <= 0 {
+		return 0, errors.New("non-positive frame rate")
+	}
+	return n / d, nil
+}
+
+// Returns the next decoded frame and its time. Returns io.EOF at the end of
+// the video, or an error holding ffmpeg's error output if ffmpeg failed or
+// the video ended in the middle of a frame.
+func (s *Source) Next() (image.Image, time.Time, error) {
+	img := image.NewRGBA(image.Rect(0, 0, s.Width, s.Height))
+	if _, err := io.ReadFull(s.r, img.Pix); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			if werr := s.wait(); werr != nil {
+				return nil, time.Time{}, werr
+			}
+		}
+		if err == io.ErrUnexpectedEOF {
+			err = fmt.Errorf("ffmpeg: video ended in the middle of a frame: %s", strings.TrimSpace(s.stderr.String()))
+		}
+		return nil, time.Time{}, err
+	}
+	// position of this frame in the video
+	frames := float64(s.index * (s.opts.Skip + 1))
+	offset := s.opts.Seek + time.Duration(frames/s.FrameRate*float64(time.Second))
+	s.index++
+	return img, s.opts.Start.Add(offset), nil
+}
+
+// Stops decoding and releases the ffmpeg process. Returns the error ffmpeg
+// exited with, as Next does, unless it was still decoding.
+func (s *Source) Close() error {
+	if s.waited {
+		return s.err
+	}
+	s.cmd.Process.Kill()
+	s.out.Close()
+	err := s.wait()
+	if !s.cmd.ProcessState.Exited() {
+		// killed above, rather than failed
+		return nil
+	}
+	return err
+}
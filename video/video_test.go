@@ -0,0 +1,73 @@
+package video
+
+import (
+	"errors"
+	"image"
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestParseRate(t *testing.T) {
+	for r, want := range map[string]float64{"25": 25, "30000/1001": 30000.0 / 1001, "60/1": 60} {
+		if got, err := parseRate(r); err != nil || got != want {
+			t.Fatal("parseRate error", r, got, err)
+		}
+	}
+	for _, r := range []string{"", "0/0", "x/1"} {
+		if _, err := parseRate(r); err == nil {
+			t.Fatal("expected parseRate error", r)
+		}
+	}
+}
+
+// return a Source of 1x1 frames read from the output of the shell command
+// script
+func shellSource(t *testing.T, script string) *Source {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no shell")
+	}
+	s := &Source{Width: 1, Height: 1, FrameRate: 1}
+	if err := s.start(exec.Command("sh", "-c", script)); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSourceErrors(t *testing.T) {
+	// a clean end
+	s := shellSource(t, "printf abcd")
+	if img, _, err := s.Next(); err != nil || img.(*image.RGBA).Pix[0] != 'a' {
+		t.Fatal("Next error", err)
+	}
+	if _, _, err := s.Next(); err != io.EOF {
+		t.Fatal("expected EOF", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal("Close error", err)
+	}
+	// ffmpeg fails
+	s = shellSource(t, "printf abcd; echo bad input >&2; exit 3")
+	s.Next()
+	_, _, err := s.Next()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || !strings.Contains(err.Error(), "bad input") {
+		t.Fatal("expected exit error", err)
+	}
+	if s.Close() != err {
+		t.Fatal("Close error", s.Close())
+	}
+	// the video ends in the middle of a frame
+	s = shellSource(t, "printf abcdab")
+	s.Next()
+	if _, _, err := s.Next(); err == nil || err == io.EOF {
+		t.Fatal("expected short frame error", err)
+	}
+	// decoding is stopped
+	s = shellSource(t, "printf abcd; exec sleep 10")
+	s.Next()
+	if err := s.Close(); err != nil {
+		t.Fatal("Close error", err)
+	}
+}
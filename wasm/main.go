@@ -0,0 +1,154 @@
+//go:build js && wasm
+
+// Command wasm exposes objsearch to JavaScript, for template matching in the
+// browser without sending images to a server. Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o objsearch.wasm ./wasm
+//
+// and load it with the wasm_exec.js shipped with Go. It defines a global
+// objsearch object with one function:
+//
+//	objsearch.search(field, templates, options, onProgress) -> Promise
+//
+// field is an ImageData, e.g. from CanvasRenderingContext2D.getImageData,
+// and templates is an array of {label, image} objects whose images are
+// ImageData. options is an optional object with any of the properties
+// tolerance, mindist, color ("gray" or "rgb") and combine ("max" or "mean").
+// onProgress, if given, is called with the fraction of the search completed.
+// The promise resolves to an array of {label, x, y, score} hits.
+//
+// The search yields to the event loop between bands of rows, so that pages
+// stay responsive during long searches.
+package main
+
+import (
+	"errors"
+	"image"
+	"syscall/js"
+
+	"github.com/hypoactiv/objsearch"
+)
+
+func main() {
+	js.Global().Set("objsearch", js.ValueOf(map[string]interface{}{
+		"search": js.FuncOf(search),
+	}))
+	// keep the exported functions alive
+	select {}
+}
+
+// the JavaScript objsearch.search function
+func search(this js.Value, args []js.Value) interface{} {
+	promise := js.Global().Get("Promise")
+	return promise.New(js.FuncOf(func(_ js.Value, cb []js.Value) interface{} {
+		resolve, reject := cb[0], cb[1]
+		go func() {
+			hits, err := runSearch(args)
+			if err != nil {
+				reject.Invoke(js.Global().Get("Error").New(err.Error()))
+				return
+			}
+			resolve.Invoke(js.ValueOf(hits))
+		}()
+		return nil
+	}))
+}
+
+// perform a search with the arguments of objsearch.search, and return the
+// hits as JavaScript objects
+func runSearch(args []js.Value) ([]interface{}, error) {
+	if len(args) < 2 {
+		return nil, errors.New("objsearch.search: expected field and templates")
+	}
+	opts, err := options(arg(args, 2))
+	if err != nil {
+		return nil, err
+	}
+	field := objsearch.NewField(imageData(args[0]))
+	set := objsearch.NewTemplateSet(opts)
+	for i := 0; i < args[1].Length(); i++ {
+		t := args[1].Index(i)
+		set.Add(t.Get("label").String(), imageData(t.Get("image")))
+	}
+	onProgress := arg(args, 3)
+	labels := set.Labels()
+	hits := []interface{}{}
+	for i, label := range labels {
+		progress := func(f float64) {
+			if onProgress.Type() == js.TypeFunction {
+				onProgress.Invoke((float64(i) + f) / float64(len(labels)))
+			}
+			yield()
+		}
+		for _, h := range set.Searcher(label).SearchWithProgress(field, progress) {
+			hits = append(hits, map[string]interface{}{
+				"label": label,
+				"x":     h.P.X,
+				"y":     h.P.Y,
+				"score": h.S,
+			})
+		}
+	}
+	return hits, nil
+}
+
+// return args[i], or undefined if there are not enough args
+func arg(args []js.Value, i int) js.Value {
+	if i < len(args) {
+		return args[i]
+	}
+	return js.Undefined()
+}
+
+// return the search options described by the JavaScript object o
+func options(o js.Value) (opts objsearch.Options, err error) {
+	if o.IsUndefined() || o.IsNull() {
+		return
+	}
+	if v := o.Get("tolerance"); !v.IsUndefined() {
+		opts.Tolerance = v.Float()
+	}
+	if v := o.Get("mindist"); !v.IsUndefined() {
+		opts.MinDist = v.Int()
+	}
+	switch v := o.Get("color"); {
+	case v.IsUndefined() || v.String() == "gray":
+	case v.String() == "rgb":
+		opts.ColorMode = objsearch.COLORMODE_RGB
+	default:
+		return opts, errors.New("objsearch.search: unknown color mode " + v.String())
+	}
+	switch v := o.Get("combine"); {
+	case v.IsUndefined() || v.String() == "max":
+	case v.String() == "mean":
+		opts.CombineMode = objsearch.COMBINEMODE_MEAN
+	default:
+		return opts, errors.New("objsearch.search: unknown combine mode " + v.String())
+	}
+	return
+}
+
+// return a copy of the ImageData d
+func imageData(d js.Value) *image.NRGBA {
+	w, h := d.Get("width").Int(), d.Get("height").Int()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	// ImageData.data is a Uint8ClampedArray, which CopyBytesToGo does not
+	// accept
+	data := d.Get("data")
+	u8 := js.Global().Get("Uint8Array").New(data.Get("buffer"), data.Get("byteOffset"), data.Get("byteLength"))
+	js.CopyBytesToGo(img.Pix, u8)
+	return img
+}
+
+// let the JavaScript event loop run before continuing
+func yield() {
+	done := make(chan struct{})
+	var f js.Func
+	f = js.FuncOf(func(js.Value, []js.Value) interface{} {
+		f.Release()
+		close(done)
+		return nil
+	})
+	js.Global().Call("setTimeout", f, 0)
+	<-done
+}
@@ -0,0 +1,59 @@
+package objsearch
+
+import (
+	"math"
+	"sort"
+)
+
+// return the weighted mean of the absolute differences between object and
+// the window of field at (u,v), less the fraction ctx.Trim of the window's
+// total weight that differs most. Pixels are weighted by ctx.Mask and, if
+// ctx.JPEG is set, by jpegWeight. Returns NaN if no weighted pixel is
+// compared according to ctx.Border.
+func (ctx objSearchContext) windowDistance(field, object *FloatImage, u, v int) float64 {
+	type diff struct{ d, w float64 }
+	r := object.Rect
+	diffs := make([]diff, 0, r.Dx()*r.Dy())
+	total := 0.0
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if !ctx.inField(u+x, v+y) {
+				continue
+			}
+			pw := 1.0
+			if ctx.Mask != nil {
+				if pw = float64(ctx.Mask.AlphaAt(x, y).A) / 255; pw == 0 {
+					continue
+				}
+			}
+			if ctx.JPEG {
+				pw *= ctx.jpegWeight(u+x, v+y)
+			}
+			diffs = append(diffs, diff{math.Abs(field.FloatAt(u+x, v+y) - object.FloatAt(x, y)), pw})
+			total += pw
+		}
+	}
+	if ctx.Trim == 0 {
+		sum := 0.0
+		for _, d := range diffs {
+			sum += d.w * d.d
+		}
+		return sum / total
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].d < diffs[j].d
+	})
+	// sum the smallest differences up to the kept weight, counting the
+	// last of them in part
+	keep := (1 - ctx.Trim) * total
+	sum, left := 0.0, keep
+	for _, d := range diffs {
+		if d.w >= left {
+			sum += left * d.d
+			break
+		}
+		sum += d.w * d.d
+		left -= d.w
+	}
+	return sum / keep
+}